@@ -0,0 +1,164 @@
+// Package apierr คือรูปแบบ error กลางของ API ตอบเป็น RFC 7807 (application/problem+json)
+// พร้อมรหัสที่โปรแกรมอ่านได้ (code) และ error รายฟิลด์จากการตรวจ request
+package apierr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"gorm.io/gorm"
+)
+
+// ContentType คือ media type ของคำตอบที่เป็น error
+const ContentType = "application/problem+json"
+
+// Code คือรหัส error ที่ client ใช้แยกกรณี (ข้อความ detail เปลี่ยนได้ code ไม่เปลี่ยน)
+type Code string
+
+const (
+	CodeBadRequest   Code = "bad_request"
+	CodeValidation   Code = "validation_failed"
+	CodeUnauthorized Code = "unauthorized"
+	CodeForbidden    Code = "forbidden"
+	CodeNotFound     Code = "not_found"
+	CodeConflict     Code = "conflict"
+	CodeInternal     Code = "internal_error"
+)
+
+// FieldError คือปัญหาของฟิลด์เดียวใน request (field เป็นชื่อตาม json)
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error คือ error ที่รู้ว่าจะตอบ client อย่างไร cause เก็บสาเหตุจริงไว้ log เท่านั้น ไม่ส่งออกไป
+type Error struct {
+	Status int
+	Code   Code
+	Detail string
+	Fields []FieldError
+	Extra  map[string]any
+	cause  error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Detail + ": " + e.cause.Error()
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// With เพิ่มข้อมูลประกอบลงในคำตอบ (extension member ของ RFC 7807) เช่น remaining ที่นั่งที่เหลือ
+func (e *Error) With(key string, value any) *Error {
+	if e.Extra == nil {
+		e.Extra = map[string]any{}
+	}
+	e.Extra[key] = value
+	return e
+}
+
+// WithCause เก็บสาเหตุจริงไว้ log ที่ server (เช่น ข้อความจาก parser) คำตอบยังเป็น Detail เดิม
+func (e *Error) WithCause(err error) *Error {
+	e.cause = err
+	return e
+}
+
+// New สร้าง error ที่มี status และ code ตามที่กำหนด
+func New(status int, code Code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func BadRequest(detail string) *Error { return New(http.StatusBadRequest, CodeBadRequest, detail) }
+
+func Unauthorized(detail string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+func Forbidden(detail string) *Error { return New(http.StatusForbidden, CodeForbidden, detail) }
+
+func NotFound(detail string) *Error { return New(http.StatusNotFound, CodeNotFound, detail) }
+
+func Conflict(detail string) *Error { return New(http.StatusConflict, CodeConflict, detail) }
+
+// Invalid คือ request ผ่านรูปแบบแต่ค่าบางฟิลด์ใช้ไม่ได้
+func Invalid(detail string, fields ...FieldError) *Error {
+	e := New(http.StatusBadRequest, CodeValidation, detail)
+	e.Fields = fields
+	return e
+}
+
+// Required คือฟิลด์ที่ต้องส่งแต่ไม่ได้ส่ง (ตรวจเองใน handler ที่ใช้ struct เดียวกันทั้งสร้างและแก้ไข)
+func Required(field string) *Error {
+	return Invalid(field+" is required", FieldError{Field: field, Code: "required", Message: "is required"})
+}
+
+// Internal คือ error ฝั่งเซิร์ฟเวอร์ client เห็นแค่ข้อความกลางๆ ส่วน err ไป log
+func Internal(err error) *Error { return Wrap(err, "Internal server error") }
+
+// Wrap คือ Internal ที่บอก client ว่าทำอะไรไม่สำเร็จ (เช่น "Failed to create room") โดยไม่เปิดเผย err
+func Wrap(err error, detail string) *Error {
+	e := New(http.StatusInternalServerError, CodeInternal, detail)
+	e.cause = err
+	return e
+}
+
+// Problem ให้ error ของแต่ละส่วน (เช่น errQuote ของการตีราคา) บอกเองว่าจะตอบ client อย่างไร
+type Problem interface {
+	Problem() *Error
+}
+
+// From แปลง error ใดๆ เป็น *Error: ไม่พบ record = 404 ที่เหลือที่ไม่รู้จัก = 500 (ไม่ส่งข้อความของ DB ออกไป)
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var p Problem
+	if errors.As(err, &p) {
+		return p.Problem()
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound("Record not found")
+	}
+	return Internal(err)
+}
+
+// body คือรูปแบบ problem+json ที่ส่งออก error ซ้ำกับ detail ไว้ให้ frontend เดิมที่อ่าน res.error
+func (e *Error) body(c *gin.Context) gin.H {
+	h := gin.H{}
+	for k, v := range e.Extra {
+		h[k] = v
+	}
+	h["type"] = "about:blank"
+	h["title"] = http.StatusText(e.Status)
+	h["status"] = e.Status
+	h["detail"] = e.Detail
+	h["code"] = e.Code
+	h["instance"] = c.Request.URL.Path
+	h["error"] = e.Detail
+	if len(e.Fields) > 0 {
+		h["errors"] = e.Fields
+	}
+	return h
+}
+
+// Respond ตอบ err เป็น problem+json และหยุด handler ที่เหลือ
+// 5xx log สาเหตุจริงไว้ที่ server, 4xx ที่มีสาเหตุ (WithCause) log เป็น warn
+func Respond(c *gin.Context, err error) {
+	e := From(err)
+	ctx := c.Request.Context()
+	if e.Status >= 500 {
+		logging.FromContext(ctx).ErrorContext(ctx, "request failed", "method", c.Request.Method,
+			"path", c.Request.URL.Path, "status", e.Status, "error", e.Error())
+	} else if e.cause != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "request rejected", "method", c.Request.Method,
+			"path", c.Request.URL.Path, "status", e.Status, "error", e.Error())
+	}
+	c.Header("Content-Type", ContentType) // c.JSON ไม่ทับ content type ที่ตั้งไว้แล้ว
+	c.AbortWithStatusJSON(e.Status, e.body(c))
+}
@@ -0,0 +1,120 @@
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ให้ error จาก validator อ้างชื่อฟิลด์ตาม json (province_id) แทนชื่อใน struct (ProvinceID)
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
+
+// Bind แปลง error จาก c.ShouldBindJSON เป็น 400 พร้อม error รายฟิลด์
+// (ไม่ส่งข้อความภายในของ decoder/validator ออกไปตรงๆ)
+func Bind(err error) *Error {
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		fields := make([]FieldError, 0, len(ve))
+		for _, fe := range ve {
+			fields = append(fields, FieldError{Field: fieldPath(fe), Code: fe.Tag(), Message: fieldMessage(fe)})
+		}
+		return Invalid("Request validation failed", fields...)
+	}
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		field := te.Field
+		if field == "" {
+			field = "body"
+		}
+		return Invalid("Request validation failed", FieldError{
+			Field: field, Code: "type", Message: fmt.Sprintf("must be %s, got %s", jsonType(te.Type), te.Value),
+		})
+	}
+	var se *json.SyntaxError
+	if errors.As(err, &se) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return BadRequest("Malformed JSON body")
+	}
+	if errors.Is(err, io.EOF) {
+		return BadRequest("Request body is empty")
+	}
+	// เช่น time.Time ที่รูปแบบไม่ตรง ตัว decoder ไม่บอกชื่อฟิลด์
+	return BadRequest("Invalid request body")
+}
+
+// fieldPath ตัดชื่อ struct นำหน้าออก (CreateReq.stays[0].room_id → stays[0].room_id)
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min", "gte":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return "must have at least " + fe.Param() + " item(s)"
+		}
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return "must have at most " + fe.Param() + " item(s)"
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "email":
+		return "must be a valid email address"
+	case "len":
+		return "must have length " + fe.Param()
+	case "numeric":
+		return "must contain digits only"
+	}
+	return "failed the " + fe.Tag() + " check"
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Ptr:
+		return jsonType(t.Elem())
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	}
+	return "an integer"
+}
@@ -4,12 +4,12 @@ import "golang.org/x/crypto/bcrypt"
 
 // hashPassword เป็น function สำหรับการแปลง password
 func HashPassword(password string) (string, error) {
-   bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-   return string(bytes), err
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	return string(bytes), err
 }
 
 // checkPasswordHash เป็น function สำหรับ check password ที่ hash แล้ว ว่าตรงกันหรือไม่
 func CheckPasswordHash(password, hash string) bool {
-    err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-    return err == nil
-}
\ No newline at end of file
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil
+}
@@ -1,13 +1,15 @@
 package config
 
 import (
-	"fmt"
+	"log/slog"
 	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 
 	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/logging"
 )
 
 var db *gorm.DB
@@ -15,80 +17,97 @@ var db *gorm.DB
 func DB() *gorm.DB { return db }
 
 func ConnectionDB() {
-    database, err := gorm.Open(sqlite.Open("project2.db"), &gorm.Config{})
-    if err != nil {
-        panic("failed to connect database")
-    }
-    db = database
+	database, err := gorm.Open(sqlite.Open("project2.db"), &gorm.Config{
+		Logger: logging.GormLogger{
+			Level:         logging.ParseGormLevel(DBLogLevel(), gormlogger.Warn),
+			SlowThreshold: DBSlowThreshold(),
+		},
+	})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db = database
 
-    // เปิด foreign keys
-    db.Exec("PRAGMA foreign_keys = ON;")
+	// เปิด foreign keys
+	db.Exec("PRAGMA foreign_keys = ON;")
 
-    // เปิด WAL mode ช่วยเรื่อง concurrent read/write
-    db.Exec("PRAGMA journal_mode = WAL;")
-    db.Exec("PRAGMA synchronous = NORMAL;")
+	// เปิด WAL mode ช่วยเรื่อง concurrent read/write
+	db.Exec("PRAGMA journal_mode = WAL;")
+	db.Exec("PRAGMA synchronous = NORMAL;")
 
-    // ปรับ connection pool (SQLite เหมาะกับ 1 connection เท่านั้น)
-    sqlDB, _ := db.DB()
-    sqlDB.SetMaxOpenConns(1)
-    sqlDB.SetMaxIdleConns(1)
+	// ปรับ connection pool (SQLite เหมาะกับ 1 connection เท่านั้น)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
 
-    fmt.Println("✅ Connected to database (SQLite)")
+	slog.Info("connected to database", "driver", "sqlite", "file", "project2.db")
 }
 
-
 func SetupDatabase() {
-db.Exec("PRAGMA foreign_keys = ON;")
-
-    if err := db.AutoMigrate(
-        // ===== พื้นที่/พจนานุกรม (แม่ของหลายตัว) =====
-        &entity.Province{}, &entity.District{}, &entity.Subdistrict{}, &entity.Location{},
-        &entity.Language{},              // สำหรับ Guide<->Language (m2m)
-        &entity.GuideType{},             // ต้องมาก่อน ServiceArea
-        &entity.EventType{},             // ถ้ามี FK ใน Event
-        &entity.PaymentType{},           // ถ้ามี FK ใน Paymentdetail
-        &entity.ApplicationStatus{},     // ถ้าใช้ใน GuideApplication / History
-
-        // ===== โครง Service =====
-        &entity.ServiceArea{},           // อ้าง Province/District/GuideType
-
-        // ===== ผู้ใช้/แอดมิน =====
-        &entity.Admin{}, &entity.Member{},
-
-        // ===== ใบสมัครไกด์ =====
-        &entity.GuideApplication{},      // ต้องมาก่อน Guide
-        &entity.ApplicationHistory{},    // ถ้ามี FK ไปที่ GuideApplication
-
-        // ===== ไกด์ =====
-        &entity.Guide{},                 // อ้าง Member/GuideApplication + m2m: Language/ServiceArea/GuideType
-
-        // ===== ที่พักและสิ่งอำนวยความสะดวก =====
-        &entity.Accommodation{}, &entity.Room{}, &entity.Facility{},
-
-        // ===== อีเวนต์/รายการ/รูปภาพ =====
-        &entity.Event{},                 // ถ้าอ้าง Location/Province ให้มาหลัง Location
-        &entity.Item{}, &entity.ItemLocation{}, // ถ้า ItemLocation เป็นตารางจริง (ไม่ใช่ join)
-        &entity.Picture{},               // polymorphic: Owner
-
-        // ===== แพ็กเกจและความสัมพันธ์ =====
-        &entity.Package{}, &entity.PackageStay{}, &entity.EventPackage{},
-
-        // ===== การจอง/ตะกร้า/ชำระเงิน =====
-        &entity.Cart{}, &entity.CartItems{},
-        &entity.Reservation{}, &entity.ReservationHistory{}, // ถ้ามี
-        &entity.Booking{}, &entity.BookingDetail{}, &entity.BookingItem{},
-        &entity.Paymentdetail{}, // ชื่อ struct ของนายสะกดตามไฟล์ ถ้ามี
-        &entity.Receipt{},
-
-        // ===== อื่น ๆ =====
-        &entity.Review{}, &entity.ReviewBooking{}, &entity.ReviewImage{},
-        &entity.WishList{}, &entity.Notification{},
-
-        // ===== เอกสารแนบ =====
-        &entity.DocumentPath{}, // หรือ DocumentFile ตามแบบที่คุยไว้
-    ); err != nil {
-        panic(err)
-    }
+	db.Exec("PRAGMA foreign_keys = ON;")
+
+	if err := db.AutoMigrate(
+		// ===== พื้นที่/พจนานุกรม (แม่ของหลายตัว) =====
+		&entity.Province{}, &entity.District{}, &entity.Subdistrict{}, &entity.Location{},
+		&entity.GeographyVersion{}, &entity.GeographyChange{}, // ประวัติชุดข้อมูลพื้นที่ที่นำเข้า
+		&entity.Language{},          // สำหรับ Guide<->Language (m2m)
+		&entity.GuideType{},         // ต้องมาก่อน ServiceArea
+		&entity.EventType{},         // ถ้ามี FK ใน Event
+		&entity.PaymentType{},       // ถ้ามี FK ใน Paymentdetail
+		&entity.ApplicationStatus{}, // ถ้าใช้ใน GuideApplication / History
+
+		// ===== โครง Service =====
+		&entity.ServiceArea{}, // อ้าง Province/District/GuideType
+
+		// ===== ผู้ใช้/แอดมิน =====
+		&entity.Admin{}, &entity.Member{},
+
+		// ===== ใบสมัครไกด์ =====
+		&entity.GuideApplication{},   // ต้องมาก่อน Guide
+		&entity.ApplicationHistory{}, // ถ้ามี FK ไปที่ GuideApplication
+
+		// ===== ไกด์ =====
+		&entity.Guide{},             // อ้าง Member/GuideApplication + m2m: Language/ServiceArea/GuideType
+		&entity.GuideAvailability{}, // วันหยุด/วันลาของไกด์
+
+		// ===== ที่พักและสิ่งอำนวยความสะดวก =====
+		&entity.Accommodation{}, &entity.Room{}, &entity.Facility{},
+
+		// ===== อีเวนต์/รายการ/รูปภาพ =====
+		&entity.Event{},                        // ถ้าอ้าง Location/Province ให้มาหลัง Location
+		&entity.Item{}, &entity.ItemLocation{}, // ถ้า ItemLocation เป็นตารางจริง (ไม่ใช่ join)
+		&entity.Picture{}, // polymorphic: Owner
+
+		// ===== แพ็กเกจและความสัมพันธ์ =====
+		&entity.Package{}, &entity.PackageStay{}, &entity.EventPackage{},
+		&entity.ItineraryDay{}, &entity.ItineraryActivity{}, // กำหนดการรายวันของแพ็คเกจ
+
+		// ===== การจอง/ตะกร้า/ชำระเงิน =====
+		&entity.Cart{}, &entity.CartItems{},
+		&entity.Reservation{}, &entity.ReservationItem{}, &entity.ReservationHistory{}, // ถ้ามี
+		&entity.Booking{}, &entity.BookingDetail{}, &entity.BookingItem{},
+		&entity.Paymentdetail{}, // ชื่อ struct ของนายสะกดตามไฟล์ ถ้ามี
+		&entity.Receipt{},
+		&entity.PricingRule{}, // กฎราคา season/weekend/child/early bird
+		&entity.Promotion{}, &entity.PromotionScope{}, &entity.PromotionRedemption{},
+		&entity.Currency{}, &entity.ExchangeRate{}, // แสดงราคาหลายสกุลเงิน
+		&entity.SeatHold{}, &entity.WaitlistEntry{}, // ที่นั่งแพ็คเกจ/อีเวนต์ที่กันไว้ และคิวรอเมื่อเต็ม
+		&entity.SearchDocument{}, // ข้อความค้นหา (search_fts อ่านจากตารางนี้)
+		&entity.PlaceAlias{},     // ชื่อเรียกอื่นของจังหวัด/อำเภอ/ตำบลสำหรับการค้นหา
+		&entity.Job{},            // งานเบื้องหลัง (นำเข้า/รายงาน/ลบจำนวนมาก)
+
+		// ===== บัญชีรายได้/จ่ายไกด์ =====
+		&entity.CommissionRule{}, &entity.PayoutBatch{}, &entity.PayoutLine{}, &entity.LedgerEntry{},
+
+		// ===== อื่น ๆ =====
+		&entity.Review{}, &entity.ReviewBooking{}, &entity.ReviewImage{},
+		&entity.WishList{}, &entity.Notification{},
+
+		// ===== เอกสารแนบ =====
+		&entity.DocumentPath{}, // หรือ DocumentFile ตามแบบที่คุยไว้
+	); err != nil {
+		panic(err)
+	}
 
 	// seed admin แบบง่าย
 	hashedPassword, _ := HashPassword("123456")
@@ -103,4 +122,35 @@ db.Exec("PRAGMA foreign_keys = ON;")
 		Tel:       "1234567890",
 	}
 	db.FirstOrCreate(admin, entity.Admin{Email: admin.Email})
+
+	// seed สกุลเงินตามภาษาที่รองรับ (อัตราแลกเปลี่ยนให้ admin ใส่/นำเข้าเอง)
+	for _, cur := range []entity.Currency{
+		{Code: "THB", Name: "Thai Baht", Symbol: "฿", Exponent: 2},
+		{Code: "USD", Name: "US Dollar", Symbol: "$", Exponent: 2},
+		{Code: "EUR", Name: "Euro", Symbol: "€", Exponent: 2},
+		{Code: "JPY", Name: "Japanese Yen", Symbol: "¥", Exponent: 0},
+		{Code: "CNY", Name: "Chinese Yuan", Symbol: "¥", Exponent: 2},
+	} {
+		db.FirstOrCreate(&cur, entity.Currency{Code: cur.Code})
+	}
+
+	// seed ชื่อเรียกอื่นของพื้นที่ที่ใช้บ่อย (ชื่อไทย/อังกฤษในตารางภูมิศาสตร์ค้นได้อยู่แล้ว)
+	for _, a := range []entity.PlaceAlias{
+		{Alias: "กรุงเทพ", Level: entity.PlaceProvince, Code: "10"},
+		{Alias: "กทม", Level: entity.PlaceProvince, Code: "10"},
+		{Alias: "Krung Thep", Level: entity.PlaceProvince, Code: "10"},
+		{Alias: "BKK", Level: entity.PlaceProvince, Code: "10"},
+		{Alias: "อยุธยา", Level: entity.PlaceProvince, Code: "14"},
+		{Alias: "Ayutthaya", Level: entity.PlaceProvince, Code: "14"},
+		{Alias: "Ayudhya", Level: entity.PlaceProvince, Code: "14"},
+		{Alias: "โคราช", Level: entity.PlaceProvince, Code: "30"},
+		{Alias: "Korat", Level: entity.PlaceProvince, Code: "30"},
+		{Alias: "พัทยา", Level: entity.PlaceDistrict, Code: "2004"},
+		{Alias: "Pattaya", Level: entity.PlaceDistrict, Code: "2004"},
+	} {
+		db.FirstOrCreate(&a, entity.PlaceAlias{Alias: a.Alias, Level: a.Level, Code: a.Code})
+	}
+
+	// ตาราง FTS5 สำหรับ /search (ถ้า sqlite ไม่รองรับจะใช้ LIKE แทน)
+	setupSearchIndex()
 }
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// envDuration อ่านค่าระยะเวลาจาก environment (รูปแบบ time.ParseDuration เช่น "15m") ถ้าไม่มีหรือผิดรูปแบบใช้ค่าเริ่มต้น
+func envDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// SeatHoldTTL คือเวลาที่ตะกร้ากันที่นั่งแพ็คเกจ/อีเวนต์ไว้ (SEAT_HOLD_TTL, ค่าเริ่มต้น 15 นาที)
+func SeatHoldTTL() time.Duration {
+	return envDuration("SEAT_HOLD_TTL", 15*time.Minute)
+}
+
+// SeatHoldSweepInterval คือรอบของงานปล่อยที่นั่งที่หมดเวลา (SEAT_HOLD_SWEEP_INTERVAL, ค่าเริ่มต้น 1 นาที)
+func SeatHoldSweepInterval() time.Duration {
+	return envDuration("SEAT_HOLD_SWEEP_INTERVAL", time.Minute)
+}
+
+// WaitlistOfferTTL คือเวลาที่คนในคิวมีสิทธิ์รับที่นั่งที่ว่าง (WAITLIST_OFFER_TTL, ค่าเริ่มต้น 2 ชั่วโมง)
+func WaitlistOfferTTL() time.Duration {
+	return envDuration("WAITLIST_OFFER_TTL", 2*time.Hour)
+}
+
+// envInt อ่านจำนวนเต็มบวกจาก environment ถ้าไม่มีหรือผิดรูปแบบใช้ค่าเริ่มต้น
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// JobWorkers คือจำนวน worker ที่ทำงานเบื้องหลังพร้อมกัน (JOB_WORKERS, ค่าเริ่มต้น 2)
+func JobWorkers() int {
+	return envInt("JOB_WORKERS", 2)
+}
+
+// JobPollInterval คือรอบที่ worker ว่างตรวจคิว เช่น งานที่รอลองใหม่ (JOB_POLL_INTERVAL, ค่าเริ่มต้น 2 วินาที)
+func JobPollInterval() time.Duration {
+	return envDuration("JOB_POLL_INTERVAL", 2*time.Second)
+}
+
+// envBool อ่าน true/false จาก environment (รูปแบบ strconv.ParseBool) ถ้าไม่มีหรือผิดรูปแบบใช้ค่าเริ่มต้น
+func envBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// LegacyRoutesEnabled คือเปิด route เดิมที่ไม่มี /api/v1 นำหน้าไว้หรือไม่ (LEGACY_ROUTES, ค่าเริ่มต้น true)
+// ปิดได้เมื่อ frontend ทั้งสองย้ายไป /api/v1 ครบแล้ว
+func LegacyRoutesEnabled() bool {
+	return envBool("LEGACY_ROUTES", true)
+}
+
+// LegacyRoutesSunset คือวันที่จะถอด route เดิมออก ส่งให้ client ใน header Sunset
+// (LEGACY_ROUTES_SUNSET รูปแบบ YYYY-MM-DD, ค่าเริ่มต้น 2027-03-31)
+func LegacyRoutesSunset() time.Time {
+	if t, err := time.Parse("2006-01-02", os.Getenv("LEGACY_ROUTES_SUNSET")); err == nil {
+		return t
+	}
+	return time.Date(2027, time.March, 31, 0, 0, 0, 0, time.UTC)
+}
+
+// DBLogLevel คือระดับ log ของ GORM (DB_LOG_LEVEL = silent | error | warn | info, ค่าเริ่มต้น warn)
+// info = log ทุก query ใช้ตอนไล่ปัญหาเท่านั้น
+func DBLogLevel() string {
+	if v := os.Getenv("DB_LOG_LEVEL"); v != "" {
+		return v
+	}
+	return "warn"
+}
+
+// DBSlowThreshold คือเวลาที่ถือว่า query ช้าและ log เป็น warn (DB_SLOW_THRESHOLD, ค่าเริ่มต้น 200ms)
+func DBSlowThreshold() time.Duration {
+	return envDuration("DB_SLOW_THRESHOLD", 200*time.Millisecond)
+}
+
+// TraceExporter คือปลายทางของ trace (OTEL_TRACES_EXPORTER = otlp | stdout | none, ค่าเริ่มต้น none)
+// otlp ส่งไป OTEL_EXPORTER_OTLP_ENDPOINT, stdout ใช้ตอนพัฒนาที่ไม่มี collector
+func TraceExporter() string {
+	if v := os.Getenv("OTEL_TRACES_EXPORTER"); v != "" {
+		return v
+	}
+	return "none"
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"log/slog"
+	"strings"
+)
+
+var searchFTS5 bool
+
+// SearchFTS5 บอกว่า sqlite ที่ build มารองรับ FTS5 หรือไม่
+// mattn/go-sqlite3 ต้อง build ด้วย -tags sqlite_fts5 (make build / make run ใส่ให้) ถ้าไม่รองรับ /search จะค้นด้วย LIKE จาก search_documents แทน
+func SearchFTS5() bool { return searchFTS5 }
+
+// setupSearchIndex สร้างตาราง FTS5 และ trigger ใหม่ทุกครั้งที่เปิดระบบแล้ว rebuild จาก search_documents
+// (ข้อมูลจริงอยู่ใน search_documents จึงเปลี่ยน tokenizer/คอลัมน์ได้โดยไม่ต้อง migrate)
+func setupSearchIndex() {
+	searchFTS5 = false
+	for _, stmt := range []string{
+		`DROP TRIGGER IF EXISTS search_documents_ai`,
+		`DROP TRIGGER IF EXISTS search_documents_ad`,
+		`DROP TRIGGER IF EXISTS search_documents_au`,
+		`DROP TABLE IF EXISTS search_fts`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil && !strings.Contains(err.Error(), "no such module") {
+			slog.Error("search index", "error", err)
+		}
+	}
+
+	// index คอลัมน์ที่ตัดคำไทยแล้ว (คำคั่นด้วยช่องว่าง) เพราะ unicode61 แยกคำไทยที่เขียนติดกันไม่ได้
+	// categories: นับสระบน/ล่างและวรรณยุกต์ไทย (Mn/Mc) เป็นส่วนของคำ ไม่งั้น unicode61 จะตัดคำไทยกลางคำ
+	err := db.Exec(`CREATE VIRTUAL TABLE search_fts USING fts5(
+		title_words, keywords,
+		content='search_documents', content_rowid='id',
+		tokenize="unicode61 remove_diacritics 2 categories 'L* N* Co Mc Mn'"
+	)`).Error
+	if err != nil {
+		slog.Warn("search index: FTS5 unavailable, falling back to LIKE search", "error", err)
+		return
+	}
+	for _, stmt := range []string{
+		`CREATE TRIGGER search_documents_ai AFTER INSERT ON search_documents BEGIN
+			INSERT INTO search_fts(rowid, title_words, keywords) VALUES (new.id, new.title_words, new.keywords);
+		END`,
+		`CREATE TRIGGER search_documents_ad AFTER DELETE ON search_documents BEGIN
+			INSERT INTO search_fts(search_fts, rowid, title_words, keywords) VALUES ('delete', old.id, old.title_words, old.keywords);
+		END`,
+		`CREATE TRIGGER search_documents_au AFTER UPDATE ON search_documents BEGIN
+			INSERT INTO search_fts(search_fts, rowid, title_words, keywords) VALUES ('delete', old.id, old.title_words, old.keywords);
+			INSERT INTO search_fts(rowid, title_words, keywords) VALUES (new.id, new.title_words, new.keywords);
+		END`,
+		`INSERT INTO search_fts(search_fts) VALUES ('rebuild')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			slog.Error("search index", "error", err)
+			return
+		}
+	}
+	searchFTS5 = true
+}
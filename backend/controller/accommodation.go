@@ -5,19 +5,22 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
 )
 
 // ==================== สร้างโครง DTO ====================
 type AccommodationCreateReq struct {
-	Name          string   `json:"name"`
+	Name          string   `json:"name" binding:"required"`
 	Type          string   `json:"type"`
 	Status        string   `json:"status"`
 	ProvinceID    uint     `json:"province_id"`
 	DistrictID    uint     `json:"district_id"`
 	SubdistrictID uint     `json:"subdistrict_id"`
 	AdminID       uint     `json:"admin_id"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
 	PictureURLs   []string `json:"picture_urls"`
 }
 
@@ -29,25 +32,34 @@ type AccommodationUpdateReq struct {
 	DistrictID    *uint     `json:"district_id"`
 	SubdistrictID *uint     `json:"subdistrict_id"`
 	AdminID       *uint     `json:"admin_id"`
+	Latitude      *float64  `json:"latitude"` // ส่งคู่กัน (ลบพิกัดใช้ PUT /accommodation/:id/coordinates)
+	Longitude     *float64  `json:"longitude"`
 	PictureURLs   *[]string `json:"picture_urls"`
 }
 
-// ==================== ดึงที่พักทั้งหมด ====================
+var accommodationList = listSpec{
+	table: "accommodations",
+	sorts: map[string]string{"name": "name", "type": "type", "status": "status", "created_at": "created_at"},
+	filters: map[string]string{"type": "type", "status": "status", "province_id": "province_id",
+		"district_id": "district_id", "subdistrict_id": "subdistrict_id", "admin_id": "admin_id"},
+}
+
+// ==================== ดึงที่พักทั้งหมด (แบ่งหน้า) ====================
 func FindAccommodation(c *gin.Context) {
 	var acc []entity.Accommodation
-	if err := config.DB().Preload("Pictures").Find(&acc).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+	page, ok := accommodationList.find(c, dbFor(c), &acc, func(db *gorm.DB) *gorm.DB { return db.Preload("Pictures") })
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": acc})
+	c.JSON(http.StatusOK, gin.H{"data": acc, "pagination": page})
 }
 
 // ==================== ดึงที่พักตาม id ====================
 func FindAccommodationId(c *gin.Context) {
 	id := c.Param("id")
 	var acc entity.Accommodation
-	if err := config.DB().Preload("Pictures").First(&acc, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "accommodation not found"})
+	if err := dbFor(c).Preload("Pictures").First(&acc, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("accommodation not found"))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": acc})
@@ -57,7 +69,17 @@ func FindAccommodationId(c *gin.Context) {
 func CreateAccommodation(c *gin.Context) {
 	var req AccommodationCreateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	if err := checkCoordinates(req.Latitude, req.Longitude); err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+
+	geo, err := checkGeoHierarchy(dbFor(c), geoRef{&req.ProvinceID, &req.DistrictID, &req.SubdistrictID}, geoRef{})
+	if err != nil {
+		apierr.Respond(c, err)
 		return
 	}
 
@@ -65,14 +87,16 @@ func CreateAccommodation(c *gin.Context) {
 		Name:          strings.TrimSpace(req.Name),
 		Type:          req.Type,
 		Status:        req.Status,
-		ProvinceID:    &req.ProvinceID,
-		DistrictID:    &req.DistrictID,
-		SubdistrictID: &req.SubdistrictID,
-		AdminID:       &req.AdminID,
+		ProvinceID:    geo.ProvinceID,
+		DistrictID:    geo.DistrictID,
+		SubdistrictID: geo.SubdistrictID,
+		AdminID:       optionalID(&req.AdminID),
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
 	}
 
-	if err := config.DB().Create(&acc).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot create accommodation"})
+	if err := dbFor(c).Create(&acc).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "cannot create accommodation"))
 		return
 	}
 
@@ -83,8 +107,9 @@ func CreateAccommodation(c *gin.Context) {
 			OwnerType: "accommodation",
 			OwnerID:   acc.ID,
 		}
-		config.DB().Create(&pic)
+		dbFor(c).Create(&pic)
 	}
+	refreshSearch(c, searchAccommodation, acc.ID)
 
 	c.JSON(http.StatusCreated, gin.H{"data": acc, "message": "created"})
 }
@@ -93,14 +118,14 @@ func CreateAccommodation(c *gin.Context) {
 func UpdateAccommodationById(c *gin.Context) {
 	id := c.Param("id")
 	var acc entity.Accommodation
-	if err := config.DB().First(&acc, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	if err := dbFor(c).First(&acc, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("not found"))
 		return
 	}
 
 	var req AccommodationUpdateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
@@ -114,36 +139,53 @@ func UpdateAccommodationById(c *gin.Context) {
 	if req.Status != nil {
 		acc.Status = *req.Status
 	}
+	prev := geoRef{acc.ProvinceID, acc.DistrictID, acc.SubdistrictID}
+	next := prev
 	if req.ProvinceID != nil {
-		acc.ProvinceID = req.ProvinceID
+		next.ProvinceID = req.ProvinceID
 	}
 	if req.DistrictID != nil {
-		acc.DistrictID = req.DistrictID
+		next.DistrictID = req.DistrictID
 	}
 	if req.SubdistrictID != nil {
-		acc.SubdistrictID = req.SubdistrictID
+		next.SubdistrictID = req.SubdistrictID
+	}
+	geo, err := checkGeoHierarchy(dbFor(c), next, prev)
+	if err != nil {
+		apierr.Respond(c, err)
+		return
 	}
+	acc.ProvinceID, acc.DistrictID, acc.SubdistrictID = geo.ProvinceID, geo.DistrictID, geo.SubdistrictID
 	if req.AdminID != nil {
 		acc.AdminID = req.AdminID
 	}
+	if req.Latitude != nil || req.Longitude != nil {
+		if err := checkCoordinates(req.Latitude, req.Longitude); err != nil {
+			apierr.Respond(c, err)
+			return
+		}
+		acc.Latitude, acc.Longitude = req.Latitude, req.Longitude
+	}
 
-	if err := config.DB().Save(&acc).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+	if err := dbFor(c).Save(&acc).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "update failed"))
 		return
 	}
 
 	// ถ้ามี picture_urls → ลบของเก่าแล้วเพิ่มใหม่
 	if req.PictureURLs != nil {
-		config.DB().Where("owner_type = ? AND owner_id = ?", "accommodation", acc.ID).Delete(&entity.Picture{})
+		dbFor(c).Where("owner_type = ? AND owner_id = ?", "accommodation", acc.ID).Delete(&entity.Picture{})
 		for _, url := range *req.PictureURLs {
 			pic := entity.Picture{
 				Url:       url,
 				OwnerType: "accommodation",
 				OwnerID:   acc.ID,
 			}
-			config.DB().Create(&pic)
+			dbFor(c).Create(&pic)
 		}
 	}
+	refreshSearch(c, searchAccommodation, acc.ID)
+	refreshAccommodationPackages(c, acc.ID)
 
 	c.JSON(http.StatusOK, gin.H{"data": acc, "message": "updated"})
 }
@@ -152,18 +194,20 @@ func UpdateAccommodationById(c *gin.Context) {
 func DeleteAccommodationById(c *gin.Context) {
 	id := c.Param("id")
 	var acc entity.Accommodation
-	if err := config.DB().First(&acc, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	if err := dbFor(c).First(&acc, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("not found"))
 		return
 	}
 
 	// ลบรูปที่เกี่ยวข้อง
-	config.DB().Where("owner_type = ? AND owner_id = ?", "accommodation", acc.ID).Delete(&entity.Picture{})
+	dbFor(c).Where("owner_type = ? AND owner_id = ?", "accommodation", acc.ID).Delete(&entity.Picture{})
 
-	if err := config.DB().Delete(&acc).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+	if err := dbFor(c).Delete(&acc).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "delete failed"))
 		return
 	}
+	refreshSearch(c, searchAccommodation, acc.ID)
+	refreshAccommodationPackages(c, acc.ID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
@@ -0,0 +1,338 @@
+package controller
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/thai"
+	"gorm.io/gorm"
+)
+
+// geoNode คือจังหวัด/อำเภอ/ตำบล 1 รายการในผลค้นที่อยู่ (ID ใช้เติมฟอร์มได้ทันที)
+type geoNode struct {
+	ID     uint   `json:"id"`
+	Code   string `json:"code"`
+	NameTh string `json:"name_th"`
+	NameEn string `json:"name_en"`
+}
+
+// addressTriple คือตำบลพร้อมอำเภอและจังหวัดที่อยู่
+type addressTriple struct {
+	Province    *geoNode `json:"province"`
+	District    *geoNode `json:"district"`
+	Subdistrict *geoNode `json:"subdistrict"`
+	PostalCode  string   `json:"postal_code"`
+}
+
+// addressRow คือแถวจาก addressTriples ก่อนแปลงเป็น addressTriple
+type addressRow struct {
+	SubID, DistID, ProvID        uint
+	SubCode, DistCode, ProvCode  string
+	SubTh, SubEn, DistTh, DistEn string
+	ProvTh, ProvEn, Zip          string
+}
+
+func (r addressRow) triple() addressTriple {
+	return addressTriple{
+		Province:    &geoNode{r.ProvID, r.ProvCode, r.ProvTh, r.ProvEn},
+		District:    &geoNode{r.DistID, r.DistCode, r.DistTh, r.DistEn},
+		Subdistrict: &geoNode{r.SubID, r.SubCode, r.SubTh, r.SubEn},
+		PostalCode:  r.Zip,
+	}
+}
+
+// addressTriples ดึงตำบลที่ยังใช้อยู่พร้อมอำเภอ/จังหวัด (where เพิ่มเงื่อนไขได้ เช่น รหัสไปรษณีย์)
+func addressTriples(tx *gorm.DB, where string, args ...any) ([]addressRow, error) {
+	q := tx.Table("subdistricts s").
+		Select(`s.id AS sub_id, s.subdistrict_code AS sub_code, s.name_th AS sub_th, s.name_en AS sub_en,
+			COALESCE(s.zip_code, '') AS zip,
+			d.id AS dist_id, d.district_code AS dist_code, d.name_th AS dist_th, d.name_en AS dist_en,
+			p.id AS prov_id, p.province_code AS prov_code, p.name_th AS prov_th, p.name_en AS prov_en`).
+		Joins("JOIN districts d ON d.id = s.district_id").
+		Joins("JOIN provinces p ON p.id = d.province_id").
+		Where("s.deleted_at IS NULL AND s.retired_at IS NULL AND d.deleted_at IS NULL AND d.retired_at IS NULL AND p.deleted_at IS NULL AND p.retired_at IS NULL")
+	if where != "" {
+		q = q.Where(where, args...)
+	}
+	var rows []addressRow
+	err := q.Order("s.subdistrict_code").Scan(&rows).Error
+	return rows, err
+}
+
+var postalCodePattern = regexp.MustCompile(`^[1-9][0-9]{4}$`)
+
+// GET /location/lookup?postal_code=50200 - ตำบลทั้งหมดที่ใช้รหัสไปรษณีย์นี้ พร้อมอำเภอและจังหวัด
+func LookupPostalCode(c *gin.Context) {
+	code := strings.TrimSpace(c.Query("postal_code"))
+	if !postalCodePattern.MatchString(code) {
+		apierr.Respond(c, apierr.BadRequest("postal_code must be 5 digits"))
+		return
+	}
+	rows, err := addressTriples(dbFor(c), "s.zip_code = ?", code)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if len(rows) == 0 {
+		apierr.Respond(c, apierr.NotFound("No subdistrict with postal code "+code))
+		return
+	}
+	triples := make([]addressTriple, 0, len(rows))
+	for _, r := range rows {
+		triples = append(triples, r.triple())
+	}
+	c.JSON(http.StatusOK, gin.H{"data": triples, "total": len(triples)})
+}
+
+// คำนำหน้าระดับพื้นที่ในที่อยู่ (ไทย/อังกฤษ) ตัดทิ้งก่อนเทียบชื่อ แต่ใช้บอกว่าชื่อที่ตามมาเป็นระดับไหน
+var addressMarkers = []struct {
+	level  string
+	prefix []string
+}{
+	{entity.PlaceSubdistrict, []string{"ตำบล", "แขวง", "ต.", "tambon", "khwaeng", "subdistrict"}},
+	{entity.PlaceDistrict, []string{"อำเภอ", "เขต", "อ.", "amphoe", "amphur", "khet", "district"}},
+	{entity.PlaceProvince, []string{"จังหวัด", "จ.", "changwat", "province"}},
+}
+
+var addressPostalCode = regexp.MustCompile(`(^|[^0-9])([1-9][0-9]{4})([^0-9]|$)`)
+
+// parsedAddress คือข้อความที่อยู่หลังตัดคำนำหน้า: key ของทั้งข้อความ และ key ของส่วนที่ตามหลังคำนำหน้าแต่ละระดับ
+type parsedAddress struct {
+	key        string
+	marked     map[string]string
+	postalCode string
+}
+
+func parseAddressText(text string) parsedAddress {
+	p := parsedAddress{marked: map[string]string{}}
+	if m := addressPostalCode.FindAllStringSubmatch(text, -1); len(m) > 0 {
+		p.postalCode = m[len(m)-1][2] // รหัสไปรษณีย์มักอยู่ท้าย (บ้านเลขที่อาจเป็นตัวเลข 5 หลักได้)
+	}
+
+	// แยกตามช่องว่าง/จุลภาค แล้วดูว่าส่วนไหนขึ้นต้นด้วยคำนำหน้า
+	parts := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t' || r == '\n'
+	})
+	var rest []string
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+	markers:
+		for _, m := range addressMarkers {
+			for _, prefix := range m.prefix {
+				if !strings.HasPrefix(part, prefix) {
+					continue
+				}
+				name := strings.TrimPrefix(part, prefix)
+				if name == "" && i+1 < len(parts) {
+					i++ // "ตำบล สุเทพ" หรือ "Tambon Su Thep"
+					name = parts[i]
+				}
+				if _, ok := p.marked[m.level]; !ok {
+					p.marked[m.level] = thai.Key(name)
+				}
+				part = name
+				break markers
+			}
+		}
+		rest = append(rest, part)
+	}
+	p.key = thai.Key(strings.Join(rest, " "))
+	return p
+}
+
+// addressCandidate คือตำบลที่ตรงกับที่อยู่ พร้อมคะแนน
+type addressCandidate struct {
+	addressTriple
+	Score   int      `json:"score"`
+	Matched []string `json:"matched"`
+
+	length int // ความยาวชื่อที่ตรงรวมกัน (ใช้ตัดสินเมื่อคะแนนเท่ากัน)
+}
+
+// matchName บอกว่าชื่อ (หรือชื่อเรียกอื่น) ของพื้นที่อยู่ในที่อยู่ คืนความยาวของชื่อที่ตรง
+// ถ้าตรงกับส่วนที่มีคำนำหน้าระดับเดียวกันพอดีจะได้ exact = true
+func (p parsedAddress) matchName(level string, names ...string) (length int, exact bool) {
+	for _, name := range names {
+		key := thai.Key(name)
+		if utf8.RuneCountInString(key) < 2 {
+			continue
+		}
+		if p.marked[level] == key {
+			return utf8.RuneCountInString(key), true
+		}
+		if n := utf8.RuneCountInString(key); n > length && strings.Contains(p.key, key) {
+			length = n
+		}
+	}
+	return length, false
+}
+
+// resolveAddress ให้คะแนนทุกตำบลกับข้อความที่อยู่ แล้วคืนผู้สมัครเรียงจากคะแนนมากไปน้อย
+// จังหวัด 4, อำเภอ 3, ตำบล 2, รหัสไปรษณีย์ 3 และ +1 เมื่อชื่อตรงกับส่วนที่มีคำนำหน้าระดับนั้น
+func resolveAddress(tx *gorm.DB, text string) (parsedAddress, []addressCandidate, error) {
+	p := parseAddressText(text)
+	rows, err := addressTriples(tx, "")
+	if err != nil {
+		return p, nil, err
+	}
+	// ชื่อเรียกอื่นของจังหวัด/อำเภอ (กทม, โคราช, พัทยา) ตามที่ใช้ในการค้นหา
+	var aliases []entity.PlaceAlias
+	if err := tx.Where("level IN ?", []string{entity.PlaceProvince, entity.PlaceDistrict}).Find(&aliases).Error; err != nil {
+		return p, nil, err
+	}
+	aliasOf := map[string][]string{}
+	for _, a := range aliases {
+		aliasOf[a.Level+":"+a.Code] = append(aliasOf[a.Level+":"+a.Code], a.Alias)
+	}
+
+	// ชื่อจังหวัด/อำเภอซ้ำกันหลายตำบล คำนวณครั้งเดียวต่อรหัส
+	type hit struct {
+		length int
+		exact  bool
+	}
+	provHits, distHits := map[string]hit{}, map[string]hit{}
+	var out []addressCandidate
+	for _, r := range rows {
+		ph, ok := provHits[r.ProvCode]
+		if !ok {
+			ph.length, ph.exact = p.matchName(entity.PlaceProvince, append([]string{r.ProvTh, r.ProvEn}, aliasOf[entity.PlaceProvince+":"+r.ProvCode]...)...)
+			provHits[r.ProvCode] = ph
+		}
+		dh, ok := distHits[r.DistCode]
+		if !ok {
+			names := append([]string{r.DistTh, r.DistEn}, aliasOf[entity.PlaceDistrict+":"+r.DistCode]...)
+			// "อ.เมือง จ.เชียงใหม่" เขียนย่อชื่ออำเภอเมืองบ่อย
+			if strings.HasPrefix(r.DistTh, "เมือง") && p.marked[entity.PlaceDistrict] == "เมือง" {
+				names = append(names, "เมือง")
+			}
+			dh.length, dh.exact = p.matchName(entity.PlaceDistrict, names...)
+			distHits[r.DistCode] = dh
+		}
+		sl, sExact := p.matchName(entity.PlaceSubdistrict, r.SubTh, r.SubEn)
+		// ตำบลชื่อเดียวกับอำเภอ (เช่น ปทุมวัน) ต้องเขียนชื่อนั้นสองครั้งหรือมีคำนำหน้าตำบล ไม่งั้นถือว่าเป็นชื่ออำเภอ
+		if sl > 0 && !sExact && thai.Key(r.SubTh) == thai.Key(r.DistTh) &&
+			strings.Count(p.key, thai.Key(r.SubTh)) < 2 && strings.Count(p.key, thai.Key(r.SubEn)) < 2 {
+			sl = 0
+		}
+
+		cand := addressCandidate{addressTriple: r.triple()}
+		add := func(field string, score, length int, exact bool) {
+			if exact {
+				score++
+			}
+			cand.Score += score
+			cand.length += length
+			cand.Matched = append(cand.Matched, field)
+		}
+		if ph.length > 0 {
+			add("province", 4, ph.length, ph.exact)
+		}
+		if dh.length > 0 {
+			add("district", 3, dh.length, dh.exact)
+		}
+		if sl > 0 {
+			add("subdistrict", 2, sl, sExact)
+		}
+		if p.postalCode != "" && p.postalCode == r.Zip {
+			add("postal_code", 3, 0, false)
+		}
+		if len(cand.Matched) >= 2 {
+			out = append(out, cand)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].length > out[j].length
+	})
+	return p, out, nil
+}
+
+// AddressParseRequest คือข้อความที่อยู่ที่ผู้ใช้พิมพ์/วาง
+type AddressParseRequest struct {
+	Address string `json:"address" binding:"required"`
+}
+
+// POST /location/address/parse - แปลงที่อยู่แบบข้อความ (ไทย/อังกฤษ) เป็นจังหวัด/อำเภอ/ตำบล สำหรับเติมฟอร์มอัตโนมัติ
+// เช่น "99/1 ต.สุเทพ อ.เมือง จ.เชียงใหม่ 50200" ส่วนที่ไม่แน่ใจ (ไม่พบในข้อความ) คืนเป็น null
+func ParseAddress(c *gin.Context) {
+	var req AddressParseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	parsed, cands, err := resolveAddress(dbFor(c), req.Address)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if len(cands) == 0 {
+		apierr.Respond(c, apierr.NotFound("Could not resolve a province, district or subdistrict from the address"))
+		return
+	}
+
+	best := cands[0]
+	matched := map[string]bool{}
+	for _, f := range best.Matched {
+		matched[f] = true
+	}
+	result := best.addressTriple
+	result.PostalCode = parsed.postalCode
+	// ตำบลที่ไม่ได้ระบุและรหัสไปรษณีย์ไม่ได้ชี้ตำบลเดียว = ไม่แน่ใจ
+	if !matched["subdistrict"] && !(matched["postal_code"] && postalUnique(cands, best)) {
+		result.Subdistrict = nil
+	}
+	if !matched["district"] && result.Subdistrict == nil {
+		result.District = nil
+	}
+
+	// ความมั่นใจ: high = ไม่มีผู้สมัครอื่นที่คะแนนเท่ากันแต่ได้ผลต่างกัน และตรงอย่างน้อย 3 อย่าง
+	// medium = ไม่กำกวมแต่ข้อมูลน้อย, low = มีหลายแบบที่เป็นไปได้ ควรให้ผู้ใช้เลือกจาก candidates
+	ambiguous := false
+	for _, c := range cands[1:] {
+		if c.Score != best.Score || c.length != best.length {
+			break
+		}
+		if (result.District != nil && c.District.ID != result.District.ID) ||
+			(result.Subdistrict != nil && c.Subdistrict.ID != result.Subdistrict.ID) ||
+			c.Province.ID != result.Province.ID {
+			ambiguous = true
+			break
+		}
+	}
+	confidence := "low"
+	switch {
+	case !ambiguous && len(best.Matched) >= 3:
+		confidence = "high"
+	case !ambiguous:
+		confidence = "medium"
+	}
+
+	if len(cands) > 5 {
+		cands = cands[:5]
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       result,
+		"matched":    best.Matched,
+		"confidence": confidence,
+		"candidates": cands,
+	})
+}
+
+// postalUnique บอกว่าในผู้สมัครที่คะแนนเท่าอันดับหนึ่ง มีตำบลเดียวที่รหัสไปรษณีย์ตรงในอำเภอเดียวกัน
+func postalUnique(cands []addressCandidate, best addressCandidate) bool {
+	n := 0
+	for _, c := range cands {
+		if c.Score == best.Score && c.District.ID == best.District.ID && c.PostalCode == best.PostalCode {
+			n++
+		}
+	}
+	return n == 1
+}
@@ -5,46 +5,54 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"github.com/kookkikiv/sa_project/backend/config"
 	"github.com/kookkikiv/sa_project/backend/entity"
-	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// GET /admin
+var adminList = listSpec{
+	table: "admins",
+	sorts: map[string]string{"user_name": "user_name", "first_name": "first_name", "last_name": "last_name",
+		"email": "email", "created_at": "created_at"},
+	filters: map[string]string{"user_name": "user_name", "email": "email"},
+}
+
+// GET /admin?page=&page_size=&sort=
 func FindAdmin(c *gin.Context) {
 	var admin []entity.Admin
-	if err := config.DB().Find(&admin).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, ok := adminList.find(c, dbFor(c), &admin)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": admin})
+	c.JSON(http.StatusOK, gin.H{"data": admin, "pagination": page})
 }
 
 // GET /admin/:id
 func FindAdminById(c *gin.Context) {
 	var admin entity.Admin
 	id := c.Param("id")
-	
+
 	// Validate ID format
 	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID format"})
+		apierr.Respond(c, apierr.BadRequest("Invalid admin ID format"))
 		return
 	}
-	
-	if err := config.DB().Where("id = ?", id).First(&admin).Error; err != nil {
+
+	if err := dbFor(c).Where("id = ?", id).First(&admin).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+			apierr.Respond(c, apierr.NotFound("Admin not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 		}
 		return
 	}
 
 	// Don't return password in response
 	admin.Password = ""
-	
+
 	c.JSON(http.StatusOK, gin.H{"data": admin})
 }
 
@@ -53,28 +61,28 @@ func CreateAdmin(c *gin.Context) {
 	var admin entity.Admin
 
 	if err := c.ShouldBindJSON(&admin); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
 	// Validate required fields
 	if strings.TrimSpace(admin.Email) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		apierr.Respond(c, apierr.Required("admin_email"))
 		return
 	}
-	
+
 	if strings.TrimSpace(admin.Password) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		apierr.Respond(c, apierr.Required("password"))
 		return
 	}
-	
+
 	if strings.TrimSpace(admin.FirstName) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Firstname is required"})
+		apierr.Respond(c, apierr.Required("admin_first_name"))
 		return
 	}
-	
+
 	if strings.TrimSpace(admin.LastName) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Lastname is required"})
+		apierr.Respond(c, apierr.Required("admin_last_name"))
 		return
 	}
 
@@ -86,30 +94,30 @@ func CreateAdmin(c *gin.Context) {
 	// Hash password ก่อนบันทึก
 	hashedPassword, err := config.HashPassword(admin.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to hash password"))
 		return
 	}
 	admin.Password = hashedPassword
 
 	// ตรวจสอบ email ซ้ำ
 	var existingAdmin entity.Admin
-	if err := config.DB().Where("email = ?", admin.Email).First(&existingAdmin).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+	if err := dbFor(c).Where("email = ?", admin.Email).First(&existingAdmin).Error; err == nil {
+		apierr.Respond(c, apierr.Conflict("Email already exists"))
 		return
 	}
 
-	if err := config.DB().Create(&admin).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin: " + err.Error()})
+	if err := dbFor(c).Create(&admin).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create admin"))
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"data": gin.H{
-			"ID": admin.ID,
+			"ID":        admin.ID,
 			"Firstname": admin.FirstName,
-			"Lastname": admin.LastName,
-			"Email": admin.Email,
-			"Birthday": admin.BirthDay,
+			"Lastname":  admin.LastName,
+			"Email":     admin.Email,
+			"Birthday":  admin.BirthDay,
 		},
 		"message": "Admin created successfully",
 	})
@@ -119,26 +127,26 @@ func CreateAdmin(c *gin.Context) {
 func UpdateAdminById(c *gin.Context) {
 	var updateData entity.Admin
 	id := c.Param("id")
-	
+
 	// Validate ID format
 	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID format"})
+		apierr.Respond(c, apierr.BadRequest("Invalid admin ID format"))
 		return
 	}
-	
+
 	// Bind JSON data
 	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
 	// ตรวจสอบว่า admin มีอยู่จริง
 	var existingAdmin entity.Admin
-	if err := config.DB().Where("id = ?", id).First(&existingAdmin).Error; err != nil {
+	if err := dbFor(c).Where("id = ?", id).First(&existingAdmin).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+			apierr.Respond(c, apierr.NotFound("Admin not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 		}
 		return
 	}
@@ -147,30 +155,30 @@ func UpdateAdminById(c *gin.Context) {
 	if updateData.FirstName != "" {
 		updateData.FirstName = strings.TrimSpace(updateData.FirstName)
 		if updateData.FirstName == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Firstname cannot be empty"})
+			apierr.Respond(c, apierr.BadRequest("Firstname cannot be empty"))
 			return
 		}
 	}
-	
+
 	if updateData.LastName != "" {
 		updateData.LastName = strings.TrimSpace(updateData.LastName)
 		if updateData.LastName == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Lastname cannot be empty"})
+			apierr.Respond(c, apierr.BadRequest("Lastname cannot be empty"))
 			return
 		}
 	}
-	
+
 	if updateData.Email != "" {
 		updateData.Email = strings.TrimSpace(updateData.Email)
 		if updateData.Email == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Email cannot be empty"})
+			apierr.Respond(c, apierr.BadRequest("Email cannot be empty"))
 			return
 		}
-		
+
 		// ตรวจสอบ email ซ้ำ (ยกเว้นตัวเอง)
 		var existingEmailAdmin entity.Admin
-		if err := config.DB().Where("email = ? AND id != ?", updateData.Email, id).First(&existingEmailAdmin).Error; err == nil {
-			c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+		if err := dbFor(c).Where("email = ? AND id != ?", updateData.Email, id).First(&existingEmailAdmin).Error; err == nil {
+			apierr.Respond(c, apierr.Conflict("Email already exists"))
 			return
 		}
 	}
@@ -179,28 +187,28 @@ func UpdateAdminById(c *gin.Context) {
 	if updateData.Password != "" {
 		updateData.Password = strings.TrimSpace(updateData.Password)
 		if len(updateData.Password) < 6 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 6 characters"})
+			apierr.Respond(c, apierr.BadRequest("Password must be at least 6 characters"))
 			return
 		}
-		
+
 		hashedPassword, err := config.HashPassword(updateData.Password)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			apierr.Respond(c, apierr.Wrap(err, "Failed to hash password"))
 			return
 		}
 		updateData.Password = hashedPassword
 	}
 
 	// อัปเดตข้อมูล (GORM จะไม่อัปเดต zero values, ใช้ Select สำหรับ fields ที่ต้องการ)
-	result := config.DB().Model(&existingAdmin).Updates(&updateData)
+	result := dbFor(c).Model(&existingAdmin).Updates(&updateData)
 	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin: " + result.Error.Error()})
+		apierr.Respond(c, apierr.Wrap(result.Error, "Failed to update admin"))
 		return
 	}
 
 	// โหลดข้อมูลใหม่เพื่อส่งกลับ
-	if err := config.DB().Where("id = ?", id).First(&existingAdmin).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload admin data"})
+	if err := dbFor(c).Where("id = ?", id).First(&existingAdmin).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to reload admin data"))
 		return
 	}
 
@@ -208,7 +216,7 @@ func UpdateAdminById(c *gin.Context) {
 	existingAdmin.Password = ""
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": existingAdmin, 
+		"data":    existingAdmin,
 		"message": "Admin updated successfully",
 	})
 }
@@ -217,28 +225,28 @@ func UpdateAdminById(c *gin.Context) {
 func DeleteAdminById(c *gin.Context) {
 	var admin entity.Admin
 	id := c.Param("id")
-	
+
 	// Validate ID format
 	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID format"})
+		apierr.Respond(c, apierr.BadRequest("Invalid admin ID format"))
 		return
 	}
-	
+
 	// ตรวจสอบว่า record มีอยู่จริงก่อนลบ
-	if err := config.DB().Where("id = ?", id).First(&admin).Error; err != nil {
+	if err := dbFor(c).Where("id = ?", id).First(&admin).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+			apierr.Respond(c, apierr.NotFound("Admin not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 		}
 		return
 	}
-	
+
 	// ลบ record
-	if err := config.DB().Delete(&admin, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete admin"})
+	if err := dbFor(c).Delete(&admin, id).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to delete admin"))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Admin deleted successfully"})
-}
\ No newline at end of file
+}
@@ -3,13 +3,14 @@
 package controller
 
 import (
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"net/http"
 	"strconv"
 
+	"github.com/gin-gonic/gin"
 	"github.com/kookkikiv/sa_project/backend/config"
 	"github.com/kookkikiv/sa_project/backend/entity"
 	"github.com/kookkikiv/sa_project/backend/services"
-	"github.com/gin-gonic/gin"
 )
 
 type SignInRequest struct {
@@ -27,22 +28,22 @@ type SignInResponse struct {
 // POST /signin
 func SignIn(c *gin.Context) {
 	var request SignInRequest
-	
+
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
 	// ค้นหา admin ด้วย email
 	var admin entity.Admin
-	if err := config.DB().Where("email= ?", request.Email).First(&admin).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	if err := dbFor(c).Where("email= ?", request.Email).First(&admin).Error; err != nil {
+		apierr.Respond(c, apierr.Unauthorized("Invalid email or password"))
 		return
 	}
 
 	// ตรวจสอบรหัสผ่าน
 	if !config.CheckPasswordHash(request.Password, admin.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		apierr.Respond(c, apierr.Unauthorized("Invalid email or password"))
 		return
 	}
 
@@ -55,7 +56,7 @@ func SignIn(c *gin.Context) {
 
 	token, err := jwtWrapper.GenerateToken(admin.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to generate token"))
 		return
 	}
 
@@ -67,4 +68,4 @@ func SignIn(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/metrics"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+type BookingRequest struct {
+	MemberID       *uint  `json:"member_id"`
+	RoomID         *uint  `json:"room_id"`
+	CheckIn        string `json:"check_in"`  // "YYYY-MM-DD"
+	CheckOut       string `json:"check_out"` // "YYYY-MM-DD"
+	Adults         uint   `json:"adults"`
+	Children       uint   `json:"children"`
+	Rooms          uint   `json:"rooms"`
+	SpecialRequest string `json:"special_request"`
+}
+
+// createRoomBooking บันทึก Booking + BookingDetail จากราคาที่ตีแล้ว total คือยอดหลังหักส่วนลดโค้ด (ถ้ามี)
+// ห้อง 1 แถวจองได้ทีละการจอง: ถ้ามีการจองที่ยังไม่ยกเลิกทับช่วงวันอยู่แล้วตอบ 409
+// error ที่คืนเป็น *apierr.Error แล้ว ส่งต่อให้ apierr.Respond ได้เลย
+func createRoomBooking(tx *gorm.DB, memberID uint, req QuoteRequest, item quotedItem, total money.Money, special string) (entity.Booking, error) {
+	var overlapping int64
+	if err := tx.Model(&entity.BookingDetail{}).
+		Joins("JOIN bookings ON bookings.id = booking_details.booking_id AND bookings.deleted_at IS NULL").
+		Where("booking_details.room_id = ? AND bookings.status_booking <> ?", req.RoomID, "cancelled").
+		Where("bookings.checkin_date < ? AND bookings.checkout_date > ?", item.End, item.Start).
+		Count(&overlapping).Error; err != nil {
+		return entity.Booking{}, apierr.Wrap(err, "Failed to check room availability")
+	}
+	if overlapping > 0 {
+		return entity.Booking{}, apierr.Conflict("Room is already booked for the selected dates")
+	}
+
+	guests := req.Adults + req.Children
+	rooms := req.Rooms
+	if rooms == 0 {
+		rooms = 1 // ห้องคิดราคาต่อคน ไม่ได้ระบุจำนวนห้อง
+	}
+	booking := entity.Booking{
+		CheckinDate:     item.Start,
+		CheckoutDate:    item.End,
+		TotalGuestCount: guests,
+		StatusBooking:   "confirmed",
+		SpecialRequest:  strings.TrimSpace(special),
+		TotalPrice:      total,
+		MemberID:        memberID,
+		BookingDetail: []entity.BookingDetail{{
+			GuestCountPerRoom: (guests + rooms - 1) / rooms,
+			NumberOfRoom:      rooms,
+			Price:             total,
+			RoomID:            req.RoomID,
+		}},
+	}
+	if err := tx.Create(&booking).Error; err != nil {
+		return booking, apierr.Wrap(err, "Failed to create booking")
+	}
+	return booking, nil
+}
+
+var bookingList = listSpec{
+	table: "bookings",
+	sorts: map[string]string{"checkin_date": "checkin_date", "checkout_date": "checkout_date",
+		"total_price": "total_price", "created_at": "created_at"},
+	filters:     map[string]string{"member_id": "member_id", "status": "status_booking"},
+	defaultSort: "-id",
+}
+
+// GET /booking?member_id=&status=&page=&page_size=&cursor=&sort= (ค่าเริ่มต้นใหม่สุดก่อน)
+func FindBookings(c *gin.Context) {
+	var bookings []entity.Booking
+	page, ok := bookingList.find(c, dbFor(c), &bookings, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("BookingDetail.Room")
+	})
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": bookings, "pagination": page})
+}
+
+// GET /booking/:id
+func FindBookingById(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid booking ID format"))
+		return
+	}
+	var booking entity.Booking
+	if err := dbFor(c).Preload("BookingDetail.Room").First(&booking, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Booking not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": booking})
+}
+
+// POST /booking - จองห้องพักโดยตรง (ไม่ผ่านตะกร้า) ราคาคิดด้วย quoteItem เดียวกับ checkout
+func CreateBooking(c *gin.Context) {
+	defer func() { metrics.CountBooking("booking", c.Writer.Status()) }()
+	var req BookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if !memberExists(tx, req.MemberID) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("Member not found"))
+		return
+	}
+	qreq := QuoteRequest{
+		ItemType: "room",
+		RoomID:   req.RoomID,
+		CheckIn:  req.CheckIn,
+		CheckOut: req.CheckOut,
+		Adults:   req.Adults,
+		Children: req.Children,
+		Rooms:    req.Rooms,
+	}
+	item, err := quoteItem(tx, qreq, time.Now())
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, err)
+		return
+	}
+
+	booking, err := createRoomBooking(tx, *req.MemberID, qreq, item, item.Quote.Total, req.SpecialRequest)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": booking, "quote": item.Quote, "message": "Booking created successfully"})
+}
@@ -0,0 +1,540 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/metrics"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+type CartItemRequest struct {
+	MemberID *uint `json:"member_id"`
+	QuoteRequest
+}
+
+type CheckoutRequest struct {
+	MemberID      *uint  `json:"member_id"`
+	CardID        *uint  `json:"card_id"`         // ไม่ส่ง = สร้างการจองไว้ก่อน (pending) ยังไม่จ่าย
+	PaymentTypeID *uint  `json:"payment_type_id"` // ไม่ส่ง = ใช้ประเภทของบัตร
+	PromoCode     string `json:"promo_code"`
+	// ราคาที่ตีใหม่ไม่ตรงกับในตะกร้าจะตอบ 409 พร้อม price_changes จนกว่าจะยืนยันด้วยอย่างใดอย่างหนึ่ง
+	AcceptPriceChanges bool         `json:"accept_price_changes"`
+	ExpectedTotal      *money.Money `json:"expected_total"` // ยอดสุทธิ (สตางค์) ที่ผู้ใช้เห็น ถ้าส่งมาต้องตรงเสมอ
+}
+
+// priceChange คือรายการที่ราคาตอน checkout ไม่ตรงกับที่เก็บไว้ในตะกร้า
+type priceChange struct {
+	CartItemID uint        `json:"cart_item_id"`
+	Was        money.Money `json:"was"`
+	Now        money.Money `json:"now"`
+}
+
+// cartItemQuoteRequest แปลงรายการในตะกร้ากลับเป็น QuoteRequest เพื่อตีราคาใหม่
+func cartItemQuoteRequest(ci entity.CartItems) QuoteRequest {
+	req := QuoteRequest{
+		ItemType:  ci.ItemType,
+		RoomID:    ci.RoomID,
+		PackageID: ci.PackageID,
+		EventID:   ci.EventID,
+		Adults:    ci.Adults,
+		Children:  ci.Children,
+		Rooms:     ci.Rooms,
+	}
+	if !ci.CheckIn.IsZero() {
+		req.CheckIn = ci.CheckIn.Format("2006-01-02")
+	}
+	if !ci.CheckOut.IsZero() {
+		req.CheckOut = ci.CheckOut.Format("2006-01-02")
+	}
+	return req
+}
+
+// quoteQuantity คือจำนวนหน่วยที่คิดเงิน (ห้องหรือคน)
+func quoteQuantity(req QuoteRequest, item quotedItem) int {
+	if item.Quote.Unit == "per_room" {
+		return int(req.Rooms)
+	}
+	return int(req.Adults + req.Children)
+}
+
+// cartPromoLine แปลงรายการในตะกร้าที่ตีราคาแล้วเป็น promoLine
+func cartPromoLine(ci entity.CartItems, item quotedItem) promoLine {
+	return promoLine{
+		ItemType:  item.ItemType,
+		PackageID: ci.PackageID,
+		EventID:   ci.EventID,
+		RoomID:    ci.RoomID,
+		Total:     item.Quote.Total,
+	}
+}
+
+// memberCartItems คือ query รายการในตะกร้าทุกใบของสมาชิก
+func memberCartItems(tx *gorm.DB, memberID uint) *gorm.DB {
+	return tx.
+		Where("cart_id IN (?)", tx.Model(&entity.Cart{}).Select("id").Where("member_id = ?", memberID)).
+		Order("id")
+}
+
+func memberExists(tx *gorm.DB, memberID *uint) bool {
+	if memberID == nil {
+		return false
+	}
+	var count int64
+	tx.Model(&entity.Member{}).Where("id = ?", *memberID).Count(&count)
+	return count > 0
+}
+
+// memberCart คืนตะกร้าล่าสุดของสมาชิก (สร้างใหม่ถ้ายังไม่มี)
+func memberCart(tx *gorm.DB, memberID uint) (entity.Cart, error) {
+	var cart entity.Cart
+	err := tx.Where("member_id = ?", memberID).Order("id DESC").First(&cart).Error
+	if err == gorm.ErrRecordNotFound {
+		cart = entity.Cart{MemberID: memberID, Created_At: time.Now()}
+		err = tx.Create(&cart).Error
+	}
+	return cart, err
+}
+
+// GET /cart?member_id=&code= - ตะกร้าพร้อมราคาปัจจุบันของทุกรายการ (ส่ง code เพื่อดูส่วนลด)
+func FindCart(c *gin.Context) {
+	memberID, err := strconv.Atoi(c.Query("member_id"))
+	if err != nil {
+		apierr.Respond(c, apierr.Required("member_id"))
+		return
+	}
+
+	db := dbFor(c)
+	var cart entity.Cart
+	if err := db.Where("member_id = ?", memberID).Order("id DESC").
+		Preload("CartItems", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&cart).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusOK, gin.H{"data": gin.H{"member_id": memberID, "items": []gin.H{}, "total": 0}})
+		} else {
+			apierr.Respond(c, apierr.Internal(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	holds := map[uint]entity.SeatHold{}
+	{
+		ids := make([]uint, 0, len(cart.CartItems))
+		for _, ci := range cart.CartItems {
+			ids = append(ids, ci.ID)
+		}
+		var rows []entity.SeatHold
+		db.Where("cart_item_id IN ? AND status = ?", ids, entity.SeatHeld).Find(&rows)
+		for _, h := range rows {
+			holds[*h.CartItemID] = h
+		}
+	}
+
+	items := make([]gin.H, 0, len(cart.CartItems))
+	lines := make([]promoLine, 0, len(cart.CartItems))
+	var total money.Money
+	for _, ci := range cart.CartItems {
+		entry := gin.H{"item": ci}
+		if h, ok := holds[ci.ID]; ok {
+			// held ที่หมดเวลาแล้ว checkout จะพยายามกันที่นั่งใหม่
+			entry["seat_hold"] = gin.H{"seats": h.Seats, "expires_at": h.ExpiresAt, "expired": !h.ExpiresAt.After(now)}
+		}
+		item, err := quoteItem(db, cartItemQuoteRequest(ci), now)
+		if err != nil {
+			// รายการที่ตีราคาไม่ได้แล้ว (เช่น ห้องปิด) ยังแสดงอยู่แต่ checkout ไม่ผ่าน
+			entry["error"] = apierr.From(err).Detail
+		} else {
+			// อ่านอย่างเดียว: ยอดที่บันทึกไว้คงเดิมให้ checkout เทียบแล้วแจ้งราคาที่เปลี่ยน (price_changes)
+			if item.Quote.Total != ci.Total {
+				entry["price_changed"] = true
+			}
+			entry["quote"] = item.Quote
+			total += item.Quote.Total
+			lines = append(lines, cartPromoLine(ci, item))
+		}
+		items = append(items, entry)
+	}
+
+	data := gin.H{
+		"id":        cart.ID,
+		"member_id": cart.MemberID,
+		"items":     items,
+		"subtotal":  total,
+		"discount":  0,
+		"total":     total,
+	}
+	if code := c.Query("code"); code != "" && len(lines) > 0 {
+		res, err := evaluatePromotion(db, code, cart.MemberID, lines, now)
+		if err != nil {
+			data["promo_error"] = apierr.From(err).Detail
+		} else {
+			data["promo_code"] = res.Promotion.Code
+			data["discount"] = res.Discount
+			data["total"] = total - res.Discount
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// POST /cart/items - เพิ่มรายการลงตะกร้า ราคาใช้ quoteItem เดียวกับ /quote
+func AddCartItem(c *gin.Context) {
+	var req CartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	if !memberExists(db, req.MemberID) {
+		apierr.Respond(c, apierr.BadRequest("Member not found"))
+		return
+	}
+	now := time.Now()
+	item, err := quoteItem(db, req.QuoteRequest, now)
+	if err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	cart, err := memberCart(tx, *req.MemberID)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to load cart"))
+		return
+	}
+	ci := entity.CartItems{
+		ItemType:  item.ItemType,
+		Added_At:  now,
+		Quatity:   quoteQuantity(req.QuoteRequest, item),
+		EventID:   req.EventID,
+		PackageID: req.PackageID,
+		RoomID:    req.RoomID,
+		Adults:    req.Adults,
+		Children:  req.Children,
+		Rooms:     req.Rooms,
+		Total:     item.Quote.Total,
+		CartID:    cart.ID,
+	}
+	if item.ItemType == "room" {
+		ci.CheckIn, ci.CheckOut = item.Start, item.End
+	}
+	if err := tx.Create(&ci).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to add cart item"))
+		return
+	}
+
+	// แพ็คเกจ/อีเวนต์: กันที่นั่งไว้ตาม SEAT_HOLD_TTL ถ้ามีคิว waitlist อยู่ต้องเข้าคิวต่อ ไม่ให้แซงคิว
+	var hold *entity.SeatHold
+	if t, ok := seatTargetOf(item.ItemType, req.PackageID, req.EventID); ok {
+		if req.Adults+req.Children == 0 {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("adults or children is required for a "+item.ItemType))
+			return
+		}
+		u, err := loadSeatUsage(tx, t, now)
+		if err != nil {
+			tx.Rollback()
+			apierr.Respond(c, err)
+			return
+		}
+		if u.Waiting > 0 {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Conflict("Sold out; join the waitlist").With("waiting", u.Waiting))
+			return
+		}
+		expiresAt := now.Add(config.SeatHoldTTL())
+		hold = &entity.SeatHold{
+			PackageID:  t.PackageID,
+			EventID:    t.EventID,
+			MemberID:   *req.MemberID,
+			CartItemID: &ci.ID,
+			Seats:      req.Adults + req.Children,
+			Status:     entity.SeatHeld,
+			ExpiresAt:  &expiresAt,
+		}
+		if err := holdSeats(tx, hold, now); err != nil {
+			tx.Rollback()
+			apierr.Respond(c, err)
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{"item": ci, "quote": item.Quote, "seat_hold": hold}, "message": "Cart item added successfully"})
+}
+
+// DELETE /cart/items/:id
+func DeleteCartItem(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid cart item ID format"))
+		return
+	}
+
+	tx := dbFor(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	res := tx.Delete(&entity.CartItems{}, id)
+	if res.Error != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.NotFound("Cart item not found"))
+		return
+	}
+	// คืนที่นั่งที่ตะกร้ากันไว้ทันที ไม่ต้องรอหมดเวลา แล้วเสนอให้คิว waitlist
+	query := tx.Where("cart_item_id = ? AND status = ?", id, entity.SeatHeld)
+	if _, err := releaseSeats(tx, query, entity.SeatReleased, time.Now()); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to release seats"))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cart item deleted successfully"})
+}
+
+// POST /cart/checkout - ตีราคาทุกรายการใหม่ สร้าง Reservation (+ Booking สำหรับห้องพัก) แล้วล้างตะกร้า
+// ถ้าส่ง card_id จะบันทึกการชำระเงินและใบเสร็จด้วย ราคาที่เปลี่ยนจากในตะกร้าตอบ 409 จนกว่าจะยืนยันยอดใหม่
+func CheckoutCart(c *gin.Context) {
+	defer func() { metrics.CountBooking("checkout", c.Writer.Status()) }()
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if !memberExists(tx, req.MemberID) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("Member not found"))
+		return
+	}
+	var card entity.Card
+	if req.CardID != nil {
+		if err := tx.First(&card, *req.CardID).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("Card not found"))
+			return
+		}
+		if card.MemberID != *req.MemberID {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("Card belongs to another member"))
+			return
+		}
+	}
+
+	var cartItems []entity.CartItems
+	if err := memberCartItems(tx, *req.MemberID).Find(&cartItems).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if len(cartItems) == 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("Cart is empty"))
+		return
+	}
+
+	now := time.Now()
+	reservation := entity.Reservation{Status: "pending", DateTime: now, MemberID: *req.MemberID}
+	if err := tx.Create(&reservation).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create reservation"))
+		return
+	}
+
+	changes := []priceChange{}
+	quoted := make([]quotedItem, len(cartItems))
+	lines := make([]promoLine, len(cartItems))
+	for i, ci := range cartItems {
+		item, err := quoteItem(tx, cartItemQuoteRequest(ci), now)
+		if err != nil {
+			tx.Rollback()
+			if _, ok := err.(errQuote); ok {
+				apierr.Respond(c, apierr.BadRequest(fmt.Sprintf("Cart item #%d: %s", ci.ID, err.Error())))
+			} else {
+				apierr.Respond(c, apierr.Internal(err))
+			}
+			return
+		}
+		if item.Quote.Total != ci.Total {
+			changes = append(changes, priceChange{CartItemID: ci.ID, Was: ci.Total, Now: item.Quote.Total})
+		}
+		quoted[i], lines[i] = item, cartPromoLine(ci, item)
+	}
+
+	// โค้ดส่วนลด: คิดจากราคาที่เพิ่งตีใหม่ แล้วนับการใช้ใน transaction เดียวกัน
+	var promo *promoResult
+	if strings.TrimSpace(req.PromoCode) != "" {
+		res, err := evaluatePromotion(tx, req.PromoCode, *req.MemberID, lines, now)
+		if err == nil {
+			err = redeemPromotion(tx, res, *req.MemberID, reservation.ID)
+		}
+		if err != nil {
+			tx.Rollback()
+			apierr.Respond(c, err)
+			return
+		}
+		promo = &res
+	}
+
+	// ไม่สร้างการจอง/ตัดบัตรด้วยยอดที่ผู้ใช้ยังไม่เห็น
+	var total money.Money
+	for _, item := range quoted {
+		total += item.Quote.Total
+	}
+	if promo != nil {
+		total -= promo.Discount
+	}
+	if (req.ExpectedTotal != nil && *req.ExpectedTotal != total) ||
+		(req.ExpectedTotal == nil && len(changes) > 0 && !req.AcceptPriceChanges) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Prices changed; confirm the new total to check out").
+			With("price_changes", changes).With("total", total))
+		return
+	}
+
+	for i, ci := range cartItems {
+		item := quoted[i]
+		var discount money.Money
+		if promo != nil {
+			discount = promo.Allocations[i]
+		}
+
+		ri := entity.ReservationItem{
+			ReservationID: reservation.ID,
+			ItemType:      item.ItemType,
+			PackageID:     ci.PackageID,
+			EventID:       ci.EventID,
+			RoomID:        ci.RoomID,
+			CheckIn:       item.Start,
+			CheckOut:      item.End,
+			Adults:        ci.Adults,
+			Children:      ci.Children,
+			Rooms:         ci.Rooms,
+			Discount:      discount,
+			Total:         item.Quote.Total - discount,
+		}
+		if item.ItemType == "room" {
+			booking, err := createRoomBooking(tx, *req.MemberID, cartItemQuoteRequest(ci), item, ri.Total, "")
+			if err != nil {
+				tx.Rollback()
+				apierr.Respond(c, err)
+				return
+			}
+			ri.BookingID = &booking.ID
+		}
+		if _, ok := seatTargetOf(item.ItemType, ci.PackageID, ci.EventID); ok {
+			status := entity.SeatReserved
+			if req.CardID != nil {
+				status = entity.SeatConfirmed
+			}
+			if err := claimCartSeats(tx, ci, *req.MemberID, reservation.ID, status, now); err != nil {
+				tx.Rollback()
+				apierr.Respond(c, err)
+				return
+			}
+		}
+		if err := tx.Create(&ri).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to create reservation item"))
+			return
+		}
+		reservation.Total += ri.Total
+		reservation.Discount += ri.Discount
+	}
+
+	updates := map[string]any{"total": reservation.Total, "discount": reservation.Discount}
+	var payment *entity.Paymentdetail
+	if req.CardID != nil {
+		paymentTypeID := card.PaymentTypeID
+		if req.PaymentTypeID != nil {
+			paymentTypeID = *req.PaymentTypeID
+		}
+		payment = &entity.Paymentdetail{
+			MemberID:      *req.MemberID,
+			CardID:        card.ID,
+			PaymentTypeID: paymentTypeID,
+			Amount_id:     reservation.Total,
+			Payment_date:  now,
+			PatmentNumber: fmt.Sprintf("PAY-%d-%d", reservation.ID, now.Unix()),
+			Status:        "paid",
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to record payment"))
+			return
+		}
+		receipt := entity.Receipt{MemberID: *req.MemberID, PaymentdetailID: payment.ID, Issued_date: now, Amount: reservation.Total}
+		if err := tx.Create(&receipt).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to issue receipt"))
+			return
+		}
+		updates["status"] = "paid"
+	}
+	if err := tx.Model(&reservation).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update reservation"))
+		return
+	}
+
+	ids := make([]uint, 0, len(cartItems))
+	for _, ci := range cartItems {
+		ids = append(ids, ci.ID)
+	}
+	if err := tx.Delete(&entity.CartItems{}, ids).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to clear cart"))
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	db.Preload("Items").First(&reservation, reservation.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"data":          reservation,
+		"payment":       payment,
+		"price_changes": changes,
+		"message":       "Checkout completed successfully",
+	})
+}
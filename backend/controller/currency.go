@@ -0,0 +1,331 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+type CurrencyRequest struct {
+	Name     *string `json:"name"`
+	Symbol   *string `json:"symbol"`
+	Exponent *uint   `json:"exponent"`
+	Rate     string  `json:"rate"` // 1 THB = rate หน่วยของสกุลนี้ เช่น "0.0275" (ไม่ส่ง = ไม่เปลี่ยนอัตรา)
+}
+
+// CurrencyResponse คือสกุลเงินพร้อมอัตราล่าสุด
+type CurrencyResponse struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Symbol      string     `json:"symbol"`
+	Exponent    uint       `json:"exponent"`
+	Rate        string     `json:"rate,omitempty"`
+	EffectiveAt *time.Time `json:"effective_at,omitempty"`
+}
+
+// priceConverter แปลงราคา THB เป็นสกุลที่ขอ (nil = ไม่ได้ขอสกุลอื่น)
+type priceConverter func(money.Money) *money.Amount
+
+// latestRate คืออัตราล่าสุดของสกุลเงิน (THB = 1 เสมอ)
+func latestRate(tx *gorm.DB, cur entity.Currency) (*entity.ExchangeRate, error) {
+	if cur.Code == money.Base {
+		return &entity.ExchangeRate{CurrencyCode: money.Base, RateMicros: money.RateScale}, nil
+	}
+	var rate entity.ExchangeRate
+	err := tx.Where("currency_code = ?", cur.Code).Order("effective_at DESC, id DESC").First(&rate).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func toCurrencyResponse(cur entity.Currency, rate *entity.ExchangeRate) CurrencyResponse {
+	res := CurrencyResponse{Code: cur.Code, Name: cur.Name, Symbol: cur.Symbol, Exponent: cur.Exponent}
+	if rate != nil {
+		res.Rate = money.FormatRate(rate.RateMicros)
+		if !rate.EffectiveAt.IsZero() {
+			t := rate.EffectiveAt
+			res.EffectiveAt = &t
+		}
+	}
+	return res
+}
+
+// currencyFromQuery อ่าน ?currency= แล้วคืนตัวแปลงราคา ถ้าสกุลไม่รู้จักหรือยังไม่มีอัตราจะตอบ 400 แล้วคืน false
+func currencyFromQuery(c *gin.Context, tx *gorm.DB) (priceConverter, bool) {
+	code := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if code == "" {
+		return nil, true
+	}
+	var cur entity.Currency
+	if err := tx.Where("code = ?", code).First(&cur).Error; err != nil {
+		apierr.Respond(c, apierr.BadRequest("Unknown currency "+code))
+		return nil, false
+	}
+	rate, err := latestRate(tx, cur)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return nil, false
+	}
+	if rate == nil {
+		apierr.Respond(c, apierr.BadRequest("No exchange rate for "+code))
+		return nil, false
+	}
+	r := money.Rate{Currency: money.Currency{Code: cur.Code, Exponent: int(cur.Exponent)}, Micros: rate.RateMicros}
+	return func(m money.Money) *money.Amount {
+		a := money.Convert(m, r)
+		return &a
+	}, true
+}
+
+// GET /currency
+func FindCurrencies(c *gin.Context) {
+	db := dbFor(c)
+	var currencies []entity.Currency
+	if err := db.Order("code").Find(&currencies).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	res := make([]CurrencyResponse, 0, len(currencies))
+	for _, cur := range currencies {
+		rate, err := latestRate(db, cur)
+		if err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+		res = append(res, toCurrencyResponse(cur, rate))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": res, "base": money.Base})
+}
+
+// GET /currency/:code/rates - ประวัติอัตราแลกเปลี่ยน
+func FindExchangeRates(c *gin.Context) {
+	code := strings.ToUpper(c.Param("code"))
+	var rates []entity.ExchangeRate
+	if err := dbFor(c).Where("currency_code = ?", code).Order("effective_at DESC, id DESC").Find(&rates).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+// PUT /currency/:code - สร้าง/แก้สกุลเงิน และบันทึกอัตราใหม่ถ้าส่ง rate มา
+func UpsertCurrency(c *gin.Context) {
+	code := strings.ToUpper(strings.TrimSpace(c.Param("code")))
+	if len(code) != 3 {
+		apierr.Respond(c, apierr.BadRequest("Currency code must be 3 letters"))
+		return
+	}
+	var req CurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	if code == money.Base && req.Rate != "" {
+		apierr.Respond(c, apierr.BadRequest(money.Base+" is the base currency; its rate is always 1"))
+		return
+	}
+	var micros int64
+	if req.Rate != "" {
+		var err error
+		if micros, err = money.ParseRate(req.Rate); err != nil {
+			apierr.Respond(c, apierr.BadRequest("rate must be a positive decimal with at most 6 decimal places"))
+			return
+		}
+	}
+	if req.Exponent != nil && *req.Exponent > 4 {
+		apierr.Respond(c, apierr.BadRequest("exponent must be between 0 and 4"))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	cur := entity.Currency{Code: code, Exponent: 2}
+	if err := tx.Where("code = ?", code).FirstOrInit(&cur).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if req.Name != nil {
+		cur.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Symbol != nil {
+		cur.Symbol = strings.TrimSpace(*req.Symbol)
+	}
+	if req.Exponent != nil {
+		cur.Exponent = *req.Exponent
+	}
+	if err := tx.Save(&cur).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to save currency"))
+		return
+	}
+	if micros > 0 {
+		rate := entity.ExchangeRate{CurrencyCode: code, RateMicros: micros, EffectiveAt: time.Now(), Source: "manual"}
+		if err := tx.Create(&rate).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to save exchange rate"))
+			return
+		}
+	}
+	rate, err := latestRate(tx, cur)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": toCurrencyResponse(cur, rate), "message": "Currency saved successfully"})
+}
+
+// errCSVLine คือข้อผิดพลาดของแถวใน CSV (นับแถวจาก 1)
+type errCSVLine struct {
+	Line int    `json:"line"`
+	Err  string `json:"error"`
+}
+
+// parseRateCSV อ่าน CSV แถวละ code,rate[,effective_date] แถวแรกเป็น header ได้
+// known คือรหัสสกุลที่มีในระบบ (ไม่รวม THB)
+func parseRateCSV(r io.Reader, known map[string]bool, now time.Time) ([]entity.ExchangeRate, []errCSVLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rates []entity.ExchangeRate
+	var errs []errCSVLine
+	var line int
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				errs = append(errs, errCSVLine{Line: pe.Line, Err: pe.Err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ = reader.FieldPos(0)
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(rec[0]), "code") {
+			continue
+		}
+		if len(rec) < 2 {
+			errs = append(errs, errCSVLine{Line: line, Err: "expected code,rate[,effective_date]"})
+			continue
+		}
+		code := strings.ToUpper(strings.TrimSpace(rec[0]))
+		if !known[code] {
+			errs = append(errs, errCSVLine{Line: line, Err: "unknown currency " + code})
+			continue
+		}
+		micros, err := money.ParseRate(rec[1])
+		if err != nil {
+			errs = append(errs, errCSVLine{Line: line, Err: fmt.Sprintf("invalid rate %q", rec[1])})
+			continue
+		}
+		effective := now
+		if len(rec) > 2 && strings.TrimSpace(rec[2]) != "" {
+			t, err := parseYMD(strings.TrimSpace(rec[2]))
+			if err != nil {
+				errs = append(errs, errCSVLine{Line: line, Err: "effective_date must be YYYY-MM-DD"})
+				continue
+			}
+			effective = t
+		}
+		rates = append(rates, entity.ExchangeRate{CurrencyCode: code, RateMicros: micros, EffectiveAt: effective, Source: "csv"})
+	}
+	return rates, errs, nil
+}
+
+// POST /currency/rates/import - นำเข้าอัตราจาก CSV (multipart field "file" หรือ body เป็น text/csv)
+// ทั้งไฟล์ต้องถูกต้องจึงจะบันทึก
+func ImportExchangeRates(c *gin.Context) {
+	var body io.Reader = c.Request.Body
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			apierr.Respond(c, apierr.BadRequest("Cannot read uploaded file"))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	db := dbFor(c)
+	var codes []string
+	if err := db.Model(&entity.Currency{}).Where("code <> ?", money.Base).Pluck("code", &codes).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	known := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		known[code] = true
+	}
+
+	rates, errs, err := parseRateCSV(body, known, time.Now())
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("Cannot read CSV").WithCause(err))
+		return
+	}
+	if len(errs) > 0 {
+		apierr.Respond(c, apierr.BadRequest("CSV contains invalid rows").With("lines", errs))
+		return
+	}
+	if len(rates) == 0 {
+		apierr.Respond(c, apierr.BadRequest("CSV contains no rates"))
+		return
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&rates).Error
+	}); err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to import rates"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": rates, "message": fmt.Sprintf("Imported %d exchange rates successfully", len(rates))})
+}
+
+func (conv priceConverter) applyPackage(p *entity.Package) {
+	if conv != nil {
+		p.DisplayPrice = conv(money.FromMajor(int64(p.Price)))
+	}
+}
+
+func (conv priceConverter) applyRoom(r *entity.Room) {
+	if conv != nil {
+		r.DisplayPrice = conv(money.FromMajor(int64(r.Price)))
+	}
+}
+
+func (conv priceConverter) applyEvent(e *entity.Event) {
+	if conv != nil {
+		e.DisplayPrice = conv(money.FromFloat(e.Price))
+	}
+}
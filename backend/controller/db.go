@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"gorm.io/gorm"
+)
+
+// dbFor คือฐานข้อมูลที่ผูกกับ request: query เป็น span ลูกของ request และ log ของ GORM มี request_id
+// handler ใช้ตัวนี้แทน config.DB() (งานเบื้องหลังที่ไม่มี request ยังใช้ config.DB())
+func dbFor(c *gin.Context) *gorm.DB {
+	return config.DB().WithContext(c.Request.Context())
+}
@@ -1,12 +1,12 @@
 package controller
 
 import (
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
 	"net/http"
 	"os"
 	"path/filepath"
-	"github.com/gin-gonic/gin"
-	"github.com/kookkikiv/sa_project/backend/config"
-	"github.com/kookkikiv/sa_project/backend/entity"
 )
 
 // UploadDocument handles file upload
@@ -17,7 +17,7 @@ func UploadDocument(c *gin.Context) {
 	// Single file
 	file, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file is uploaded"})
+		apierr.Respond(c, apierr.BadRequest("No file is uploaded"))
 		return
 	}
 
@@ -30,12 +30,12 @@ func UploadDocument(c *gin.Context) {
 	// Save the file
 	filePath := filepath.Join(uploadDir, file.Filename)
 	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierr.Respond(c, apierr.Internal(err))
 		return
 	}
 
 	// Save to database
-	db := config.DB()
+	db := dbFor(c)
 	doc := entity.DocumentPath{
 		DocumentPath: filePath,
 	}
@@ -46,7 +46,7 @@ func UploadDocument(c *gin.Context) {
 	}
 
 	if err := db.Create(&doc).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierr.Respond(c, apierr.Internal(err))
 		return
 	}
 
@@ -54,12 +54,12 @@ func UploadDocument(c *gin.Context) {
 }
 
 func GetDocumentsByUser(c *gin.Context) {
-    userID := c.Param("user_id")
-    var docs []entity.DocumentPath
-    db := config.DB()
-    if err := db.Where("user_id = ?", userID).Find(&docs).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
-    c.JSON(http.StatusOK, docs)
+	userID := c.Param("user_id")
+	var docs []entity.DocumentPath
+	db := dbFor(c)
+	if err := db.Where("user_id = ?", userID).Find(&docs).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, docs)
 }
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+func preloadEvent(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("EventType").
+		Preload("Location").
+		Preload("Province").
+		Preload("District").
+		Preload("Subdistrict")
+}
+
+var eventList = listSpec{
+	table: "events",
+	sorts: map[string]string{"name": "event_name", "price": "price", "status": "status", "added_at": "added_at",
+		"created_at": "created_at"},
+	filters: map[string]string{"event_type_id": "event_type_id", "province_id": "province_id", "status": "status",
+		"admin_id": "admin_id", "location_id": "location_id"},
+}
+
+// GET /event?page=&page_size=&cursor=&sort=&event_type_id=&province_id=&status=&currency=
+func FindEvent(c *gin.Context) {
+	db := dbFor(c)
+	conv, ok := currencyFromQuery(c, db)
+	if !ok {
+		return
+	}
+
+	var events []entity.Event
+	page, ok := eventList.find(c, db, &events, preloadEvent)
+	if !ok {
+		return
+	}
+	for i := range events {
+		conv.applyEvent(&events[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"data": events, "pagination": page})
+}
+
+// GET /event/:id?currency=
+func FindEventById(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid event ID format"))
+		return
+	}
+	db := dbFor(c)
+	conv, ok := currencyFromQuery(c, db)
+	if !ok {
+		return
+	}
+	var event entity.Event
+	if err := preloadEvent(db).First(&event, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Event not found"))
+		return
+	}
+	conv.applyEvent(&event)
+	c.JSON(http.StatusOK, gin.H{"data": event})
+}
@@ -1,12 +1,12 @@
-
 package controller
 
 import (
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kookkikiv/sa_project/backend/config"
 	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
 )
 
 type facilityInput struct {
@@ -20,7 +20,7 @@ type facilityInput struct {
 func CreateFacility(c *gin.Context) {
 	var in facilityInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
@@ -30,12 +30,12 @@ func CreateFacility(c *gin.Context) {
 	if in.Type == "room" {
 		// ดึง accommodation_id ของห้องมาเก็บด้วย
 		if in.RoomID == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "room_id is required for type=room"})
+			apierr.Respond(c, apierr.BadRequest("room_id is required for type=room"))
 			return
 		}
 		var room entity.Room
-		if err := config.DB().Select("id", "accommodation_id").First(&room, *in.RoomID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "room not found"})
+		if err := dbFor(c).Select("id", "accommodation_id").First(&room, *in.RoomID).Error; err != nil {
+			apierr.Respond(c, apierr.BadRequest("room not found"))
 			return
 		}
 		roomID = in.RoomID
@@ -43,7 +43,7 @@ func CreateFacility(c *gin.Context) {
 	} else {
 		// type = accommodation
 		if in.AccommodationID == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "accommodation_id is required for type=accommodation"})
+			apierr.Respond(c, apierr.BadRequest("accommodation_id is required for type=accommodation"))
 			return
 		}
 		accID = in.AccommodationID
@@ -57,12 +57,16 @@ func CreateFacility(c *gin.Context) {
 		RoomID:          roomID,
 	}
 
-	if err := config.DB().Create(&f).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := dbFor(c).Create(&f).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
 		return
 	}
 
-	config.DB().Preload("Accommodation").Preload("Room").First(&f, f.ID)
+	if f.AccommodationID != nil {
+		refreshSearch(c, searchAccommodation, *f.AccommodationID)
+	}
+
+	dbFor(c).Preload("Accommodation").Preload("Room").First(&f, f.ID)
 	c.JSON(http.StatusCreated, gin.H{"data": f, "message": "created"})
 }
 
@@ -72,34 +76,35 @@ func UpdateFacilityById(c *gin.Context) {
 
 	var in facilityInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
 	var f entity.Facility
-	if err := config.DB().First(&f, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "facility not found"})
+	if err := dbFor(c).First(&f, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("facility not found"))
 		return
 	}
 
 	var accID *uint
 	var roomID *uint
+	oldAccID := f.AccommodationID
 
 	if in.Type == "room" {
 		if in.RoomID == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "room_id is required for type=room"})
+			apierr.Respond(c, apierr.BadRequest("room_id is required for type=room"))
 			return
 		}
 		var room entity.Room
-		if err := config.DB().Select("id", "accommodation_id").First(&room, *in.RoomID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "room not found"})
+		if err := dbFor(c).Select("id", "accommodation_id").First(&room, *in.RoomID).Error; err != nil {
+			apierr.Respond(c, apierr.BadRequest("room not found"))
 			return
 		}
 		roomID = in.RoomID
 		accID = room.AccommodationID // <- อัปเดตให้ตรงกับห้องใหม่เสมอ
 	} else {
 		if in.AccommodationID == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "accommodation_id is required for type=accommodation"})
+			apierr.Respond(c, apierr.BadRequest("accommodation_id is required for type=accommodation"))
 			return
 		}
 		accID = in.AccommodationID
@@ -113,59 +118,79 @@ func UpdateFacilityById(c *gin.Context) {
 		"room_id":          roomID,
 	}
 
-	if err := config.DB().Model(&f).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := dbFor(c).Model(&f).Updates(updates).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
 		return
 	}
 
-	config.DB().Preload("Accommodation").Preload("Room").First(&f, id)
+	// ชื่อสิ่งอำนวยความสะดวกอยู่ในข้อความค้นหาของที่พัก ทั้งที่เดิมและที่ใหม่
+	for _, a := range []*uint{oldAccID, accID} {
+		if a != nil {
+			refreshSearch(c, searchAccommodation, *a)
+		}
+	}
+
+	dbFor(c).Preload("Accommodation").Preload("Room").First(&f, id)
 	c.JSON(http.StatusOK, gin.H{"data": f, "message": "updated"})
 }
 
-// GET /facility
+var facilityList = listSpec{
+	table:   "facilities",
+	sorts:   map[string]string{"name": "name", "type": "type", "created_at": "created_at"},
+	filters: map[string]string{"type": "type", "accommodation_id": "accommodation_id", "room_id": "room_id"},
+}
+
+// GET /facility?page=&page_size=&sort=&type=&accommodation_id=&room_id=
 func FindFacility(c *gin.Context) {
 	var items []entity.Facility
-	if err := config.DB().
-		Preload("Accommodation").
-		Preload("Room").
-		Find(&items).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, ok := facilityList.find(c, dbFor(c), &items, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Accommodation").Preload("Room")
+	})
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": items})
+	c.JSON(http.StatusOK, gin.H{"data": items, "pagination": page})
 }
 
 func FindFacilityById(c *gin.Context) {
 	id := c.Param("id")
 
 	var item entity.Facility
-	if err := config.DB().
+	if err := dbFor(c).
 		Preload("Accommodation").
 		Preload("Room").
 		First(&item, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "facility not found"})
+		apierr.Respond(c, apierr.NotFound("facility not found"))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": item})
 }
+
 // DELETE /facility/:id
 func DeleteFacilityById(c *gin.Context) {
 	var item entity.Facility
 	id := c.Param("id")
 
-	if err := config.DB().Where("id = ?", id).First(&item).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "facility not found"})
+	if err := dbFor(c).Where("id = ?", id).First(&item).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("facility not found"))
 		return
 	}
 
+	accIDs := []uint{}
+	dbFor(c).Raw("SELECT accommodation_id FROM accommodation_facility WHERE facility_id = ?", item.ID).Scan(&accIDs)
+	if item.AccommodationID != nil {
+		accIDs = append(accIDs, *item.AccommodationID)
+	}
+
 	// เคลียร์ many2many (ถ้ามีการตั้ง FK)
-	_ = config.DB().Model(&item).Association("Accommodations").Clear()
-	_ = config.DB().Model(&item).Association("Rooms").Clear()
+	_ = dbFor(c).Model(&item).Association("Accommodations").Clear()
+	_ = dbFor(c).Model(&item).Association("Rooms").Clear()
 
-	if err := config.DB().Delete(&item, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete facility"})
+	if err := dbFor(c).Delete(&item, id).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "failed to delete facility"))
 		return
 	}
+	refreshSearch(c, searchAccommodation, accIDs...)
 	c.JSON(http.StatusOK, gin.H{"message": "facility deleted successfully"})
 }
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"gorm.io/gorm"
+)
+
+// geoRef คือจังหวัด/อำเภอ/ตำบลที่รายการอ้าง (nil = ไม่ได้ระบุ)
+type geoRef struct {
+	ProvinceID    *uint `json:"province_id"`
+	DistrictID    *uint `json:"district_id"`
+	SubdistrictID *uint `json:"subdistrict_id"`
+}
+
+// optionalID แปลง 0 (ฟอร์มไม่ได้เลือก) เป็น nil ไม่ให้เก็บ FK ชี้ไป id 0
+func optionalID(id *uint) *uint {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	return id
+}
+
+// geoNodeInfo คือข้อมูลที่ใช้ตรวจของ 1 จังหวัด/อำเภอ/ตำบล (parent = จังหวัดของอำเภอ / อำเภอของตำบล)
+type geoNodeInfo struct {
+	parent  uint
+	retired bool
+}
+
+// geoIndex คือจังหวัด/อำเภอ/ตำบลที่โหลดมาตรวจ (ไม่มีใน map = ไม่พบหรือถูกลบ)
+type geoIndex struct {
+	provinces, districts, subdistricts map[uint]geoNodeInfo
+}
+
+// loadGeoIndex โหลดจังหวัด/อำเภอ/ตำบลที่ refs อ้าง (ไม่ส่ง refs = โหลดทั้งหมด ใช้ตอน audit)
+func loadGeoIndex(tx *gorm.DB, refs ...geoRef) (geoIndex, error) {
+	ix := geoIndex{map[uint]geoNodeInfo{}, map[uint]geoNodeInfo{}, map[uint]geoNodeInfo{}}
+	var pIDs, dIDs, sIDs []uint
+	for _, r := range refs {
+		if r.ProvinceID != nil {
+			pIDs = append(pIDs, *r.ProvinceID)
+		}
+		if r.DistrictID != nil {
+			dIDs = append(dIDs, *r.DistrictID)
+		}
+		if r.SubdistrictID != nil {
+			sIDs = append(sIDs, *r.SubdistrictID)
+		}
+	}
+	all := len(refs) == 0
+	for _, t := range []struct {
+		table, parent string
+		ids           []uint
+		into          map[uint]geoNodeInfo
+	}{
+		{"provinces", "0", pIDs, ix.provinces},
+		{"districts", "province_id", dIDs, ix.districts},
+		{"subdistricts", "district_id", sIDs, ix.subdistricts},
+	} {
+		if !all && len(t.ids) == 0 {
+			continue
+		}
+		q := tx.Table(t.table).Select("id, " + t.parent + " AS parent, retired_at IS NOT NULL AS retired").
+			Where("deleted_at IS NULL")
+		if !all {
+			q = q.Where("id IN ?", t.ids)
+		}
+		var rows []struct {
+			ID      uint
+			Parent  uint
+			Retired bool
+		}
+		if err := q.Scan(&rows).Error; err != nil {
+			return ix, err
+		}
+		for _, r := range rows {
+			t.into[r.ID] = geoNodeInfo{parent: r.Parent, retired: r.Retired}
+		}
+	}
+	return ix, nil
+}
+
+// geoIssue คือปัญหา 1 อย่างของการอ้างพื้นที่
+type geoIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// issues ตรวจว่าพื้นที่ที่อ้างมีอยู่จริงและอำเภออยู่ในจังหวัด ตำบลอยู่ในอำเภอ
+// checkRetired = รายงานการอ้างรายการที่เลิกใช้แล้วด้วย
+func (ix geoIndex) issues(r geoRef, checkRetired bool) []geoIssue {
+	var out []geoIssue
+	add := func(code, format string, args ...any) {
+		out = append(out, geoIssue{code, fmt.Sprintf(format, args...)})
+	}
+	levels := []struct {
+		name  string
+		id    *uint
+		nodes map[uint]geoNodeInfo
+	}{
+		{"province", r.ProvinceID, ix.provinces},
+		{"district", r.DistrictID, ix.districts},
+		{"subdistrict", r.SubdistrictID, ix.subdistricts},
+	}
+	for _, l := range levels {
+		if l.id == nil {
+			continue
+		}
+		n, ok := l.nodes[*l.id]
+		switch {
+		case *l.id == 0:
+			add(l.name+"_zero", "%s_id is 0", l.name)
+		case !ok:
+			add(l.name+"_not_found", "%s %d not found", l.name, *l.id)
+		case checkRetired && n.retired:
+			add(l.name+"_retired", "%s %d is retired", l.name, *l.id)
+		}
+	}
+	if r.DistrictID != nil && r.ProvinceID != nil {
+		if d, ok := ix.districts[*r.DistrictID]; ok && d.parent != *r.ProvinceID {
+			add("district_not_in_province", "district %d is not in province %d", *r.DistrictID, *r.ProvinceID)
+		}
+	}
+	if r.SubdistrictID != nil && r.DistrictID != nil {
+		if s, ok := ix.subdistricts[*r.SubdistrictID]; ok && s.parent != *r.DistrictID {
+			add("subdistrict_not_in_district", "subdistrict %d is not in district %d", *r.SubdistrictID, *r.DistrictID)
+		}
+	}
+	if r.SubdistrictID != nil && r.DistrictID == nil {
+		add("district_missing", "subdistrict %d is set without a district", *r.SubdistrictID)
+	}
+	if r.DistrictID != nil && r.ProvinceID == nil {
+		add("province_missing", "district %d is set without a province", *r.DistrictID)
+	}
+	return out
+}
+
+// checkGeoHierarchy คือตัวตรวจที่ใช้ร่วมกันตอนสร้าง/แก้รายการที่มีจังหวัด/อำเภอ/ตำบล
+// ระดับบนที่ไม่ได้ส่งมาจะเติมจากระดับล่าง (ส่งแค่ตำบลก็ได้อำเภอและจังหวัด) แล้วตรวจว่าอยู่ในกันจริง
+// ห้ามเลือกรายการที่เลิกใช้แล้ว ยกเว้นเป็นค่าเดิมของรายการที่แก้ (prev) คืน errQuote เมื่อไม่ผ่าน
+func checkGeoHierarchy(tx *gorm.DB, r geoRef, prev geoRef) (geoRef, error) {
+	r = geoRef{optionalID(r.ProvinceID), optionalID(r.DistrictID), optionalID(r.SubdistrictID)}
+	ix, err := loadGeoIndex(tx, r)
+	if err != nil {
+		return r, err
+	}
+	if r.SubdistrictID != nil && r.DistrictID == nil {
+		if s, ok := ix.subdistricts[*r.SubdistrictID]; ok {
+			r.DistrictID = &s.parent
+		}
+	}
+	if r.DistrictID != nil && r.ProvinceID == nil {
+		if ix, err = loadGeoIndex(tx, r); err != nil {
+			return r, err
+		}
+		if d, ok := ix.districts[*r.DistrictID]; ok {
+			r.ProvinceID = &d.parent
+		}
+	}
+	if ix, err = loadGeoIndex(tx, r); err != nil {
+		return r, err
+	}
+	same := func(a, b *uint) bool { return a != nil && b != nil && *a == *b }
+	for _, issue := range ix.issues(r, true) {
+		switch issue.Code {
+		case "province_retired":
+			if same(r.ProvinceID, prev.ProvinceID) {
+				continue
+			}
+		case "district_retired":
+			if same(r.DistrictID, prev.DistrictID) {
+				continue
+			}
+		case "subdistrict_retired":
+			if same(r.SubdistrictID, prev.SubdistrictID) {
+				continue
+			}
+		}
+		return r, errQuote{issue.Message}
+	}
+	return r, nil
+}
+
+// ตารางที่อ้างจังหวัด/อำเภอ/ตำบล และคอลัมน์ชื่อที่แสดงในผล audit
+var geoAuditTables = []struct {
+	table, name string
+	district    bool
+	subdistrict bool
+}{
+	{"accommodations", "name", true, true},
+	{"packages", "name", true, true},
+	{"events", "event_name", true, true},
+	{"service_areas", "status", true, false},
+	{"locations", "name", false, false},
+}
+
+// geoAuditRow คือรายการที่อ้างพื้นที่ไม่ถูกต้อง
+type geoAuditRow struct {
+	Table  string     `json:"table"`
+	ID     uint       `json:"id"`
+	Name   string     `json:"name"`
+	Ref    geoRef     `json:"ref"`
+	Issues []geoIssue `json:"issues"`
+}
+
+// GET /thailand/audit?table=&include_retired=true - รายการที่พัก/แพ็คเกจ/อีเวนต์/พื้นที่บริการ/สถานที่ที่อ้างจังหวัด/อำเภอ/ตำบลไม่สอดคล้องกัน
+// (ไม่พบ, id 0, อำเภอไม่อยู่ในจังหวัด, ตำบลไม่อยู่ในอำเภอ) include_retired=true รายงานรายการที่อ้างพื้นที่ที่เลิกใช้แล้วด้วย
+func AuditGeoHierarchy(c *gin.Context) {
+	db := dbFor(c)
+	checkRetired := c.Query("include_retired") == "true"
+	ix, err := loadGeoIndex(db)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+
+	out := []geoAuditRow{}
+	summary := map[string]int{}
+	for _, t := range geoAuditTables {
+		if v := c.Query("table"); v != "" && v != t.table {
+			continue
+		}
+		cols := "id, " + t.name + " AS name, province_id"
+		if t.district {
+			cols += ", district_id"
+		}
+		if t.subdistrict {
+			cols += ", subdistrict_id"
+		}
+		var rows []struct {
+			ID            uint
+			Name          string
+			ProvinceID    *uint
+			DistrictID    *uint
+			SubdistrictID *uint
+		}
+		if err := db.Table(t.table).Select(cols).Where("deleted_at IS NULL").Order("id").Scan(&rows).Error; err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+		for _, r := range rows {
+			ref := geoRef{r.ProvinceID, r.DistrictID, r.SubdistrictID}
+			if issues := ix.issues(ref, checkRetired); len(issues) > 0 {
+				out = append(out, geoAuditRow{Table: t.table, ID: r.ID, Name: r.Name, Ref: ref, Issues: issues})
+				summary[t.table]++
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Table < out[j].Table })
+	c.JSON(http.StatusOK, gin.H{"data": out, "total": len(out), "summary": summary})
+}
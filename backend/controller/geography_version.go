@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// findGeographyDependents ใส่ ID ของที่พัก/แพ็คเกจ/อีเวนต์/สถานที่/พื้นที่บริการที่อ้างรายการที่เปลี่ยนหรือเลิกใช้
+// ลงใน diff (นับเฉพาะแถวที่ยังไม่ถูกลบ) ให้ admin ตามแก้ก่อน/หลังวันที่มีผล
+func findGeographyDependents(tx *gorm.DB, diff *geographyDiff) error {
+	for _, level := range geographyLevels {
+		byID := map[uint]*geographyDiffItem{}
+		var ids []uint
+		for _, item := range diff.all {
+			if item.Level == level.name && item.id != 0 {
+				byID[item.id] = item
+				ids = append(ids, item.id)
+			}
+		}
+		column := level.name + "_id"
+		for from := 0; from < len(ids); from += geographyBatchSize {
+			chunk := ids[from:min(from+geographyBatchSize, len(ids))]
+			for _, table := range level.dependents {
+				var refs []struct {
+					ID  uint
+					Ref uint
+				}
+				if err := tx.Table(table).Select("id, "+column+" AS ref").
+					Where(column+" IN ? AND deleted_at IS NULL", chunk).
+					Order("id").Scan(&refs).Error; err != nil {
+					return fmt.Errorf("dependents in %s: %w", table, err)
+				}
+				for _, r := range refs {
+					item := byID[r.Ref]
+					if item.Dependents == nil {
+						item.Dependents = map[string][]uint{}
+					}
+					item.Dependents[table] = append(item.Dependents[table], r.ID)
+					diff.Dependents[table]++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// geographyChecksum คือ sha256 ของชุดข้อมูลหลัง normalize (เรียงตามรหัส) ชุดเดียวกันได้ค่าเดียวกันเสมอ
+func geographyChecksum(rows map[string][]geographyRow) string {
+	h := sha256.New()
+	for _, level := range geographyLevels {
+		sorted := append([]geographyRow(nil), rows[level.name]...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Code < sorted[j].Code })
+		for _, r := range sorted {
+			fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s\n", level.name, r.Code, r.Parent, r.NameTh, r.NameEn, r.Zip)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordGeographyVersion บันทึกเวอร์ชันและทุกรายการที่เปลี่ยนใน tx เดียวกับการนำเข้า
+func recordGeographyVersion(tx *gorm.DB, payload geographyImportPayload, checksum string, diff *geographyDiff, jobID *uint) (*entity.GeographyVersion, error) {
+	effective, _ := parseYMD(payload.EffectiveDate)
+	name := payload.Version
+	if name == "" {
+		// ค่าเริ่มต้นคือวันที่มีผล ถ้าวันเดียวกันนำเข้าหลายครั้งเติม .2, .3, ...
+		name = payload.EffectiveDate
+		for n := 2; ; n++ {
+			var count int64
+			if err := tx.Model(&entity.GeographyVersion{}).Where("version = ?", name).Count(&count).Error; err != nil {
+				return nil, err
+			}
+			if count == 0 {
+				break
+			}
+			name = payload.EffectiveDate + "." + strconv.Itoa(n)
+		}
+	}
+	version := entity.GeographyVersion{
+		Version:       name,
+		EffectiveDate: effective,
+		Source:        payload.Source,
+		Checksum:      checksum,
+		Note:          payload.Note,
+		JobID:         jobID,
+	}
+	for _, n := range diff.Summary {
+		version.Added += n.Added
+		version.Changed += n.Changed
+		version.Retired += n.Retired
+		version.Restored += n.Restored
+		version.Unchanged += n.Unchanged
+	}
+	for _, n := range diff.Dependents {
+		version.Dependents += n
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, fmt.Errorf("record geography version %s: %w", name, err)
+	}
+
+	changes := make([]entity.GeographyChange, 0, len(diff.all))
+	for _, item := range diff.all {
+		ch := entity.GeographyChange{
+			GeographyVersionID: version.ID,
+			Level:              item.Level,
+			Code:               item.Code,
+			NameTh:             item.NameTh,
+			Action:             item.Action,
+		}
+		if len(item.Changes) > 0 {
+			ch.Fields, _ = json.Marshal(item.Changes)
+		}
+		if len(item.Dependents) > 0 {
+			ch.Dependents, _ = json.Marshal(item.Dependents)
+		}
+		changes = append(changes, ch)
+	}
+	if len(changes) > 0 {
+		if err := tx.CreateInBatches(&changes, geographyBatchSize).Error; err != nil {
+			return nil, fmt.Errorf("record geography changes: %w", err)
+		}
+	}
+	return &version, nil
+}
+
+// geographyReferences นับแถวที่อ้างจังหวัด/อำเภอ/ตำบลอยู่ (รวมแถวที่ soft delete เพราะ FK ยังกันการลบ)
+func geographyReferences(tx *gorm.DB) (map[string]int64, error) {
+	refs := map[string]int64{}
+	for _, level := range geographyLevels {
+		for _, table := range level.dependents {
+			var n int64
+			if err := tx.Table(table).Where(level.name + "_id IS NOT NULL").Count(&n).Error; err != nil {
+				return nil, err
+			}
+			if n > 0 {
+				refs[table+"."+level.name+"_id"] = n
+			}
+		}
+	}
+	return refs, nil
+}
+
+// GET /thailand/versions - ชุดข้อมูลพื้นที่ที่นำเข้าแล้ว ใหม่สุดก่อน
+func FindGeographyVersions(c *gin.Context) {
+	var versions []entity.GeographyVersion
+	if err := dbFor(c).Order("effective_date DESC, id DESC").Find(&versions).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": versions})
+}
+
+// GET /thailand/versions/:id/changes?action=&level=&code=&limit=&offset= - รายการที่เปลี่ยนพร้อมแถวที่อ้างอยู่
+func FindGeographyChanges(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid version ID format"))
+		return
+	}
+	db := dbFor(c)
+	var version entity.GeographyVersion
+	if err := db.First(&version, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Geography version not found"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		apierr.Respond(c, apierr.BadRequest("limit must be between 1 and 1000"))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		apierr.Respond(c, apierr.BadRequest("offset must not be negative"))
+		return
+	}
+
+	q := db.Model(&entity.GeographyChange{}).Where("geography_version_id = ?", version.ID)
+	for _, f := range []string{"action", "level", "code"} {
+		if v := c.Query(f); v != "" {
+			q = q.Where(f+" = ?", v)
+		}
+	}
+	if c.Query("with_dependents") == "true" {
+		q = q.Where("dependents IS NOT NULL AND dependents <> ''")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	var changes []entity.GeographyChange
+	if err := q.Order("id").Limit(limit).Offset(offset).Find(&changes).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": changes, "total": total, "version": version})
+}
@@ -2,97 +2,520 @@ package controller
 
 import (
 	"net/http"
-	"github.com/kookkikiv/sa_project/backend/config"
-	"github.com/kookkikiv/sa_project/backend/entity"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
 )
 
-// GET /guide
+// ---------- DTO รับจาก UI ----------
+// slice ที่เป็น nil ตอน update = ไม่แตะ, ส่ง [] มา = ล้างความสัมพันธ์ทั้งหมด
+type GuideRequest struct {
+	GuideStatus        *string `json:"guide_status"`
+	MemberID           *uint   `json:"member_id"`
+	GuideApplicationID *uint   `json:"guide_application_id"`
+	LanguageIDs        *[]uint `json:"language_ids"`
+	GuideTypeIDs       *[]uint `json:"guide_type_ids"`
+	ServiceAreaIDs     *[]uint `json:"service_area_ids"`
+}
+
+// ---------- DTO ส่งกลับ (ไม่ส่ง password ของ member) ----------
+type GuideMemberResponse struct {
+	ID        uint   `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Tel       string `json:"phonenumber"`
+}
+
+type GuideRefResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type GuideServiceAreaResponse struct {
+	ID             uint   `json:"id"`
+	ProvinceID     uint   `json:"province_id"`
+	ProvinceNameTh string `json:"province_name_th"`
+	ProvinceNameEn string `json:"province_name_en"`
+	DistrictID     uint   `json:"district_id"`
+	DistrictNameTh string `json:"district_name_th"`
+	DistrictNameEn string `json:"district_name_en"`
+	GuideTypeID    uint   `json:"guide_type_id"`
+	Status         string `json:"status"`
+}
+
+type GuideResponse struct {
+	ID                 uint                       `json:"id"`
+	GuideStatus        string                     `json:"guide_status"`
+	MemberID           uint                       `json:"member_id"`
+	Member             *GuideMemberResponse       `json:"member,omitempty"`
+	GuideApplicationID *uint                      `json:"guide_application_id"`
+	Languages          []GuideRefResponse         `json:"languages"`
+	GuideTypes         []GuideRefResponse         `json:"guide_types"`
+	ServiceAreas       []GuideServiceAreaResponse `json:"service_areas"`
+}
+
+func toGuideResponse(g entity.Guide) GuideResponse {
+	res := GuideResponse{
+		ID:                 g.ID,
+		GuideStatus:        g.GuideStatus,
+		MemberID:           g.MemberID,
+		GuideApplicationID: g.GuideApplicationID,
+		Languages:          []GuideRefResponse{},
+		GuideTypes:         []GuideRefResponse{},
+		ServiceAreas:       []GuideServiceAreaResponse{},
+	}
+	if g.Member != nil {
+		res.Member = &GuideMemberResponse{
+			ID:        g.Member.ID,
+			Username:  g.Member.Username,
+			FirstName: g.Member.First_Name,
+			LastName:  g.Member.Last_Name,
+			Email:     g.Member.Email,
+			Tel:       g.Member.Tel,
+		}
+	}
+	for _, l := range g.Language {
+		res.Languages = append(res.Languages, GuideRefResponse{ID: l.ID, Name: l.Name})
+	}
+	for _, t := range g.GuideType {
+		res.GuideTypes = append(res.GuideTypes, GuideRefResponse{ID: t.ID, Name: t.Name})
+	}
+	for _, sa := range g.ServiceArea {
+		res.ServiceAreas = append(res.ServiceAreas, GuideServiceAreaResponse{
+			ID:             sa.ID,
+			ProvinceID:     sa.ProvinceID,
+			ProvinceNameTh: sa.Province.NameTh,
+			ProvinceNameEn: sa.Province.NameEn,
+			DistrictID:     sa.DistrictID,
+			DistrictNameTh: sa.District.NameTh,
+			DistrictNameEn: sa.District.NameEn,
+			GuideTypeID:    sa.GuideTypeID,
+			Status:         sa.Status,
+		})
+	}
+	return res
+}
+
+func toGuideResponses(guides []entity.Guide) []GuideResponse {
+	res := make([]GuideResponse, 0, len(guides))
+	for _, g := range guides {
+		res = append(res, toGuideResponse(g))
+	}
+	return res
+}
+
+// preloadGuide โหลด member + ความสัมพันธ์ many2many ทั้งหมดของไกด์
+func preloadGuide(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Member").
+		Preload("Language").
+		Preload("GuideType").
+		Preload("ServiceArea.Province").
+		Preload("ServiceArea.District")
+}
+
+// guideFilter คือ filter จาก query string ที่ใช้ร่วมกันระหว่าง FindGuide และ SuggestAvailableGuides
+type guideFilter struct {
+	Language    string
+	LanguageID  string
+	GuideType   string
+	GuideTypeID string
+	ProvinceID  string
+	DistrictID  string
+	Status      string
+}
+
+func guideFilterFromQuery(c *gin.Context) guideFilter {
+	return guideFilter{
+		Language:    strings.TrimSpace(c.Query("language")),
+		LanguageID:  c.Query("language_id"),
+		GuideType:   strings.TrimSpace(c.Query("guide_type")),
+		GuideTypeID: c.Query("guide_type_id"),
+		ProvinceID:  c.Query("province_id"),
+		DistrictID:  c.Query("district_id"),
+		Status:      strings.TrimSpace(c.Query("status")),
+	}
+}
+
+// apply กรองผ่านตารางกลาง guide_language / guide_type / guide_servicearea
+func (f guideFilter) apply(db, q *gorm.DB) *gorm.DB {
+	if f.Language != "" {
+		q = q.Where("guides.id IN (?)", db.Table("guide_language").
+			Select("guide_language.guide_id").
+			Joins("JOIN languages ON languages.id = guide_language.language_id").
+			Where("languages.deleted_at IS NULL AND LOWER(languages.name) = LOWER(?)", f.Language))
+	}
+	if f.LanguageID != "" {
+		q = q.Where("guides.id IN (?)", db.Table("guide_language").
+			Select("guide_id").Where("language_id = ?", f.LanguageID))
+	}
+	if f.GuideType != "" {
+		q = q.Where("guides.id IN (?)", db.Table("guide_type").
+			Select("guide_type.guide_id").
+			Joins("JOIN guide_types ON guide_types.id = guide_type.guide_type_id").
+			Where("guide_types.deleted_at IS NULL AND LOWER(guide_types.name) = LOWER(?)", f.GuideType))
+	}
+	if f.GuideTypeID != "" {
+		q = q.Where("guides.id IN (?)", db.Table("guide_type").
+			Select("guide_id").Where("guide_type_id = ?", f.GuideTypeID))
+	}
+	if f.ProvinceID != "" || f.DistrictID != "" {
+		sub := db.Table("guide_servicearea").
+			Select("guide_servicearea.guide_id").
+			Joins("JOIN service_areas ON service_areas.id = guide_servicearea.service_area_id").
+			Where("service_areas.deleted_at IS NULL")
+		if f.ProvinceID != "" {
+			sub = sub.Where("service_areas.province_id = ?", f.ProvinceID)
+		}
+		if f.DistrictID != "" {
+			sub = sub.Where("service_areas.district_id = ?", f.DistrictID)
+		}
+		q = q.Where("guides.id IN (?)", sub)
+	}
+	if f.Status != "" {
+		q = q.Where("guides.guide_status = ?", f.Status)
+	}
+	return q
+}
+
+// loadGuideRefs โหลด language/guide type/service area ตาม id ที่ส่งมา และเช็คว่ามีครบทุกตัว
+func loadGuideRefs(tx *gorm.DB, req GuideRequest) (langs []entity.Language, types []entity.GuideType, areas []entity.ServiceArea, errMsg string) {
+	if req.LanguageIDs != nil && len(*req.LanguageIDs) > 0 {
+		ids := uniqueIDs(*req.LanguageIDs)
+		if err := tx.Where("id IN ?", ids).Find(&langs).Error; err != nil || len(langs) != len(ids) {
+			return nil, nil, nil, "Language not found"
+		}
+	}
+	if req.GuideTypeIDs != nil && len(*req.GuideTypeIDs) > 0 {
+		ids := uniqueIDs(*req.GuideTypeIDs)
+		if err := tx.Where("id IN ?", ids).Find(&types).Error; err != nil || len(types) != len(ids) {
+			return nil, nil, nil, "GuideType not found"
+		}
+	}
+	if req.ServiceAreaIDs != nil && len(*req.ServiceAreaIDs) > 0 {
+		ids := uniqueIDs(*req.ServiceAreaIDs)
+		if err := tx.Where("id IN ?", ids).Find(&areas).Error; err != nil || len(areas) != len(ids) {
+			return nil, nil, nil, "ServiceArea not found"
+		}
+	}
+	return langs, types, areas, ""
+}
+
+// replaceGuideRefs เขียนตารางกลางเฉพาะ slice ที่ส่งมา
+func replaceGuideRefs(tx *gorm.DB, guide *entity.Guide, req GuideRequest, langs []entity.Language, types []entity.GuideType, areas []entity.ServiceArea) error {
+	if req.LanguageIDs != nil {
+		if err := tx.Model(guide).Association("Language").Replace(langs); err != nil {
+			return err
+		}
+	}
+	if req.GuideTypeIDs != nil {
+		if err := tx.Model(guide).Association("GuideType").Replace(types); err != nil {
+			return err
+		}
+	}
+	if req.ServiceAreaIDs != nil {
+		if err := tx.Model(guide).Association("ServiceArea").Replace(areas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+var guideList = listSpec{
+	table:   "guides",
+	sorts:   map[string]string{"status": "guide_status", "created_at": "created_at"},
+	filters: map[string]string{"member_id": "member_id"},
+}
+
+// GET /guide?language=Japanese&province_id=10&page=&page_size=&cursor=&sort=
 func FindGuide(c *gin.Context) {
+	db := dbFor(c)
 	var guides []entity.Guide
-	
-	if err := config.DB().Find(&guides).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	// ตัวกรองภาษา/ประเภท/พื้นที่ผ่านตารางกลาง ใช้ guideFilter เหมือน SuggestAvailableGuides
+	page, ok := guideList.find(c, guideFilterFromQuery(c).apply(db, db), &guides, preloadGuide)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": guides})
+	c.JSON(http.StatusOK, gin.H{"data": toGuideResponses(guides), "pagination": page})
 }
 
 // GET /guide/:id
 func FindGuideById(c *gin.Context) {
 	var guide entity.Guide
 	id := c.Param("id")
-	
-	if err := config.DB().Where("id = ?", id).First(&guide).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "guide not found"})
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": guide})
+	if err := preloadGuide(dbFor(c)).Where("id = ?", id).First(&guide).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("guide not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toGuideResponse(guide)})
 }
 
 // POST /guide
 func CreateGuide(c *gin.Context) {
-	var guide entity.Guide
+	var req GuideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	if req.MemberID == nil {
+		apierr.Respond(c, apierr.Required("member_id"))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var member entity.Member
+	if err := tx.First(&member, *req.MemberID).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("Member not found"))
+		return
+	}
+	var count int64
+	tx.Model(&entity.Guide{}).Where("member_id = ?", member.ID).Count(&count)
+	if count > 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Member is already a guide"))
+		return
+	}
+	if req.GuideApplicationID != nil {
+		var app entity.GuideApplication
+		if err := tx.First(&app, *req.GuideApplicationID).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("GuideApplication not found"))
+			return
+		}
+	}
+
+	langs, types, areas, errMsg := loadGuideRefs(tx, req)
+	if errMsg != "" {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest(errMsg))
+		return
+	}
 
-	if err := c.ShouldBindJSON(&guide); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+	guide := entity.Guide{
+		MemberID:           member.ID,
+		GuideApplicationID: req.GuideApplicationID,
+	}
+	if req.GuideStatus != nil {
+		guide.GuideStatus = strings.TrimSpace(*req.GuideStatus)
+	}
+
+	if err := tx.Omit("Language", "GuideType", "ServiceArea").Create(&guide).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create guide"))
+		return
+	}
+	if err := replaceGuideRefs(tx, &guide, req, langs, types, areas); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to link guide"))
 		return
 	}
 
-	if err := config.DB().Create(&guide).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guide: " + err.Error()})
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
 		return
 	}
 
+	preloadGuide(db).First(&guide, guide.ID)
 	c.JSON(http.StatusCreated, gin.H{
-		"data": guide, 
+		"data":    toGuideResponse(guide),
 		"message": "Guide created successfully",
 	})
 }
 
 // PUT /guide/:id
 func UpdateGuideById(c *gin.Context) {
-	var updateData entity.Guide
 	id := c.Param("id")
-	
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
 		return
 	}
 
-	var existingGuide entity.Guide
-	if err := config.DB().Where("id = ?", id).First(&existingGuide).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Guide not found"})
+	var req GuideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
-	if err := config.DB().Model(&existingGuide).Updates(&updateData).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update guide: " + err.Error()})
+	db := dbFor(c)
+	var guide entity.Guide
+	if err := db.Where("id = ?", id).First(&guide).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Guide not found"))
 		return
 	}
 
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	updates := map[string]any{}
+	if req.GuideStatus != nil {
+		updates["guide_status"] = strings.TrimSpace(*req.GuideStatus)
+	}
+	if req.MemberID != nil && *req.MemberID != guide.MemberID {
+		var member entity.Member
+		if err := tx.First(&member, *req.MemberID).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("Member not found"))
+			return
+		}
+		var count int64
+		tx.Model(&entity.Guide{}).Where("member_id = ? AND id <> ?", member.ID, guide.ID).Count(&count)
+		if count > 0 {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Conflict("Member is already a guide"))
+			return
+		}
+		updates["member_id"] = member.ID
+	}
+	if req.GuideApplicationID != nil {
+		var app entity.GuideApplication
+		if err := tx.First(&app, *req.GuideApplicationID).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("GuideApplication not found"))
+			return
+		}
+		updates["guide_application_id"] = app.ID
+	}
+
+	langs, types, areas, errMsg := loadGuideRefs(tx, req)
+	if errMsg != "" {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest(errMsg))
+		return
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(&guide).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to update guide"))
+			return
+		}
+	}
+	if err := replaceGuideRefs(tx, &guide, req, langs, types, areas); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to link guide"))
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	preloadGuide(db).First(&guide, guide.ID)
 	c.JSON(http.StatusOK, gin.H{
-		"data": existingGuide, 
+		"data":    toGuideResponse(guide),
 		"message": "Guide updated successfully",
 	})
 }
 
 // DELETE /guide/:id
+// ลบไม่ได้ (409) ถ้ายังมีแพ็คเกจที่ใช้ไกด์นี้ หรือมีค่าไกด์ค้างจ่ายที่ยังไม่เข้ารอบจ่าย
 func DeleteGuideById(c *gin.Context) {
 	var guide entity.Guide
 	id := c.Param("id")
-	
-	if err := config.DB().Where("id = ?", id).First(&guide).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "guide not found"})
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
 		return
 	}
-	
-	if err := config.DB().Delete(&guide, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete guide"})
+
+	db := dbFor(c)
+	if err := db.Where("id = ?", id).First(&guide).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("guide not found"))
 		return
 	}
 
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var packages int64
+	if err := tx.Model(&entity.Package{}).Where("guide_id = ?", guide.ID).Count(&packages).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if packages > 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Guide is assigned to packages").With("packages", packages))
+		return
+	}
+	// ยอดสุทธิแบบเดียวกับ CreatePayoutBatch (ค่าไกด์ที่กลับรายการแล้วหักกันเป็น 0)
+	var unpaid money.Money
+	if err := tx.Model(&entity.LedgerEntry{}).
+		Select("COALESCE(SUM(credit) - SUM(debit), 0)").
+		Where("account = ? AND guide_id = ? AND payout_batch_id IS NULL", entity.AccountGuidePayable, guide.ID).
+		Scan(&unpaid).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if unpaid > 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Guide has earnings not yet in a payout batch").With("unpaid", unpaid))
+		return
+	}
+
+	// ล้างตารางกลางและตารางงานก่อนลบ
+	if err := tx.Where("guide_id = ?", guide.ID).Delete(&entity.GuideAvailability{}).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "failed to delete guide availability"))
+		return
+	}
+	for _, assoc := range []string{"Language", "GuideType", "ServiceArea"} {
+		if err := tx.Model(&guide).Association(assoc).Clear(); err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "failed to unlink guide"))
+			return
+		}
+	}
+	if err := tx.Delete(&guide).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "failed to delete guide"))
+		return
+	}
+
+	tx.Commit()
 	c.JSON(http.StatusOK, gin.H{"message": "guide deleted successfully"})
-}
\ No newline at end of file
+}
@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// --------- DTO วันไม่ว่างของไกด์ (วันที่เป็น string "YYYY-MM-DD") ----------
+type GuideAvailabilityRequest struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Kind      string `json:"kind"` // blocked|leave
+	Note      string `json:"note"`
+}
+
+// guideConflict อธิบายสิ่งที่ชนกับช่วงวันที่ที่ขอ (แพ็คเกจอื่น หรือวันไม่ว่าง)
+type guideConflict struct {
+	Kind      string    `json:"kind"` // package|blocked|leave
+	ID        uint      `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// findGuideConflicts หาแพ็คเกจและวันไม่ว่างที่ทับช่วง [start, end] ของไกด์
+// excludePackageID ใช้ตอนแก้ไขแพ็คเกจ เพื่อไม่ให้ชนกับตัวเอง
+func findGuideConflicts(tx *gorm.DB, guideID uint, start, end time.Time, excludePackageID uint) ([]guideConflict, error) {
+	conflicts := []guideConflict{}
+
+	var packs []entity.Package
+	if err := tx.
+		Where("guide_id = ? AND id <> ?", guideID, excludePackageID).
+		Where("start_date <= ? AND final_date >= ?", end, start).
+		Find(&packs).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range packs {
+		conflicts = append(conflicts, guideConflict{
+			Kind: "package", ID: p.ID, Name: p.Name, StartDate: p.StartDate, EndDate: p.FinalDate,
+		})
+	}
+
+	var blocks []entity.GuideAvailability
+	if err := tx.
+		Where("guide_id = ?", guideID).
+		Where("start_date <= ? AND end_date >= ?", end, start).
+		Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		conflicts = append(conflicts, guideConflict{
+			Kind: b.Kind, ID: b.ID, Name: b.Note, StartDate: b.StartDate, EndDate: b.EndDate,
+		})
+	}
+	return conflicts, nil
+}
+
+// checkGuideSchedule ตรวจช่วงวันที่ของแพ็คเกจกับตารางงานไกด์
+// คืนค่า false เมื่อเขียน response ไปแล้ว (ผู้เรียกต้อง rollback แล้ว return)
+func checkGuideSchedule(c *gin.Context, tx *gorm.DB, guideID *uint, start, end time.Time, excludePackageID uint) bool {
+	if guideID == nil || start.IsZero() || end.IsZero() {
+		return true
+	}
+	if end.Before(start) {
+		apierr.Respond(c, apierr.BadRequest("final_date must not be before start_date"))
+		return false
+	}
+	conflicts, err := findGuideConflicts(tx, *guideID, start, end, excludePackageID)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return false
+	}
+	if len(conflicts) > 0 {
+		apierr.Respond(c, apierr.Conflict("Guide is not available for the selected dates").With("conflicts", conflicts))
+		return false
+	}
+	return true
+}
+
+// GET /guide/:id/availability?from=YYYY-MM-DD&to=YYYY-MM-DD
+func FindGuideAvailability(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
+		return
+	}
+
+	db := dbFor(c)
+	var guide entity.Guide
+	if err := db.First(&guide, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("guide not found"))
+		return
+	}
+
+	from, err := parseYMD(c.Query("from"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("from must be YYYY-MM-DD"))
+		return
+	}
+	to, err := parseYMD(c.Query("to"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("to must be YYYY-MM-DD"))
+		return
+	}
+
+	blockQ := db.Where("guide_id = ?", guide.ID).Order("start_date")
+	packQ := db.Where("guide_id = ?", guide.ID).Order("start_date")
+	if !from.IsZero() {
+		blockQ = blockQ.Where("end_date >= ?", from)
+		packQ = packQ.Where("final_date >= ?", from)
+	}
+	if !to.IsZero() {
+		blockQ = blockQ.Where("start_date <= ?", to)
+		packQ = packQ.Where("start_date <= ?", to)
+	}
+
+	var blocks []entity.GuideAvailability
+	if err := blockQ.Find(&blocks).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	var packs []entity.Package
+	if err := packQ.Find(&packs).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"guide_id":    guide.ID,
+		"unavailable": blocks,
+		"packages":    packs,
+	}})
+}
+
+// POST /guide/:id/availability
+func CreateGuideAvailability(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
+		return
+	}
+
+	var req GuideAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	start, err := parseYMD(req.StartDate)
+	if err != nil || start.IsZero() {
+		apierr.Respond(c, apierr.BadRequest("start_date must be YYYY-MM-DD"))
+		return
+	}
+	end, err := parseYMD(req.EndDate)
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("end_date must be YYYY-MM-DD"))
+		return
+	}
+	if end.IsZero() {
+		end = start
+	}
+	if end.Before(start) {
+		apierr.Respond(c, apierr.BadRequest("end_date must not be before start_date"))
+		return
+	}
+
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	if kind == "" {
+		kind = "blocked"
+	}
+	if kind != "blocked" && kind != "leave" {
+		apierr.Respond(c, apierr.BadRequest("kind must be blocked or leave"))
+		return
+	}
+
+	db := dbFor(c)
+	var guide entity.Guide
+	if err := db.First(&guide, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("guide not found"))
+		return
+	}
+
+	// ห้ามบล็อกวันที่ไกด์มีแพ็คเกจอยู่แล้ว ต้องย้ายแพ็คเกจก่อน
+	var packs []entity.Package
+	if err := db.
+		Where("guide_id = ?", guide.ID).
+		Where("start_date <= ? AND final_date >= ?", end, start).
+		Find(&packs).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if len(packs) > 0 {
+		apierr.Respond(c, apierr.Conflict("Guide is assigned to packages in this date range").With("packages", packs))
+		return
+	}
+
+	block := entity.GuideAvailability{
+		GuideID:   guide.ID,
+		StartDate: start,
+		EndDate:   end,
+		Kind:      kind,
+		Note:      strings.TrimSpace(req.Note),
+	}
+	if err := db.Create(&block).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create availability"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": block, "message": "Availability created successfully"})
+}
+
+// DELETE /guide/:id/availability/:availability_id
+func DeleteGuideAvailability(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
+		return
+	}
+	availabilityID := c.Param("availability_id")
+	if _, err := strconv.Atoi(availabilityID); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid availability ID format"))
+		return
+	}
+
+	var block entity.GuideAvailability
+	if err := dbFor(c).
+		Where("id = ? AND guide_id = ?", availabilityID, id).
+		First(&block).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("availability not found"))
+		return
+	}
+
+	if err := dbFor(c).Delete(&block).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "failed to delete availability"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "availability deleted successfully"})
+}
+
+// GET /guide/available?start_date=&final_date=&province_id=&language=&guide_type_id=
+// หรือส่ง package_id เพื่อใช้วันที่และจังหวัดของแพ็คเกจนั้น (filter อื่นเหมือน GET /guide)
+func SuggestAvailableGuides(c *gin.Context) {
+	db := dbFor(c)
+
+	var (
+		start, end       time.Time
+		provinceID       string
+		excludePackageID uint
+	)
+
+	if packageID := c.Query("package_id"); packageID != "" {
+		var pack entity.Package
+		if err := db.First(&pack, packageID).Error; err != nil {
+			apierr.Respond(c, apierr.NotFound("Package not found"))
+			return
+		}
+		start, end = pack.StartDate, pack.FinalDate
+		if pack.ProvinceID != nil {
+			provinceID = strconv.FormatUint(uint64(*pack.ProvinceID), 10)
+		}
+		excludePackageID = pack.ID
+	}
+
+	if s := c.Query("start_date"); s != "" {
+		t, err := parseYMD(s)
+		if err != nil {
+			apierr.Respond(c, apierr.BadRequest("start_date must be YYYY-MM-DD"))
+			return
+		}
+		start = t
+	}
+	if s := c.Query("final_date"); s != "" {
+		t, err := parseYMD(s)
+		if err != nil {
+			apierr.Respond(c, apierr.BadRequest("final_date must be YYYY-MM-DD"))
+			return
+		}
+		end = t
+	}
+	if p := c.Query("province_id"); p != "" {
+		provinceID = p
+	}
+
+	if start.IsZero() || end.IsZero() {
+		apierr.Respond(c, apierr.BadRequest("start_date and final_date (or package_id) are required"))
+		return
+	}
+	if end.Before(start) {
+		apierr.Respond(c, apierr.BadRequest("final_date must not be before start_date"))
+		return
+	}
+
+	filter := guideFilterFromQuery(c)
+	filter.ProvinceID = provinceID
+
+	q := preloadGuide(db).
+		Where("guides.id NOT IN (?)", db.Model(&entity.Package{}).
+			Select("guide_id").
+			Where("guide_id IS NOT NULL AND id <> ?", excludePackageID).
+			Where("start_date <= ? AND final_date >= ?", end, start)).
+		Where("guides.id NOT IN (?)", db.Model(&entity.GuideAvailability{}).
+			Select("guide_id").
+			Where("start_date <= ? AND end_date >= ?", end, start))
+	q = filter.apply(db, q)
+
+	var guides []entity.Guide
+	if err := q.Find(&guides).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": toGuideResponses(guides), "count": len(guides)})
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/logging"
+)
+
+// readyTimeout จำกัดเวลาตรวจแต่ละอย่าง (SQLite มี connection เดียว ถ้ามี transaction ยาวค้างอยู่จะรอจนหมดเวลา)
+const readyTimeout = 2 * time.Second
+
+// GET /livez - โปรเซสยังตอบได้ (ไม่แตะฐานข้อมูล ใช้ตัดสินว่าต้อง restart หรือไม่)
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GET /readyz - พร้อมรับ request: ฐานข้อมูลตอบ และเขียนไฟล์ลง uploads ได้ (ดิสก์ไม่เต็ม/สิทธิ์ถูกต้อง)
+// /health เดิมใช้ handler นี้
+func Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	checks := gin.H{}
+	ready := true
+	for _, check := range []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"database", pingDatabase},
+		{"uploads", checkUploadsWritable},
+	} {
+		if err := check.run(ctx); err != nil {
+			ready = false
+			checks[check.name] = "failed"
+			logging.FromContext(ctx).WarnContext(ctx, "readiness check failed", "check", check.name, "error", err)
+			continue
+		}
+		checks[check.name] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
+
+func pingDatabase(ctx context.Context) error {
+	db := config.DB()
+	if db == nil {
+		return errors.New("database not connected")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return err
+	}
+	// Ping ไม่ได้รันคำสั่งจริง ถ้าไฟล์เสียหรือถูกล็อกจะรู้ตอน query
+	return db.WithContext(ctx).Exec("SELECT 1").Error
+}
+
+// checkUploadsWritable เขียนไฟล์ชั่วคราวลง uploads แล้วลบทิ้ง
+func checkUploadsWritable(context.Context) error {
+	if err := os.MkdirAll("./uploads", 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("./uploads", ".readyz-*")
+	if err != nil {
+		return err
+	}
+	_, werr := f.Write([]byte("ok"))
+	cerr := f.Close()
+	os.Remove(f.Name())
+	return errors.Join(werr, cerr)
+}
@@ -1,17 +1,26 @@
 package controller
 
 import (
+	"bufio"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"mime/multipart"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/dataset"
 	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/jobs"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"github.com/kookkikiv/sa_project/backend/tracing"
 	"gorm.io/gorm"
 )
 
@@ -40,192 +49,610 @@ type ThaiSubdistrict struct {
 	SubdistrictCode   int    `json:"subdistrictCode"`
 	SubdistrictNameTh string `json:"subdistrictNameTh"`
 	SubdistrictNameEn string `json:"subdistrictNameEn"`
-	PostalCode        any `json:"postalCode"` // บางชุดข้อมูลเป็น number ก็ครอบด้วย string ได้
+	PostalCode        any    `json:"postalCode"` // บางชุดข้อมูลเป็น number ก็ครอบด้วย string ได้
+}
+
+// URL ของ thailand-geography-json (ใช้เมื่อ ?source=remote)
+const (
+	provincesURL    = "https://raw.githubusercontent.com/thailand-geography-data/thailand-geography-json/main/src/provinces.json"
+	districtsURL    = "https://raw.githubusercontent.com/thailand-geography-data/thailand-geography-json/main/src/districts.json"
+	subdistrictsURL = "https://raw.githubusercontent.com/thailand-geography-data/thailand-geography-json/main/src/subdistricts.json"
+)
+
+// geographyBatchSize คือจำนวนแถวต่อ INSERT ตอนนำเข้า
+const geographyBatchSize = 500
+
+// maxGeographyDiffItems จำกัดจำนวนรายการใน added/changed/removed ที่ตอบกลับ (summary นับครบทุกรายการ)
+const maxGeographyDiffItems = 200
+
+// geographyDataset คือข้อมูลจังหวัด/อำเภอ/ตำบลชุดเต็ม
+// ไฟล์รวม: {"provinces": [...], "districts": [...], "subdistricts": [...]} รูปแบบแถวเดียวกับ thailand-geography-json
+type geographyDataset struct {
+	Provinces    []ThaiProvince    `json:"provinces"`
+	Districts    []ThaiDistrict    `json:"districts"`
+	Subdistricts []ThaiSubdistrict `json:"subdistricts"`
+}
+
+// geographyRow คือแถวที่แปลงรหัสเป็นสตริงแล้ว Parent = รหัสจังหวัดของอำเภอ / รหัสอำเภอของตำบล
+type geographyRow struct {
+	Code   string
+	Parent string
+	NameTh string
+	NameEn string
+	Zip    string
+}
+
+// geographyLevel คือตารางของแต่ละระดับ เรียงจากแม่ไปลูก
+type geographyLevel struct {
+	name        string
+	table       string
+	codeColumn  string
+	parentTable string // ตารางแม่ ("" = ไม่มี)
+	parentFK    string
+	parentCode  string
+	dependents  []string // ตารางอื่นที่อ้างระดับนี้ผ่านคอลัมน์ <name>_id
+}
+
+var geographyLevels = []geographyLevel{
+	{name: entity.PlaceProvince, table: "provinces", codeColumn: "province_code",
+		dependents: []string{"accommodations", "packages", "events", "locations", "service_areas"}},
+	{name: entity.PlaceDistrict, table: "districts", codeColumn: "district_code", parentTable: "provinces", parentFK: "province_id", parentCode: "province_code",
+		dependents: []string{"accommodations", "packages", "events", "service_areas"}},
+	{name: entity.PlaceSubdistrict, table: "subdistricts", codeColumn: "subdistrict_code", parentTable: "districts", parentFK: "district_id", parentCode: "district_code",
+		dependents: []string{"accommodations", "packages", "events"}},
+}
+
+type geographyCounts struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Retired   int `json:"retired"`
+	Restored  int `json:"restored"`
+	Unchanged int `json:"unchanged"`
+}
+
+// geographyDiffItem คือรายการที่เพิ่ม/เปลี่ยน/เลิกใช้/กลับมาใช้ Changes = ฟิลด์ -> [ค่าเดิม, ค่าใหม่]
+// Dependents = ตาราง -> ID ของแถวที่อ้างรายการนี้ (เฉพาะรายการเดิมที่เปลี่ยน/เลิกใช้)
+type geographyDiffItem struct {
+	Level      string               `json:"level"`
+	Code       string               `json:"code"`
+	NameTh     string               `json:"name_th"`
+	Action     string               `json:"action"`
+	Changes    map[string][2]string `json:"changes,omitempty"`
+	Dependents map[string][]uint    `json:"dependents,omitempty"`
+
+	id uint
+}
+
+// geographyDiff คือผลเทียบชุดข้อมูลกับฐานข้อมูล
+// retired คือรายการที่มีในฐานข้อมูลแต่ไม่มีในชุดข้อมูล: ตั้ง retired_at ไม่ลบ (ID เดิมที่ถูกอ้างยังใช้ได้)
+type geographyDiff struct {
+	Summary    map[string]*geographyCounts `json:"summary"`
+	Added      []*geographyDiffItem        `json:"added"`
+	Changed    []*geographyDiffItem        `json:"changed"` // รวม restored
+	Retired    []*geographyDiffItem        `json:"retired"`
+	Dependents map[string]int              `json:"dependents"` // ตาราง -> จำนวนแถวที่อ้างรายการที่เปลี่ยน/เลิกใช้
+	Truncated  bool                        `json:"truncated"`  // มีรายการเกิน maxGeographyDiffItems
+
+	all []*geographyDiffItem // ทุกรายการ (บันทึกลง GeographyChange)
+}
+
+func newGeographyDiff() *geographyDiff {
+	d := &geographyDiff{
+		Summary:    map[string]*geographyCounts{},
+		Added:      []*geographyDiffItem{},
+		Changed:    []*geographyDiffItem{},
+		Retired:    []*geographyDiffItem{},
+		Dependents: map[string]int{},
+	}
+	for _, l := range geographyLevels {
+		d.Summary[l.name] = &geographyCounts{}
+	}
+	return d
+}
+
+func (d *geographyDiff) record(list *[]*geographyDiffItem, item *geographyDiffItem) {
+	d.all = append(d.all, item)
+	if len(*list) < maxGeographyDiffItems {
+		*list = append(*list, item)
+	} else {
+		d.Truncated = true
+	}
+}
+
+var errGeographyDryRun = errors.New("dry run")
+
+// ชนิดงานเบื้องหลังของข้อมูลภูมิศาสตร์
+const (
+	jobGeographyImport = "geography_import"
+	jobGeographyClear  = "geography_clear"
+)
+
+// geographyImportPayload คืองานนำเข้า Dataset มีค่าเมื่ออัปโหลดไฟล์ (ไม่งั้นงานโหลดจาก Source เอง)
+// Version ว่าง = ใช้วันที่มีผล (เติม .2, .3 ถ้าซ้ำ)
+type geographyImportPayload struct {
+	Source        string            `json:"source"`
+	DryRun        bool              `json:"dry_run"`
+	Version       string            `json:"version,omitempty"`
+	EffectiveDate string            `json:"effective_date"`
+	Note          string            `json:"note,omitempty"`
+	Dataset       *geographyDataset `json:"dataset,omitempty"`
 }
 
 // ------------------------------
-// ENDPOINT: POST /import-thailand-all
+// ENDPOINT: POST /thailand/import-all?source=embedded|remote&dry_run=true&version=&effective_date=YYYY-MM-DD&note=
 // ------------------------------
+// แหล่งข้อมูล: ไฟล์อัปโหลด (multipart "file" = ไฟล์รวม .json/.json.gz หรือ "provinces"/"districts"/"subdistricts" แยกไฟล์)
+// ถ้าไม่อัปโหลดใช้ชุดที่ฝังมากับโปรแกรม (ค่าเริ่มต้น ไม่ต้องต่อเน็ต) หรือ ?source=remote ดึงจาก GitHub
+// ตอบ 202 พร้อม job แล้วนำเข้าเบื้องหลัง ดูผล (diff + แถวที่อ้างรายการที่เปลี่ยน) ที่ GET /jobs/:id
+// ไฟล์อัปโหลดถูกตรวจก่อนเข้าคิว ถ้าข้อมูลผิดตอบ 400 ทันที
+// effective_date ในอนาคต = ตั้งเวลานำเข้าไว้ถึงวันนั้น (dry run ทำทันที) ผลที่บันทึกแล้วดูที่ GET /thailand/versions
 func ImportThailandAll(c *gin.Context) {
-	db := config.DB()
-
-	log.Println("🚀 Start import Thailand geography (provinces, districts, subdistricts)")
-
-	const provincesURL = "https://raw.githubusercontent.com/thailand-geography-data/thailand-geography-json/main/src/provinces.json"
-	const districtsURL = "https://raw.githubusercontent.com/thailand-geography-data/thailand-geography-json/main/src/districts.json"
-	const subdistrictsURL = "https://raw.githubusercontent.com/thailand-geography-data/thailand-geography-json/main/src/subdistricts.json"
+	payload := geographyImportPayload{
+		DryRun:  c.Query("dry_run") == "true",
+		Version: strings.TrimSpace(c.Query("version")),
+		Note:    c.Query("note"),
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	effective, err := parseYMD(c.Query("effective_date"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("effective_date must be YYYY-MM-DD"))
+		return
+	}
+	if effective.IsZero() {
+		effective = today
+	}
+	payload.EffectiveDate = effective.Format("2006-01-02")
+	if payload.Version != "" {
+		var n int64
+		dbFor(c).Model(&entity.GeographyVersion{}).Where("version = ?", payload.Version).Count(&n)
+		if n > 0 {
+			apierr.Respond(c, apierr.Conflict("Geography version "+payload.Version+" already exists"))
+			return
+		}
+	}
 
-	// 1) Provinces
-	log.Println("📌 Fetching provinces...")
-	provinces, err := fetchProvinces(provincesURL)
+	ds, uploaded, err := readGeographyUpload(c)
+	if errors.Is(err, errGeographyUploadParts) {
+		apierr.Respond(c, apierr.Invalid("Failed to read geography dataset", apierr.FieldError{
+			Field: "file", Code: "required", Message: errGeographyUploadParts.Error(),
+		}))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch provinces: " + err.Error()})
+		apierr.Respond(c, apierr.BadRequest("Failed to read geography dataset: files must be JSON (optionally gzipped)").WithCause(err))
 		return
 	}
+	if uploaded {
+		if _, problems := ds.normalize(); len(problems) > 0 {
+			if len(problems) > 50 {
+				problems = problems[:50]
+			}
+			apierr.Respond(c, apierr.BadRequest("Invalid geography dataset").With("details", problems))
+			return
+		}
+		payload.Source, payload.Dataset = "upload", &ds
+	} else {
+		payload.Source = c.DefaultQuery("source", "embedded")
+		if payload.Source != "embedded" && payload.Source != "remote" {
+			apierr.Respond(c, apierr.BadRequest("Failed to read geography dataset: source must be embedded or remote"))
+			return
+		}
+	}
+	if !payload.DryRun && effective.After(today) {
+		enqueueJobAt(c, jobGeographyImport, payload, effective)
+		return
+	}
+	enqueueJob(c, jobGeographyImport, payload)
+}
 
-	provinceCodeToID := make(map[int]uint) // key=int provinceCode จาก JSON -> value=uint PK ใน DB
-	importedProvinces := 0
+// runGeographyImport คืองาน geography_import นำเข้าใน transaction เดียวแบบ batch
+// ถ้า dry_run จะคำนวณ diff แล้ว rollback ไม่บันทึกอะไร
+func runGeographyImport(ctx context.Context, t *jobs.Task) (any, error) {
+	var payload geographyImportPayload
+	if err := t.Decode(&payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	var ds geographyDataset
+	if payload.Dataset != nil {
+		ds = *payload.Dataset
+	} else {
+		t.Progress(ctx, 0, 1, "Loading "+payload.Source+" dataset")
+		var err error
+		if ds, err = loadGeographySource(ctx, payload.Source); err != nil {
+			return nil, jobs.Public("Failed to load geography dataset", err)
+		}
+	}
+	rows, problems := ds.normalize()
+	if len(problems) > 0 {
+		if len(problems) > 50 {
+			problems = problems[:50]
+		}
+		return nil, jobs.Fail("invalid geography dataset: %s", strings.Join(problems, "; "))
+	}
+	total := 0
+	for _, r := range rows {
+		total += len(r)
+	}
 
-	for _, p := range provinces {
-		pCode := fmt.Sprintf("%02d", p.ProvinceCode) // zero-pad 2 หลัก
-		var existing entity.Province
-		err := db.Where("province_code = ?", pCode).First(&existing).Error
+	effective, err := parseYMD(payload.EffectiveDate)
+	if err != nil || effective.IsZero() {
+		return nil, jobs.Fail("effective_date must be YYYY-MM-DD")
+	}
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			newProvince := entity.Province{
-				ProvinceCode: pCode,
-				NameTh:       p.ProvinceNameTh,
-				NameEn:       p.ProvinceNameEn,
-			}
-			if err := db.Create(&newProvince).Error; err != nil {
-				log.Printf("❌ create province %s (%s): %v", p.ProvinceNameTh, pCode, err)
-				continue
+	log := logging.FromContext(ctx)
+	log.Info("geography import started", "source", payload.Source, "provinces", len(ds.Provinces),
+		"districts", len(ds.Districts), "subdistricts", len(ds.Subdistricts), "dry_run", payload.DryRun)
+	db := config.DB().WithContext(ctx) // query อยู่ใต้ span ของงาน และ log มี job_id
+	var diff *geographyDiff
+	var version *entity.GeographyVersion
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		diff, err = applyGeography(tx, rows, effective, func(done int, level string) error {
+			return t.Progress(ctx, done, total, "Importing "+level)
+		})
+		if err != nil {
+			return err
+		}
+		if err := findGeographyDependents(tx, diff); err != nil {
+			return err
+		}
+		if payload.DryRun {
+			return errGeographyDryRun
+		}
+		jobID := t.ID
+		version, err = recordGeographyVersion(tx, payload, geographyChecksum(rows), diff, &jobID)
+		return err
+	})
+	if err != nil && err != errGeographyDryRun {
+		return nil, fmt.Errorf("import failed, nothing was saved: %w", err)
+	}
+
+	message := "🔍 Dry run: nothing was saved"
+	if !payload.DryRun {
+		message = "✅ Thailand geography version " + version.Version + " imported"
+		resetSearchLexicon()
+		changed := 0
+		for _, n := range diff.Summary {
+			changed += n.Changed + n.Restored
+		}
+		if changed > 0 {
+			// ชื่อพื้นที่เปลี่ยน ข้อความค้นหาของแพ็คเกจ/ที่พัก/อีเวนต์ต้องสร้างใหม่
+			t.Progress(ctx, total, total, "Rebuilding search index")
+			if _, err := RebuildSearchIndex(db); err != nil {
+				log.Error("search index: rebuild after geography import failed", "error", err)
 			}
-			provinceCodeToID[p.ProvinceCode] = newProvince.ID
-			importedProvinces++
-		} else if err != nil {
-			log.Printf("❌ query province %s: %v", p.ProvinceNameTh, err)
-			continue
-		} else {
-			provinceCodeToID[p.ProvinceCode] = existing.ID
 		}
 	}
-	log.Printf("🏁 Provinces: %d new, %d total in file", importedProvinces, len(provinces))
+	for _, l := range geographyLevels {
+		n := diff.Summary[l.name]
+		log.Info("geography import finished", "table", l.table, "added", n.Added, "changed", n.Changed,
+			"retired", n.Retired, "restored", n.Restored, "unchanged", n.Unchanged, "dry_run", payload.DryRun)
+	}
+	t.Progress(ctx, total, total, message)
+
+	return gin.H{
+		"message":        message,
+		"summary":        diff.Summary,
+		"source":         payload.Source,
+		"dry_run":        payload.DryRun,
+		"effective_date": payload.EffectiveDate,
+		"version":        version,
+		"diff":           diff,
+	}, nil
+}
 
-	// 2) Districts
-	log.Println("📌 Fetching districts...")
-	districts, err := fetchDistricts(districtsURL)
+// errGeographyUploadParts คืออัปโหลดไม่ครบชุด
+var errGeographyUploadParts = errors.New(`upload "file" (combined dataset) or all of "provinces", "districts" and "subdistricts"`)
+
+// readGeographyUpload อ่านชุดข้อมูลจากไฟล์อัปโหลด คืน false ถ้าไม่ได้อัปโหลด
+func readGeographyUpload(c *gin.Context) (geographyDataset, bool, error) {
+	var ds geographyDataset
+	form, err := c.MultipartForm()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch districts: " + err.Error()})
-		return
+		return ds, false, nil
+	}
+	if files := form.File["file"]; len(files) > 0 {
+		b, err := readUploadedJSON(files[0])
+		if err != nil {
+			return ds, true, err
+		}
+		if err := json.Unmarshal(b, &ds); err != nil {
+			return ds, true, fmt.Errorf("%s: %w", files[0].Filename, err)
+		}
+		return ds, true, nil
+	}
+	parts := []struct {
+		field string
+		into  any
+	}{
+		{"provinces", &ds.Provinces},
+		{"districts", &ds.Districts},
+		{"subdistricts", &ds.Subdistricts},
 	}
+	for _, p := range parts {
+		files := form.File[p.field]
+		if len(files) == 0 {
+			return ds, true, errGeographyUploadParts
+		}
+		b, err := readUploadedJSON(files[0])
+		if err != nil {
+			return ds, true, err
+		}
+		if err := json.Unmarshal(b, p.into); err != nil {
+			return ds, true, fmt.Errorf("%s: %w", files[0].Filename, err)
+		}
+	}
+	return ds, true, nil
+}
 
-	districtCodeToID := make(map[int]uint) // key=int districtCode -> value=uint PK
-	importedDistricts := 0
+// loadGeographySource อ่านชุดที่ฝังมากับโปรแกรม (embedded) หรือดึงจาก GitHub (remote)
+func loadGeographySource(ctx context.Context, source string) (geographyDataset, error) {
+	var ds geographyDataset
+	switch source {
+	case "embedded":
+		b, err := dataset.ThailandGeography()
+		if err != nil {
+			return ds, err
+		}
+		return ds, json.Unmarshal(b, &ds)
+	case "remote":
+		var err error
+		if ds.Provinces, err = fetchProvinces(ctx, provincesURL); err != nil {
+			return ds, fmt.Errorf("fetch provinces: %w", err)
+		}
+		if ds.Districts, err = fetchDistricts(ctx, districtsURL); err != nil {
+			return ds, fmt.Errorf("fetch districts: %w", err)
+		}
+		if ds.Subdistricts, err = fetchSubdistricts(ctx, subdistrictsURL); err != nil {
+			return ds, fmt.Errorf("fetch subdistricts: %w", err)
+		}
+		return ds, nil
+	default:
+		return ds, jobs.Fail("source must be embedded or remote")
+	}
+}
+
+// readUploadedJSON อ่านไฟล์ที่อัปโหลด คลาย gzip ให้ถ้าเป็นไฟล์ .gz
+func readUploadedJSON(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return io.ReadAll(r)
+}
+
+// normalize แปลงรหัสเป็นสตริงตามที่เก็บในฐานข้อมูล แล้วตรวจรหัสซ้ำ/ชื่อว่าง/อ้างแม่ที่ไม่มีในชุดข้อมูล
+func (ds geographyDataset) normalize() (map[string][]geographyRow, []string) {
+	rows := map[string][]geographyRow{}
+	var problems []string
+	seen := map[string]bool{}
+	add := func(level string, r geographyRow, valid bool, parentLevel string) {
+		switch {
+		case !valid:
+			problems = append(problems, fmt.Sprintf("%s %s: invalid code", level, r.Code))
+		case strings.TrimSpace(r.NameTh) == "":
+			problems = append(problems, fmt.Sprintf("%s %s: missing Thai name", level, r.Code))
+		case seen[level+":"+r.Code]:
+			problems = append(problems, fmt.Sprintf("%s %s: duplicate code", level, r.Code))
+		case parentLevel != "" && !seen[parentLevel+":"+r.Parent]:
+			problems = append(problems, fmt.Sprintf("%s %s: unknown %s %s", level, r.Code, parentLevel, r.Parent))
+		default:
+			seen[level+":"+r.Code] = true
+			r.NameTh, r.NameEn = strings.TrimSpace(r.NameTh), strings.TrimSpace(r.NameEn)
+			rows[level] = append(rows[level], r)
+		}
+	}
+	for _, p := range ds.Provinces {
+		add(entity.PlaceProvince, geographyRow{
+			Code: fmt.Sprintf("%02d", p.ProvinceCode), NameTh: p.ProvinceNameTh, NameEn: p.ProvinceNameEn,
+		}, p.ProvinceCode >= 10 && p.ProvinceCode <= 99, "")
+	}
+	for _, d := range ds.Districts {
+		add(entity.PlaceDistrict, geographyRow{
+			Code: fmt.Sprintf("%04d", d.DistrictCode), Parent: fmt.Sprintf("%02d", d.ProvinceCode),
+			NameTh: d.DistrictNameTh, NameEn: d.DistrictNameEn,
+		}, d.DistrictCode >= 1000 && d.DistrictCode <= 9999, entity.PlaceProvince)
+	}
+	for _, s := range ds.Subdistricts {
+		add(entity.PlaceSubdistrict, geographyRow{
+			Code: fmt.Sprintf("%06d", s.SubdistrictCode), Parent: fmt.Sprintf("%04d", s.DistrictCode),
+			NameTh: s.SubdistrictNameTh, NameEn: s.SubdistrictNameEn, Zip: toPostalString(s.PostalCode),
+		}, s.SubdistrictCode >= 100000 && s.SubdistrictCode <= 999999, entity.PlaceDistrict)
+	}
+	if len(rows[entity.PlaceProvince]) == 0 && len(problems) == 0 {
+		problems = append(problems, "dataset has no provinces")
+	}
+	return rows, problems
+}
 
-	for i, d := range districts {
-		// province FK by provinceCode
-		pID, ok := provinceCodeToID[d.ProvinceCode]
-		if !ok {
-			log.Printf("⚠️ provinceCode %d not mapped for district %s", d.ProvinceCode, d.DistrictNameTh)
-			continue
+// applyGeography เพิ่ม/แก้/เลิกใช้ทีละระดับ (จังหวัด -> อำเภอ -> ตำบล) ใน tx แล้วคืน diff
+// ID ของแถวเดิมไม่เปลี่ยน รายการที่ไม่มีในชุดข้อมูลถูกตั้ง retired_at = effective (ไม่ลบ)
+// รายการที่เคยเลิกใช้หรือถูก soft delete แล้วกลับมาในชุดข้อมูลจะถูกกู้คืน
+// progress ถูกเรียกทุก geographyBatchSize แถวด้วยจำนวนแถวที่ทำแล้ว ถ้าคืน error จะหยุด (งานถูกยกเลิก)
+func applyGeography(tx *gorm.DB, rows map[string][]geographyRow, effective time.Time, progress func(done int, level string) error) (*geographyDiff, error) {
+	diff := newGeographyDiff()
+	now := time.Now()
+	done := 0
+	parentIDs := map[string]uint{}
+	for _, level := range geographyLevels {
+		var existing []struct {
+			ID      uint
+			Code    string
+			Parent  string
+			NameTh  string
+			NameEn  string
+			Zip     string
+			Deleted bool
+			Retired bool
+		}
+		sql := "SELECT t.id, t." + level.codeColumn + " AS code, t.name_th, t.name_en," +
+			" t.deleted_at IS NOT NULL AS deleted, t.retired_at IS NOT NULL AS retired"
+		if level.parentTable != "" {
+			sql += ", COALESCE(p." + level.parentCode + ", '') AS parent"
+		}
+		if level.name == entity.PlaceSubdistrict {
+			sql += ", COALESCE(t.zip_code, '') AS zip"
+		}
+		sql += " FROM " + level.table + " t"
+		if level.parentTable != "" {
+			sql += " LEFT JOIN " + level.parentTable + " p ON p.id = t." + level.parentFK
+		}
+		if err := tx.Raw(sql).Scan(&existing).Error; err != nil {
+			return nil, err
+		}
+		byCode := make(map[string]int, len(existing))
+		for i, e := range existing {
+			byCode[e.Code] = i
 		}
-		dCode := fmt.Sprintf("%04d", d.DistrictCode) // zero-pad 4 หลัก
 
-		var existing entity.District
-		err := db.Where("district_code = ?", dCode).First(&existing).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			newDistrict := entity.District{
-				DistrictCode: dCode,
-				NameTh:       d.DistrictNameTh,
-				NameEn:       d.DistrictNameEn,
-				ProvinceID:   pID,
+		counts := diff.Summary[level.name]
+		ids := map[string]uint{}
+		inDataset := map[string]bool{}
+		var provinces []entity.Province
+		var districts []entity.District
+		var subdistricts []entity.Subdistrict
+		for _, r := range rows[level.name] {
+			if done++; done%geographyBatchSize == 0 {
+				if err := progress(done, level.table); err != nil {
+					return nil, err
+				}
 			}
-			if err := db.Create(&newDistrict).Error; err != nil {
-				log.Printf("❌ create district %s (%s): %v", d.DistrictNameTh, dCode, err)
+			inDataset[r.Code] = true
+			i, ok := byCode[r.Code]
+			if !ok {
+				switch level.name {
+				case entity.PlaceProvince:
+					provinces = append(provinces, entity.Province{ProvinceCode: r.Code, NameTh: r.NameTh, NameEn: r.NameEn, ValidFrom: &effective})
+				case entity.PlaceDistrict:
+					districts = append(districts, entity.District{DistrictCode: r.Code, NameTh: r.NameTh, NameEn: r.NameEn, ProvinceID: parentIDs[r.Parent], ValidFrom: &effective})
+				case entity.PlaceSubdistrict:
+					subdistricts = append(subdistricts, entity.Subdistrict{SubdistrictCode: r.Code, NameTh: r.NameTh, NameEn: r.NameEn, DistrictID: parentIDs[r.Parent], ZipCode: r.Zip, ValidFrom: &effective})
+				}
+				counts.Added++
+				diff.record(&diff.Added, &geographyDiffItem{Level: level.name, Code: r.Code, NameTh: r.NameTh, Action: entity.GeographyAdded})
 				continue
 			}
-			districtCodeToID[d.DistrictCode] = newDistrict.ID
-			importedDistricts++
-			if importedDistricts%100 == 0 {
-				log.Printf("🔄 Districts progress: %d/%d (i=%d)", importedDistricts, len(districts), i)
+
+			e := existing[i]
+			ids[r.Code] = e.ID
+			changes := map[string][2]string{}
+			updates := map[string]any{}
+			if e.NameTh != r.NameTh {
+				changes["name_th"] = [2]string{e.NameTh, r.NameTh}
+				updates["name_th"] = r.NameTh
 			}
-		} else if err != nil {
-			log.Printf("❌ query district %s: %v", d.DistrictNameTh, err)
-			continue
-		} else {
-			// ensure province link is set (in case schema changed)
-			if existing.ProvinceID == 0 {
-				existing.ProvinceID = pID
-				_ = db.Save(&existing).Error
+			if e.NameEn != r.NameEn {
+				changes["name_en"] = [2]string{e.NameEn, r.NameEn}
+				updates["name_en"] = r.NameEn
 			}
-			districtCodeToID[d.DistrictCode] = existing.ID
-		}
-	}
-	log.Printf("🏁 Districts: %d new, %d total in file", importedDistricts, len(districts))
-
-	// 3) Subdistricts
-	log.Println("📌 Fetching subdistricts...")
-	subdistricts, err := fetchSubdistricts(subdistrictsURL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subdistricts: " + err.Error()})
-		return
-	}
-
-	importedSubdistricts := 0
-	for i, s := range subdistricts {
-		// district FK by districtCode
-		dID, ok := districtCodeToID[s.DistrictCode]
-		if !ok {
-			// เผื่อกรณี district ยังไม่ถูกสร้างจากขั้นตอนก่อน (เช่นเพราะ unique/err บางอย่าง)
-			// ลองหาใน DB ด้วย dCode อีกครั้ง
-			dCode := fmt.Sprintf("%04d", s.DistrictCode)
-			var d entity.District
-			if err := db.Where("district_code = ?", dCode).First(&d).Error; err == nil {
-				dID = d.ID
-				districtCodeToID[s.DistrictCode] = d.ID
-			} else {
-				log.Printf("⚠️ districtCode %d not mapped for subdistrict %s", s.DistrictCode, s.SubdistrictNameTh)
+			if level.parentTable != "" && e.Parent != r.Parent {
+				changes[level.parentCode] = [2]string{e.Parent, r.Parent}
+				updates[level.parentFK] = parentIDs[r.Parent]
+			}
+			if level.name == entity.PlaceSubdistrict && e.Zip != r.Zip {
+				changes["zip_code"] = [2]string{e.Zip, r.Zip}
+				updates["zip_code"] = r.Zip
+			}
+			action := entity.GeographyChanged
+			if e.Deleted || e.Retired {
+				changes["retired"] = [2]string{"true", "false"}
+				updates["deleted_at"], updates["retired_at"], updates["valid_from"] = nil, nil, effective
+				action = entity.GeographyRestored
+			}
+			if len(updates) == 0 {
+				counts.Unchanged++
 				continue
 			}
+			updates["updated_at"] = now
+			if err := tx.Table(level.table).Where("id = ?", e.ID).Updates(updates).Error; err != nil {
+				return nil, fmt.Errorf("update %s %s: %w", level.name, r.Code, err)
+			}
+			if action == entity.GeographyRestored {
+				counts.Restored++
+			} else {
+				counts.Changed++
+			}
+			diff.record(&diff.Changed, &geographyDiffItem{Level: level.name, Code: r.Code, NameTh: r.NameTh, Action: action, Changes: changes, id: e.ID})
 		}
 
-		sCode := fmt.Sprintf("%06d", s.SubdistrictCode) // zero-pad 6 หลัก
-
-		var existing entity.Subdistrict
-		err := db.Where("subdistrict_code = ?", sCode).First(&existing).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			newSubdistrict := entity.Subdistrict{
-				SubdistrictCode: sCode,
-				NameTh:          s.SubdistrictNameTh,
-				NameEn:          s.SubdistrictNameEn,
-				DistrictID:      dID,
-				ZipCode:         toPostalString(s.PostalCode),
+		// เพิ่มแถวใหม่ทีละ batch แล้วเก็บ ID ไว้ให้ระดับลูก
+		var err error
+		switch {
+		case len(provinces) > 0:
+			if err = tx.CreateInBatches(&provinces, geographyBatchSize).Error; err == nil {
+				for _, p := range provinces {
+					ids[p.ProvinceCode] = p.ID
+				}
 			}
-			if err := db.Create(&newSubdistrict).Error; err != nil {
-				log.Printf("❌ create subdistrict %s (%s): %v", s.SubdistrictNameTh, sCode, err)
-				continue
+		case len(districts) > 0:
+			if err = tx.CreateInBatches(&districts, geographyBatchSize).Error; err == nil {
+				for _, d := range districts {
+					ids[d.DistrictCode] = d.ID
+				}
 			}
-			importedSubdistricts++
-			if importedSubdistricts%500 == 0 {
-				log.Printf("🔄 Subdistricts progress: %d/%d (i=%d)", importedSubdistricts, len(subdistricts), i)
+		case len(subdistricts) > 0:
+			if err = tx.CreateInBatches(&subdistricts, geographyBatchSize).Error; err == nil {
+				for _, s := range subdistricts {
+					ids[s.SubdistrictCode] = s.ID
+				}
 			}
-		} else if err != nil {
-			log.Printf("❌ query subdistrict %s: %v", s.SubdistrictNameTh, err)
-			continue
-		} else {
-			// ensure FK present
-			if existing.DistrictID == 0 {
-				existing.DistrictID = dID
-				_ = db.Save(&existing).Error
+		}
+		if err != nil {
+			return nil, fmt.Errorf("insert %s: %w", level.table, err)
+		}
+
+		// รายการที่หายจากชุดข้อมูล (เช่น อำเภอเดิมที่ถูกแยก) เลิกใช้ตั้งแต่วันที่มีผล
+		var retire []uint
+		for _, e := range existing {
+			if !e.Deleted && !e.Retired && !inDataset[e.Code] {
+				retire = append(retire, e.ID)
+				counts.Retired++
+				diff.record(&diff.Retired, &geographyDiffItem{Level: level.name, Code: e.Code, NameTh: e.NameTh, Action: entity.GeographyRetired, id: e.ID})
 			}
 		}
+		for from := 0; from < len(retire); from += geographyBatchSize {
+			to := min(from+geographyBatchSize, len(retire))
+			if err := tx.Table(level.table).Where("id IN ?", retire[from:to]).
+				Updates(map[string]any{"retired_at": effective, "updated_at": now}).Error; err != nil {
+				return nil, fmt.Errorf("retire %s: %w", level.table, err)
+			}
+		}
+		parentIDs = ids
+		if err := progress(done, level.table); err != nil {
+			return nil, err
+		}
 	}
-	log.Printf("🏁 Subdistricts: %d new, %d total in file", importedSubdistricts, len(subdistricts))
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "✅ Thailand geography import completed",
-		"summary": gin.H{
-			"provinces_new":         importedProvinces,
-			"districts_new":         importedDistricts,
-			"subdistricts_new":      importedSubdistricts,
-			"file_total_provinces":  len(provinces),
-			"file_total_districts":  len(districts),
-			"file_total_subdistricts": len(subdistricts),
-		},
-	})
+	return diff, nil
 }
 
 // ------------------------------
 // ENDPOINT: GET /thailand-stats
 // ------------------------------
 func GetThailandStats(c *gin.Context) {
-	db := config.DB()
+	db := dbFor(c)
 
+	// นับเฉพาะรายการที่ยังใช้อยู่ ส่วนที่เลิกใช้แยกไว้ใน retired
 	var provinceCount, districtCount, subdistrictCount int64
-	db.Model(&entity.Province{}).Count(&provinceCount)
-	db.Model(&entity.District{}).Count(&districtCount)
-	db.Model(&entity.Subdistrict{}).Count(&subdistrictCount)
+	db.Model(&entity.Province{}).Where("retired_at IS NULL").Count(&provinceCount)
+	db.Model(&entity.District{}).Where("retired_at IS NULL").Count(&districtCount)
+	db.Model(&entity.Subdistrict{}).Where("retired_at IS NULL").Count(&subdistrictCount)
+	var retiredProvinces, retiredDistricts, retiredSubdistricts int64
+	db.Model(&entity.Province{}).Where("retired_at IS NOT NULL").Count(&retiredProvinces)
+	db.Model(&entity.District{}).Where("retired_at IS NOT NULL").Count(&retiredDistricts)
+	db.Model(&entity.Subdistrict{}).Where("retired_at IS NOT NULL").Count(&retiredSubdistricts)
+	var latest entity.GeographyVersion
+	db.Order("effective_date DESC, id DESC").Limit(1).Find(&latest)
 
 	// จำนวนล่าสุดจากแหล่งข้อมูล: 77 / 928 / 7436
 	c.JSON(http.StatusOK, gin.H{
@@ -234,6 +661,12 @@ func GetThailandStats(c *gin.Context) {
 			"districts":    districtCount,
 			"subdistricts": subdistrictCount,
 		},
+		"retired": gin.H{
+			"provinces":    retiredProvinces,
+			"districts":    retiredDistricts,
+			"subdistricts": retiredSubdistricts,
+		},
+		"version": latest.Version,
 		"expected": gin.H{
 			"provinces":    77,
 			"districts":    928,
@@ -245,45 +678,88 @@ func GetThailandStats(c *gin.Context) {
 // ------------------------------
 // ENDPOINT: POST /clear-thailand-data
 // ------------------------------
+// ลบข้อมูลพื้นที่ทั้งหมดได้เฉพาะตอนที่ยังไม่มีอะไรอ้างอยู่ (เช่น ตั้งระบบใหม่) ถ้ามีตอบ 409 พร้อมจำนวนแถวที่อ้าง
+// รหัสที่เลิกใช้ให้นำเข้าชุดข้อมูลใหม่แทน (รายการที่หายไปจะถูก retire ไม่ถูกลบ)
+// ตอบ 202 พร้อม job แล้วลบเบื้องหลัง
 func ClearThailandData(c *gin.Context) {
-	db := config.DB()
-
-	// ลบตามลำดับ FK: subdistricts -> districts -> provinces
-	if err := db.Exec("DELETE FROM subdistricts").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear subdistricts: " + err.Error()})
+	refs, err := geographyReferences(dbFor(c))
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
 		return
 	}
-	if err := db.Exec("DELETE FROM districts").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear districts: " + err.Error()})
+	if len(refs) > 0 {
+		apierr.Respond(c, apierr.Conflict("Geography data is still referenced; import a new dataset version to retire codes instead").
+			With("dependents", refs))
 		return
 	}
-	if err := db.Exec("DELETE FROM provinces").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear provinces: " + err.Error()})
-		return
+	enqueueJob(c, jobGeographyClear, nil)
+}
+
+// runGeographyClear คืองาน geography_clear ลบทีละ batch ตามลำดับ FK (subdistricts -> districts -> provinces)
+// ทำใน transaction เดียวและตรวจแถวที่อ้างอยู่ซ้ำ ถ้ามีหรือถูกยกเลิกกลางทางจะไม่ลบอะไรเลย
+func runGeographyClear(ctx context.Context, t *jobs.Task) (any, error) {
+	tables := []string{"subdistricts", "districts", "provinces"}
+	deleted := map[string]int64{}
+	err := config.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		refs, err := geographyReferences(tx)
+		if err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			return jobs.Fail("geography data is still referenced: %v", refs)
+		}
+		var total int64
+		for _, table := range tables {
+			var n int64
+			if err := tx.Table(table).Count(&n).Error; err != nil {
+				return err
+			}
+			total += n
+		}
+		var done int64
+		for _, table := range tables {
+			for {
+				res := tx.Exec("DELETE FROM "+table+" WHERE id IN (SELECT id FROM "+table+" LIMIT ?)", geographyBatchSize)
+				if res.Error != nil {
+					return fmt.Errorf("clear %s: %w", table, res.Error)
+				}
+				if res.RowsAffected == 0 {
+					break
+				}
+				deleted[table] += res.RowsAffected
+				done += res.RowsAffected
+				if err := t.Progress(ctx, int(done), int(total), "Deleting "+table); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "🗑️ Cleared all Thailand geography data"})
+	resetSearchLexicon()
+	return gin.H{"message": "🗑️ Cleared all Thailand geography data", "deleted": deleted}, nil
 }
 
 // ------------------------------
 // Helpers
 // ------------------------------
-var httpClient = &http.Client{
-	Timeout: 30 * time.Second,
-}
+var httpClient = tracing.HTTPClient(30 * time.Second)
 
-func fetchProvinces(url string) ([]ThaiProvince, error) {
-	return fetchData[ThaiProvince](url)
+func fetchProvinces(ctx context.Context, url string) ([]ThaiProvince, error) {
+	return fetchData[ThaiProvince](ctx, url)
 }
-func fetchDistricts(url string) ([]ThaiDistrict, error) {
-	return fetchData[ThaiDistrict](url)
+func fetchDistricts(ctx context.Context, url string) ([]ThaiDistrict, error) {
+	return fetchData[ThaiDistrict](ctx, url)
 }
-func fetchSubdistricts(url string) ([]ThaiSubdistrict, error) {
-	return fetchData[ThaiSubdistrict](url)
+func fetchSubdistricts(ctx context.Context, url string) ([]ThaiSubdistrict, error) {
+	return fetchData[ThaiSubdistrict](ctx, url)
 }
 
-func fetchData[T any](url string) ([]T, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func fetchData[T any](ctx context.Context, url string) ([]T, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("new request: %w", err)
 	}
@@ -307,26 +783,26 @@ func fetchData[T any](url string) ([]T, error) {
 	return data, nil
 }
 func toPostalString(v any) string {
-    switch x := v.(type) {
-    case nil:
-        return ""
-    case string:
-        // กรณีเป็นสตริงอยู่แล้ว (เช่น "10220")
-        if len(x) == 5 {
-            return x
-        }
-        // เผื่อบางแถวเป็น "1022" → pad เป็น 5 หลัก
-        if len(x) > 0 && len(x) < 5 {
-            return fmt.Sprintf("%05s", x)
-        }
-        return x
-    case float64:
-        // JSON number จะมาเป็น float64
-        return fmt.Sprintf("%05d", int(x))
-    case int:
-        return fmt.Sprintf("%05d", x)
-    default:
-        // เผื่อกรณีแปลก ๆ
-        return fmt.Sprintf("%v", x)
-    }
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		// กรณีเป็นสตริงอยู่แล้ว (เช่น "10220")
+		if len(x) == 5 {
+			return x
+		}
+		// เผื่อบางแถวเป็น "1022" → pad เป็น 5 หลัก
+		if len(x) > 0 && len(x) < 5 {
+			return fmt.Sprintf("%05s", x)
+		}
+		return x
+	case float64:
+		// JSON number จะมาเป็น float64
+		return fmt.Sprintf("%05d", int(x))
+	case int:
+		return fmt.Sprintf("%05d", x)
+	default:
+		// เผื่อกรณีแปลก ๆ
+		return fmt.Sprintf("%v", x)
+	}
 }
@@ -0,0 +1,399 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// ---------- DTO รับจาก UI: ส่งกำหนดการทั้งชุดมาแทนที่ของเดิม ----------
+type ItineraryActivityRequest struct {
+	StartTime     string `json:"start_time"` // "HH:MM"
+	EndTime       string `json:"end_time"`   // "HH:MM"
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	EventID       *uint  `json:"event_id"`
+	LocationID    *uint  `json:"location_id"`
+	PackageStayID *uint  `json:"package_stay_id"`
+}
+
+type ItineraryDayRequest struct {
+	Date        string                     `json:"date"` // "YYYY-MM-DD" ไม่ส่ง = วันเริ่มแพ็คเกจ + ลำดับวัน
+	Title       string                     `json:"title"`
+	Description string                     `json:"description"`
+	Activities  []ItineraryActivityRequest `json:"activities"`
+}
+
+type ItineraryRequest struct {
+	Days []ItineraryDayRequest `json:"days"`
+}
+
+// ---------- DTO ส่งกลับแบบซ้อนกัน ----------
+type ItineraryStayResponse struct {
+	ID                uint   `json:"id"`
+	AccommodationID   uint   `json:"accommodation_id"`
+	AccommodationName string `json:"accommodation_name"`
+	RoomID            *uint  `json:"room_id"`
+	RoomName          string `json:"room_name,omitempty"`
+}
+
+type ItineraryRefResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type ItineraryActivityResponse struct {
+	ID          uint                   `json:"id"`
+	Seq         uint                   `json:"seq"`
+	StartTime   string                 `json:"start_time"`
+	EndTime     string                 `json:"end_time"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Kind        string                 `json:"kind"` // event|location|stay|free
+	Event       *ItineraryRefResponse  `json:"event,omitempty"`
+	Location    *ItineraryRefResponse  `json:"location,omitempty"`
+	Stay        *ItineraryStayResponse `json:"stay,omitempty"`
+}
+
+type ItineraryDayResponse struct {
+	ID          uint                        `json:"id"`
+	DayNumber   uint                        `json:"day_number"`
+	Date        string                      `json:"date"`
+	Title       string                      `json:"title"`
+	Description string                      `json:"description"`
+	Activities  []ItineraryActivityResponse `json:"activities"`
+}
+
+type ItineraryResponse struct {
+	PackageID uint                   `json:"package_id"`
+	StartDate string                 `json:"start_date"`
+	FinalDate string                 `json:"final_date"`
+	Days      []ItineraryDayResponse `json:"days"`
+}
+
+// parseHM ตรวจรูปแบบ "HH:MM" คืนค่าเป็นนาทีนับจากเที่ยงคืน (-1 = ไม่ได้ส่งมา)
+func parseHM(s string) (int, error) {
+	if s == "" {
+		return -1, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func loadItinerary(db *gorm.DB, pack entity.Package) (ItineraryResponse, error) {
+	var days []entity.ItineraryDay
+	err := db.
+		Preload("Activities", func(db *gorm.DB) *gorm.DB { return db.Order("seq") }).
+		Preload("Activities.Event").
+		Preload("Activities.Location").
+		Preload("Activities.PackageStay.Accommodation").
+		Preload("Activities.PackageStay.Room").
+		Where("package_id = ?", pack.ID).
+		Order("day_number").
+		Find(&days).Error
+	if err != nil {
+		return ItineraryResponse{}, err
+	}
+
+	res := ItineraryResponse{
+		PackageID: pack.ID,
+		StartDate: pack.StartDate.Format("2006-01-02"),
+		FinalDate: pack.FinalDate.Format("2006-01-02"),
+		Days:      make([]ItineraryDayResponse, 0, len(days)),
+	}
+	for _, d := range days {
+		day := ItineraryDayResponse{
+			ID:          d.ID,
+			DayNumber:   d.DayNumber,
+			Date:        d.Date.Format("2006-01-02"),
+			Title:       d.Title,
+			Description: d.Description,
+			Activities:  make([]ItineraryActivityResponse, 0, len(d.Activities)),
+		}
+		for _, a := range d.Activities {
+			act := ItineraryActivityResponse{
+				ID: a.ID, Seq: a.Seq, StartTime: a.StartTime, EndTime: a.EndTime,
+				Title: a.Title, Description: a.Description, Kind: "free",
+			}
+			switch {
+			case a.Event != nil:
+				act.Kind = "event"
+				act.Event = &ItineraryRefResponse{ID: a.Event.ID, Name: a.Event.Event_Name}
+			case a.Location != nil:
+				act.Kind = "location"
+				act.Location = &ItineraryRefResponse{ID: a.Location.ID, Name: a.Location.Name}
+			case a.PackageStay != nil:
+				act.Kind = "stay"
+				act.Stay = &ItineraryStayResponse{
+					ID:                a.PackageStay.ID,
+					AccommodationID:   a.PackageStay.AccommodationID,
+					AccommodationName: a.PackageStay.Accommodation.Name,
+					RoomID:            a.PackageStay.RoomID,
+				}
+				if a.PackageStay.RoomID != nil {
+					act.Stay.RoomName = a.PackageStay.Room.Name
+				}
+			}
+			day.Activities = append(day.Activities, act)
+		}
+		res.Days = append(res.Days, day)
+	}
+	return res, nil
+}
+
+// validateStayNight ตรวจว่าที่พัก/ห้องของ PackageStay เปิดให้บริการ และห้องไม่ถูกใช้โดยแพ็คเกจอื่นในคืนเดียวกัน
+func validateStayNight(tx *gorm.DB, pack entity.Package, stayID uint, date time.Time) string {
+	var stay entity.PackageStay
+	if err := tx.Preload("Accommodation").Preload("Room").First(&stay, stayID).Error; err != nil {
+		return fmt.Sprintf("PackageStay %d not found", stayID)
+	}
+	if stay.PackageID != pack.ID {
+		return fmt.Sprintf("PackageStay %d does not belong to this package", stayID)
+	}
+	if stay.Accommodation.Status == "closed" {
+		return fmt.Sprintf("Accommodation %q is closed", stay.Accommodation.Name)
+	}
+	if stay.RoomID == nil {
+		return ""
+	}
+	if stay.Room.Status == "closed" {
+		return fmt.Sprintf("Room %q is closed", stay.Room.Name)
+	}
+
+	var taken int64
+	tx.Model(&entity.ItineraryActivity{}).
+		Joins("JOIN itinerary_days ON itinerary_days.id = itinerary_activities.itinerary_day_id AND itinerary_days.deleted_at IS NULL").
+		Joins("JOIN package_stays ON package_stays.id = itinerary_activities.package_stay_id AND package_stays.deleted_at IS NULL").
+		Where("package_stays.room_id = ? AND itinerary_days.package_id <> ? AND itinerary_days.date = ?", *stay.RoomID, pack.ID, date).
+		Count(&taken)
+	if taken > 0 {
+		return fmt.Sprintf("Room %q is already allocated on %s", stay.Room.Name, date.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// checkItineraryRange ตรวจว่ากำหนดการที่มีอยู่ยังอยู่ในช่วงวันที่ใหม่ของแพ็คเกจ
+func checkItineraryRange(tx *gorm.DB, packageID uint, start, end time.Time) (bool, error) {
+	q := tx.Model(&entity.ItineraryDay{}).Where("package_id = ?", packageID)
+	if !start.IsZero() && !end.IsZero() {
+		q = q.Where("date < ? OR date > ?", start, end)
+	}
+	var outside int64
+	if err := q.Count(&outside).Error; err != nil {
+		return false, err
+	}
+	return outside == 0, nil
+}
+
+// GET /package/:id/itinerary
+func FindPackageItinerary(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid package ID format"))
+		return
+	}
+
+	db := dbFor(c)
+	var pack entity.Package
+	if err := db.First(&pack, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierr.Respond(c, apierr.NotFound("Package not found"))
+		} else {
+			apierr.Respond(c, apierr.Internal(err))
+		}
+		return
+	}
+
+	res, err := loadItinerary(db, pack)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// PUT /package/:id/itinerary - แทนที่กำหนดการทั้งหมดของแพ็คเกจ
+func UpdatePackageItinerary(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid package ID format"))
+		return
+	}
+
+	var req ItineraryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	var pack entity.Package
+	if err := db.First(&pack, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierr.Respond(c, apierr.NotFound("Package not found"))
+		} else {
+			apierr.Respond(c, apierr.Internal(err))
+		}
+		return
+	}
+	if len(req.Days) > 0 && (pack.StartDate.IsZero() || pack.FinalDate.IsZero()) {
+		apierr.Respond(c, apierr.BadRequest("Package start_date and final_date must be set before building an itinerary"))
+		return
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	fail := func(err *apierr.Error) {
+		tx.Rollback()
+		apierr.Respond(c, err)
+	}
+
+	days := make([]entity.ItineraryDay, 0, len(req.Days))
+	var prevDate time.Time
+	for i, d := range req.Days {
+		dayNo := i + 1
+		date := pack.StartDate.AddDate(0, 0, i)
+		if d.Date != "" {
+			t, err := parseYMD(d.Date)
+			if err != nil {
+				fail(apierr.BadRequest(fmt.Sprintf("day %d: date must be YYYY-MM-DD", dayNo)))
+				return
+			}
+			date = t
+		}
+		if date.Before(pack.StartDate) || date.After(pack.FinalDate) {
+			fail(apierr.BadRequest(fmt.Sprintf("day %d: date %s is outside the package date range", dayNo, date.Format("2006-01-02"))))
+			return
+		}
+		if i > 0 && !date.After(prevDate) {
+			fail(apierr.BadRequest(fmt.Sprintf("day %d: dates must be in ascending order", dayNo)))
+			return
+		}
+		prevDate = date
+
+		day := entity.ItineraryDay{
+			PackageID:   pack.ID,
+			DayNumber:   uint(dayNo),
+			Date:        date,
+			Title:       strings.TrimSpace(d.Title),
+			Description: strings.TrimSpace(d.Description),
+		}
+
+		lastEnd := -1
+		for j, a := range d.Activities {
+			where := fmt.Sprintf("day %d activity %d", dayNo, j+1)
+
+			start, err := parseHM(a.StartTime)
+			if err != nil {
+				fail(apierr.BadRequest(where + ": start_time must be HH:MM"))
+				return
+			}
+			end, err := parseHM(a.EndTime)
+			if err != nil {
+				fail(apierr.BadRequest(where + ": end_time must be HH:MM"))
+				return
+			}
+			if start >= 0 && end >= 0 && end < start {
+				fail(apierr.BadRequest(where + ": end_time must not be before start_time"))
+				return
+			}
+			if start >= 0 && lastEnd >= 0 && start < lastEnd {
+				fail(apierr.BadRequest(where + ": overlaps the previous activity"))
+				return
+			}
+			if end >= 0 {
+				lastEnd = end
+			} else if start >= 0 {
+				lastEnd = start
+			}
+
+			refs := 0
+			for _, ref := range []*uint{a.EventID, a.LocationID, a.PackageStayID} {
+				if ref != nil {
+					refs++
+				}
+			}
+			if refs > 1 {
+				fail(apierr.BadRequest(where + ": reference only one of event_id, location_id or package_stay_id"))
+				return
+			}
+			if a.EventID != nil {
+				var ev entity.Event
+				if err := tx.First(&ev, *a.EventID).Error; err != nil {
+					fail(apierr.BadRequest(where + ": Event not found"))
+					return
+				}
+			}
+			if a.LocationID != nil {
+				var loc entity.Location
+				if err := tx.First(&loc, *a.LocationID).Error; err != nil {
+					fail(apierr.BadRequest(where + ": Location not found"))
+					return
+				}
+			}
+			if a.PackageStayID != nil {
+				if msg := validateStayNight(tx, pack, *a.PackageStayID, date); msg != "" {
+					fail(apierr.Conflict(where + ": " + msg))
+					return
+				}
+			}
+
+			day.Activities = append(day.Activities, entity.ItineraryActivity{
+				Seq:           uint(j + 1),
+				StartTime:     a.StartTime,
+				EndTime:       a.EndTime,
+				Title:         strings.TrimSpace(a.Title),
+				Description:   strings.TrimSpace(a.Description),
+				EventID:       a.EventID,
+				LocationID:    a.LocationID,
+				PackageStayID: a.PackageStayID,
+			})
+		}
+		days = append(days, day)
+	}
+
+	// ลบของเดิมจริง (unique day_number) แล้วสร้างใหม่ทั้งชุด
+	if err := tx.Unscoped().
+		Where("itinerary_day_id IN (?)", tx.Unscoped().Model(&entity.ItineraryDay{}).Select("id").Where("package_id = ?", pack.ID)).
+		Delete(&entity.ItineraryActivity{}).Error; err != nil {
+		fail(apierr.Wrap(err, "Failed to clear itinerary"))
+		return
+	}
+	if err := tx.Unscoped().Where("package_id = ?", pack.ID).Delete(&entity.ItineraryDay{}).Error; err != nil {
+		fail(apierr.Wrap(err, "Failed to clear itinerary"))
+		return
+	}
+	if len(days) > 0 {
+		if err := tx.Create(&days).Error; err != nil {
+			fail(apierr.Wrap(err, "Failed to save itinerary"))
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	res, err := loadItinerary(db, pack)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": res, "message": "Itinerary updated successfully"})
+}
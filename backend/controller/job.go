@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/jobs"
+	"gorm.io/gorm"
+)
+
+// RegisterJobs ผูกชนิดงานเบื้องหลังกับตัวทำงาน เรียกก่อน jobs.Start
+func RegisterJobs() {
+	jobs.Register(jobGeographyImport, 3, runGeographyImport) // remote อาจล่มชั่วคราว
+	jobs.Register(jobGeographyClear, 1, runGeographyClear)
+	jobs.Register(jobLedgerReconcile, 2, runReconcilePayments)
+}
+
+// jobView คือ Job ที่ตอบกลับ: ความคืบหน้าล่าสุดของงานที่กำลังทำ และผลลัพธ์เป็น JSON
+type jobView struct {
+	entity.Job
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+func viewJob(job entity.Job) jobView {
+	if job.Status == entity.JobRunning {
+		if progress, message, ok := jobs.Live(job.ID); ok {
+			job.Progress, job.Message = progress, message
+		}
+	}
+	v := jobView{Job: job}
+	if job.Result != "" {
+		v.Result = json.RawMessage(job.Result)
+	}
+	return v
+}
+
+// enqueueJob เข้าคิวงานแล้วตอบ 202 พร้อม job (Location = ที่สำหรับ poll)
+func enqueueJob(c *gin.Context, kind string, payload any) {
+	enqueueJobAt(c, kind, payload, time.Now())
+}
+
+// enqueueJobAt เหมือน enqueueJob แต่งานเริ่มไม่ก่อนเวลา at
+func enqueueJobAt(c *gin.Context, kind string, payload any, at time.Time) {
+	job, err := jobs.EnqueueAt(dbFor(c), kind, payload, at)
+	if err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to queue job"))
+		return
+	}
+	c.Header("Location", "/api/v1/jobs/"+strconv.Itoa(int(job.ID)))
+	c.JSON(http.StatusAccepted, gin.H{"data": viewJob(job), "message": "Job queued successfully"})
+}
+
+// GET /jobs?kind=&status=&limit= - งานล่าสุดก่อน
+func FindJobs(c *gin.Context) {
+	db := dbFor(c)
+	if kind := c.Query("kind"); kind != "" {
+		db = db.Where("kind = ?", kind)
+	}
+	if status := c.Query("status"); status != "" {
+		db = db.Where("status = ?", status)
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		apierr.Respond(c, apierr.BadRequest("limit must be between 1 and 200"))
+		return
+	}
+	var list []entity.Job
+	// ไม่ดึง payload/result (อาจเป็นชุดข้อมูลทั้งก้อน) ดูผลที่ GET /jobs/:id
+	if err := db.Omit("payload", "result").Order("id DESC").Limit(limit).Find(&list).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	views := make([]jobView, 0, len(list))
+	for _, j := range list {
+		views = append(views, viewJob(j))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": views})
+}
+
+// GET /jobs/:id - สถานะ ความคืบหน้า และผลลัพธ์เมื่อเสร็จ
+func GetJob(c *gin.Context) {
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+	var job entity.Job
+	if err := dbFor(c).Omit("payload").First(&job, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Job not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": viewJob(job)})
+}
+
+// POST /jobs/:id/cancel - งานในคิวยกเลิกทันที งานที่กำลังทำจะหยุดที่จุดตรวจถัดไป
+func CancelJob(c *gin.Context) {
+	jobAction(c, jobs.Cancel, "Job cancellation requested successfully")
+}
+
+// POST /jobs/:id/retry - นำงานที่ล้มเหลว/ยกเลิกกลับเข้าคิว
+func RetryJob(c *gin.Context) {
+	jobAction(c, jobs.Retry, "Job queued for retry successfully")
+}
+
+func jobAction(c *gin.Context, action func(*gorm.DB, uint) (entity.Job, error), message string) {
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+	job, err := action(dbFor(c), id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierr.Respond(c, apierr.NotFound("Job not found"))
+	case errors.Is(err, jobs.ErrFinished):
+		apierr.Respond(c, apierr.Conflict("Job is "+job.Status))
+	case err != nil:
+		apierr.Respond(c, apierr.Internal(err))
+	default:
+		job.Payload = ""
+		c.JSON(http.StatusOK, gin.H{"data": viewJob(job), "message": message})
+	}
+}
+
+func jobID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		apierr.Respond(c, apierr.BadRequest("Invalid job ID format"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,562 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/jobs"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+// ส่วนแบ่งแพลตฟอร์มเมื่อประเภทไกด์ยังไม่มี CommissionRule (20%)
+const defaultCommissionBP = 2000
+
+type CommissionRuleRequest struct {
+	RateBP *uint `json:"rate_bp"`
+}
+
+type PostReservationRequest struct {
+	PaymentdetailID *uint `json:"payment_detail_id"`
+}
+
+// revenueLine คือรายได้ของ 1 รายการในการจอง ก่อนแบ่งค่าคอมมิชชัน
+type revenueLine struct {
+	PackageID *uint
+	GuideID   *uint
+	Gross     money.Money
+	Memo      string
+}
+
+// reservationRevenueLines แตกยอดรายได้ของการจองตาม ReservationItem (ราคาที่ตีตอน checkout)
+// การจองเก่าที่ไม่มี item ใช้ราคาแพ็คเกจที่ผูกกับ reservation_id แทน
+func reservationRevenueLines(tx *gorm.DB, reservation entity.Reservation) ([]revenueLine, error) {
+	var items []entity.ReservationItem
+	if err := tx.Preload("Package").Where("reservation_id = ?", reservation.ID).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		lines := make([]revenueLine, 0, len(items))
+		for _, it := range items {
+			l := revenueLine{PackageID: it.PackageID, Gross: it.Total}
+			switch {
+			case it.PackageID != nil:
+				l.Memo = fmt.Sprintf("package #%d", *it.PackageID)
+				if it.Package != nil {
+					l.GuideID = it.Package.GuideID
+				}
+			case it.RoomID != nil:
+				l.Memo = fmt.Sprintf("room #%d", *it.RoomID)
+			case it.EventID != nil:
+				l.Memo = fmt.Sprintf("event #%d", *it.EventID)
+			}
+			lines = append(lines, l)
+		}
+		return lines, nil
+	}
+
+	var packs []entity.Package
+	if err := tx.Where("reservation_id = ?", reservation.ID).Find(&packs).Error; err != nil {
+		return nil, err
+	}
+	lines := make([]revenueLine, 0, len(packs))
+	for _, p := range packs {
+		packageID := p.ID
+		lines = append(lines, revenueLine{
+			PackageID: &packageID, GuideID: p.GuideID, Gross: money.FromMajor(int64(p.Price)),
+			Memo: fmt.Sprintf("package #%d", p.ID),
+		})
+	}
+	return lines, nil
+}
+
+// commissionRateFor คืน rate ของไกด์ ถ้าไกด์มีหลายประเภทจะใช้ rate ที่สูงที่สุด
+func commissionRateFor(tx *gorm.DB, guideID uint) (int64, error) {
+	var rules []entity.CommissionRule
+	if err := tx.
+		Where("guide_type_id IN (?)", tx.Table("guide_type").Select("guide_type_id").Where("guide_id = ?", guideID)).
+		Find(&rules).Error; err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 {
+		return defaultCommissionBP, nil
+	}
+	var rate int64
+	for _, r := range rules {
+		if int64(r.RateBP) > rate {
+			rate = int64(r.RateBP)
+		}
+	}
+	return rate, nil
+}
+
+// splitCommission แบ่งยอด gross เป็นค่าคอมแพลตฟอร์มกับค่าไกด์ (ปัดเศษครึ่งขึ้น)
+func splitCommission(gross money.Money, rateBP int64) (commission, guideFee money.Money) {
+	commission = (gross*money.Money(rateBP) + 5000) / 10000
+	return commission, gross - commission
+}
+
+// GET /commission-rule
+func FindCommissionRules(c *gin.Context) {
+	var rules []entity.CommissionRule
+	if err := dbFor(c).Order("guide_type_id").Find(&rules).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules, "default_rate_bp": defaultCommissionBP})
+}
+
+// PUT /commission-rule/:guide_type_id - สร้างหรือแก้ rate ของประเภทไกด์
+func UpsertCommissionRule(c *gin.Context) {
+	guideTypeID, err := strconv.Atoi(c.Param("guide_type_id"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide type ID format"))
+		return
+	}
+
+	var req CommissionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	if req.RateBP == nil || *req.RateBP > 10000 {
+		apierr.Respond(c, apierr.BadRequest("rate_bp must be between 0 and 10000"))
+		return
+	}
+
+	db := dbFor(c)
+	var gt entity.GuideType
+	if err := db.First(&gt, guideTypeID).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("GuideType not found"))
+		return
+	}
+
+	rule := entity.CommissionRule{GuideTypeID: gt.ID}
+	if err := db.Where("guide_type_id = ?", gt.ID).FirstOrInit(&rule).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	rule.RateBP = *req.RateBP
+	if err := db.Save(&rule).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to save commission rule"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule, "message": "Commission rule saved successfully"})
+}
+
+// POST /ledger/reservation/:id - ลงบัญชีรายได้ของการจอง (ทำได้ครั้งเดียวต่อการจอง)
+func PostReservationToLedger(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid reservation ID format"))
+		return
+	}
+
+	var req PostReservationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierr.Respond(c, apierr.Bind(err))
+			return
+		}
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var reservation entity.Reservation
+	if err := tx.First(&reservation, id).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			apierr.Respond(c, apierr.NotFound("Reservation not found"))
+		} else {
+			apierr.Respond(c, apierr.Internal(err))
+		}
+		return
+	}
+
+	if reservation.Status == "cancelled" {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Reservation is cancelled"))
+		return
+	}
+	var posted int64
+	tx.Model(&entity.LedgerEntry{}).Where("reservation_id = ?", reservation.ID).Count(&posted)
+	if posted > 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Reservation is already posted to the ledger"))
+		return
+	}
+
+	if req.PaymentdetailID != nil {
+		var pd entity.Paymentdetail
+		if err := tx.First(&pd, *req.PaymentdetailID).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("Paymentdetail not found"))
+			return
+		}
+		if pd.MemberID != reservation.MemberID {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest("Paymentdetail belongs to another member"))
+			return
+		}
+	}
+
+	lines, err := reservationRevenueLines(tx, reservation)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if len(lines) == 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("Reservation has no revenue to post"))
+		return
+	}
+
+	txRef := fmt.Sprintf("RSV-%d", reservation.ID)
+	var entries []entity.LedgerEntry
+	var gross, commission, guideFees money.Money
+	for _, l := range lines {
+		rate := int64(10000) // ไม่มีไกด์ = รายได้แพลตฟอร์มทั้งหมด
+		if l.GuideID != nil {
+			if rate, err = commissionRateFor(tx, *l.GuideID); err != nil {
+				tx.Rollback()
+				apierr.Respond(c, apierr.Internal(err))
+				return
+			}
+		}
+		comm, fee := splitCommission(l.Gross, rate)
+		packageID, memo := l.PackageID, l.Memo
+
+		entries = append(entries, entity.LedgerEntry{
+			TxRef: txRef, Account: entity.AccountCash, Debit: l.Gross, Memo: memo,
+			ReservationID: &reservation.ID, PaymentdetailID: req.PaymentdetailID, PackageID: packageID,
+		})
+		if comm > 0 {
+			entries = append(entries, entity.LedgerEntry{
+				TxRef: txRef, Account: entity.AccountPlatformCommission, Credit: comm, Memo: memo,
+				ReservationID: &reservation.ID, PackageID: packageID, GuideID: l.GuideID,
+			})
+		}
+		if fee > 0 {
+			entries = append(entries, entity.LedgerEntry{
+				TxRef: txRef, Account: entity.AccountGuidePayable, Credit: fee, Memo: memo,
+				ReservationID: &reservation.ID, PackageID: packageID, GuideID: l.GuideID,
+			})
+		}
+		gross += l.Gross
+		commission += comm
+		guideFees += fee
+	}
+
+	if err := tx.Create(&entries).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to post ledger entries"))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": entries,
+		"summary": gin.H{
+			"gross":               gross,
+			"platform_commission": commission,
+			"guide_fees":          guideFees,
+		},
+		"message": "Reservation posted to ledger successfully",
+	})
+}
+
+// reverseReservationEntries กลับรายการบัญชีของการจองที่ยกเลิก: ทุกขาที่ลงไว้ลงซ้ำโดยสลับ Debit/Credit
+// ภายใต้ TxRef "RSV-<id>-REV" (cash credit, platform_commission debit, guide_payable debit)
+// ค่าไกด์ที่เข้ารอบจ่ายแล้วกลับรายการไม่ได้ จะคืน errGuideFeeBatched
+func reverseReservationEntries(tx *gorm.DB, reservationID uint) error {
+	var posted []entity.LedgerEntry
+	if err := tx.Where("reservation_id = ? AND tx_ref = ?", reservationID, fmt.Sprintf("RSV-%d", reservationID)).
+		Order("id").Find(&posted).Error; err != nil {
+		return err
+	}
+	if len(posted) == 0 {
+		return nil
+	}
+	var reversed int64
+	if err := tx.Model(&entity.LedgerEntry{}).
+		Where("tx_ref = ?", fmt.Sprintf("RSV-%d-REV", reservationID)).Count(&reversed).Error; err != nil {
+		return err
+	}
+	if reversed > 0 {
+		return nil
+	}
+	for _, e := range posted {
+		if e.Account == entity.AccountGuidePayable && e.PayoutBatchID != nil {
+			return errGuideFeeBatched
+		}
+	}
+
+	txRef := fmt.Sprintf("RSV-%d-REV", reservationID)
+	entries := make([]entity.LedgerEntry, 0, len(posted))
+	for _, e := range posted {
+		entries = append(entries, entity.LedgerEntry{
+			TxRef: txRef, Account: e.Account, Debit: e.Credit, Credit: e.Debit,
+			Memo:          "reversal: " + e.Memo,
+			ReservationID: e.ReservationID, PaymentdetailID: e.PaymentdetailID,
+			PackageID: e.PackageID, GuideID: e.GuideID,
+		})
+	}
+	return tx.Create(&entries).Error
+}
+
+// errGuideFeeBatched คือค่าไกด์ของการจองเข้ารอบจ่าย (payout batch) ไปแล้ว
+var errGuideFeeBatched = errors.New("guide fee is already in a payout batch")
+
+// GET /ledger?reservation_id=&guide_id=&account=
+func FindLedgerEntries(c *gin.Context) {
+	q := dbFor(c).Order("id")
+	if v := c.Query("reservation_id"); v != "" {
+		q = q.Where("reservation_id = ?", v)
+	}
+	if v := c.Query("guide_id"); v != "" {
+		q = q.Where("guide_id = ?", v)
+	}
+	if v := c.Query("account"); v != "" {
+		q = q.Where("account = ?", v)
+	}
+	if v := c.Query("tx_ref"); v != "" {
+		q = q.Where("tx_ref = ?", v)
+	}
+
+	var entries []entity.LedgerEntry
+	if err := q.Find(&entries).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+// GET /ledger/reconcile?from=YYYY-MM-DD&to=YYYY-MM-DD
+// เทียบยอดใน Paymentdetail กับยอดเงินสดที่ลงบัญชีไว้ (ช่วงยาวใช้ POST เพื่อทำเบื้องหลัง)
+func ReconcilePayments(c *gin.Context) {
+	from, to, ok := reconcileRange(c)
+	if !ok {
+		return
+	}
+	report, err := reconcileReport(dbFor(c), from, to)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// POST /ledger/reconcile?from=YYYY-MM-DD&to=YYYY-MM-DD - สร้างรายงานเดียวกันเป็นงานเบื้องหลัง ผลอยู่ที่ GET /jobs/:id
+func EnqueueReconcilePayments(c *gin.Context) {
+	if _, _, ok := reconcileRange(c); !ok {
+		return
+	}
+	enqueueJob(c, jobLedgerReconcile, reconcilePayload{From: c.Query("from"), To: c.Query("to")})
+}
+
+const jobLedgerReconcile = "ledger_reconcile"
+
+type reconcilePayload struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// runReconcilePayments คืองาน ledger_reconcile
+func runReconcilePayments(ctx context.Context, t *jobs.Task) (any, error) {
+	var payload reconcilePayload
+	if err := t.Decode(&payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+	from, err := parseYMD(payload.From)
+	if err != nil {
+		return nil, jobs.Fail("from must be YYYY-MM-DD")
+	}
+	to, err := parseYMD(payload.To)
+	if err != nil {
+		return nil, jobs.Fail("to must be YYYY-MM-DD")
+	}
+	t.Progress(ctx, 0, 1, "Reconciling payments")
+	return reconcileReport(config.DB().WithContext(ctx), from, to)
+}
+
+// reconcileRange อ่าน from/to จาก query (ว่างได้) ตอบ 400 เองถ้ารูปแบบผิด
+func reconcileRange(c *gin.Context) (from, to time.Time, ok bool) {
+	from, err := parseYMD(c.Query("from"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("from must be YYYY-MM-DD"))
+		return from, to, false
+	}
+	to, err = parseYMD(c.Query("to"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("to must be YYYY-MM-DD"))
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// reconcileReport เทียบยอดการชำระแต่ละรายการกับยอดเงินสดในบัญชี
+func reconcileReport(db *gorm.DB, from, to time.Time) (gin.H, error) {
+	q := db.Order("id")
+	if !from.IsZero() {
+		q = q.Where("payment_date >= ?", from)
+	}
+	if !to.IsZero() {
+		q = q.Where("payment_date < ?", to.AddDate(0, 0, 1))
+	}
+	var payments []entity.Paymentdetail
+	if err := q.Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	type postedSum struct {
+		PaymentdetailID uint
+		Total           money.Money
+	}
+	var sums []postedSum
+	if err := db.Model(&entity.LedgerEntry{}).
+		Select("paymentdetail_id, SUM(debit) - SUM(credit) AS total").
+		Where("account = ? AND paymentdetail_id IS NOT NULL", entity.AccountCash).
+		Group("paymentdetail_id").
+		Scan(&sums).Error; err != nil {
+		return nil, err
+	}
+	posted := make(map[uint]money.Money, len(sums))
+	for _, s := range sums {
+		posted[s.PaymentdetailID] = s.Total
+	}
+
+	rows := make([]gin.H, 0, len(payments))
+	mismatches := 0
+	for _, p := range payments {
+		// Amount_id เก็บยอดเงินของการชำระ (หน่วยย่อย)
+		amount := p.Amount_id
+		ledger, ok := posted[p.ID]
+		status := "matched"
+		switch {
+		case !ok:
+			status = "unposted"
+		case ledger != amount:
+			status = "mismatch"
+		}
+		if status != "matched" {
+			mismatches++
+		}
+		rows = append(rows, gin.H{
+			"payment_detail_id": p.ID,
+			"payment_number":    p.PatmentNumber,
+			"payment_date":      p.Payment_date,
+			"amount":            amount,
+			"ledger_amount":     ledger,
+			"difference":        amount - ledger,
+			"status":            status,
+		})
+	}
+
+	return gin.H{"data": rows, "mismatches": mismatches}, nil
+}
+
+// GET /guide/:id/statement?from=YYYY-MM-DD&to=YYYY-MM-DD
+// รายการค่าไกด์ (guide_payable) พร้อมยอดคงเหลือสะสม
+func GetGuideStatement(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid guide ID format"))
+		return
+	}
+	from, err := parseYMD(c.Query("from"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("from must be YYYY-MM-DD"))
+		return
+	}
+	to, err := parseYMD(c.Query("to"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("to must be YYYY-MM-DD"))
+		return
+	}
+
+	db := dbFor(c)
+	var guide entity.Guide
+	if err := db.First(&guide, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("guide not found"))
+		return
+	}
+
+	base := db.Model(&entity.LedgerEntry{}).
+		Where("account = ? AND guide_id = ?", entity.AccountGuidePayable, guide.ID)
+
+	// ยอดยกมาก่อนช่วงที่ขอ
+	var opening money.Money
+	if !from.IsZero() {
+		if err := base.Session(&gorm.Session{}).
+			Select("COALESCE(SUM(credit) - SUM(debit), 0)").
+			Where("created_at < ?", from).
+			Scan(&opening).Error; err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+	}
+
+	q := base.Session(&gorm.Session{}).Order("created_at, id")
+	if !from.IsZero() {
+		q = q.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		q = q.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+	var entries []entity.LedgerEntry
+	if err := q.Find(&entries).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+
+	type statementLine struct {
+		Date          time.Time   `json:"date"`
+		TxRef         string      `json:"tx_ref"`
+		Memo          string      `json:"memo"`
+		ReservationID *uint       `json:"reservation_id"`
+		PayoutBatchID *uint       `json:"payout_batch_id"`
+		Earned        money.Money `json:"earned"`
+		Paid          money.Money `json:"paid"`
+		Balance       money.Money `json:"balance"`
+	}
+	lines := make([]statementLine, 0, len(entries))
+	balance := opening
+	var earned, paid money.Money
+	for _, e := range entries {
+		balance += e.Credit - e.Debit
+		earned += e.Credit
+		paid += e.Debit
+		lines = append(lines, statementLine{
+			Date: e.CreatedAt, TxRef: e.TxRef, Memo: e.Memo,
+			ReservationID: e.ReservationID, PayoutBatchID: e.PayoutBatchID,
+			Earned: e.Credit, Paid: e.Debit, Balance: balance,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"guide_id":        guide.ID,
+		"opening_balance": opening,
+		"earned":          earned,
+		"paid":            paid,
+		"closing_balance": balance,
+		"lines":           lines,
+	}})
+}
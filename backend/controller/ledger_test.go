@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+// ค่าคอมปัดเศษครึ่งขึ้นเป็นหน่วยย่อย และค่าคอม + ค่าไกด์ = ยอดเต็มเสมอ
+func TestSplitCommissionRoundsHalfUp(t *testing.T) {
+	cases := []struct {
+		gross      money.Money
+		rateBP     int64
+		commission money.Money
+	}{
+		{100000, 2000, 20000},
+		{1, 5000, 1},          // 0.5 ขึ้น
+		{3, 5000, 2},          // 1.5 ขึ้น
+		{4, 1000, 0},          // 0.4 ลง
+		{5, 1000, 1},          // 0.5 ขึ้น
+		{199999, 1250, 25000}, // 24999.875
+		{1000, 0, 0},
+		{1000, 10000, 1000},
+	}
+	for _, tc := range cases {
+		commission, fee := splitCommission(tc.gross, tc.rateBP)
+		if commission != tc.commission {
+			t.Errorf("splitCommission(%d, %d) commission = %d, want %d", tc.gross, tc.rateBP, commission, tc.commission)
+		}
+		if commission+fee != tc.gross {
+			t.Errorf("splitCommission(%d, %d) = %d + %d, want sum %d", tc.gross, tc.rateBP, commission, fee, tc.gross)
+		}
+	}
+}
+
+// ไกด์หลายประเภทใช้ rate สูงสุด ไม่มีกฎใช้ defaultCommissionBP
+func TestCommissionRateForUsesHighestRule(t *testing.T) {
+	db := openTestDB(t)
+	member := testMember(t, db, 1)
+	cheap := entity.GuideType{Name: "city", Description: "city"}
+	dear := entity.GuideType{Name: "trek", Description: "trek"}
+	noRule := entity.GuideType{Name: "boat", Description: "boat"}
+	mustCreate(t, db, &cheap)
+	mustCreate(t, db, &dear)
+	mustCreate(t, db, &noRule)
+	mustCreate(t, db, &entity.CommissionRule{GuideTypeID: cheap.ID, RateBP: 1500})
+	mustCreate(t, db, &entity.CommissionRule{GuideTypeID: dear.ID, RateBP: 3000})
+
+	both := entity.Guide{MemberID: member.ID, GuideType: []entity.GuideType{cheap, dear}}
+	untyped := entity.Guide{MemberID: member.ID}
+	unruled := entity.Guide{MemberID: member.ID, GuideType: []entity.GuideType{noRule}}
+	mustCreate(t, db, &both)
+	mustCreate(t, db, &untyped)
+	mustCreate(t, db, &unruled)
+
+	for _, tc := range []struct {
+		name  string
+		guide uint
+		want  int64
+	}{
+		{"highest of two rules", both.ID, 3000},
+		{"no guide type", untyped.ID, defaultCommissionBP},
+		{"guide type without rule", unruled.ID, defaultCommissionBP},
+	} {
+		got, err := commissionRateFor(db, tc.guide)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: rate = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+// ledgerTotals คือยอด debit/credit รวมของรายการที่ตรงเงื่อนไข
+func ledgerTotals(t *testing.T, db *gorm.DB, where string, args ...any) (debit, credit money.Money) {
+	t.Helper()
+	var sum struct{ Debit, Credit money.Money }
+	if err := db.Model(&entity.LedgerEntry{}).
+		Select("COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit").
+		Where(where, args...).Scan(&sum).Error; err != nil {
+		t.Fatal(err)
+	}
+	return sum.Debit, sum.Credit
+}
+
+// ลงบัญชีแล้วยอดแต่ละ TxRef สมดุล ยกเลิกแล้วกลับรายการจนทุกบัญชีเป็น 0
+// และยกเลิกไม่ได้เมื่อค่าไกด์เข้ารอบจ่ายแล้ว
+func TestReservationLedgerBalancesThroughCancel(t *testing.T) {
+	db := openTestDB(t)
+	member := testMember(t, db, 1)
+	guideType := entity.GuideType{Name: "city", Description: "city"}
+	mustCreate(t, db, &guideType)
+	mustCreate(t, db, &entity.CommissionRule{GuideTypeID: guideType.ID, RateBP: 2500})
+	guide := entity.Guide{MemberID: member.ID, GuideType: []entity.GuideType{guideType}}
+	mustCreate(t, db, &guide)
+	pack := entity.Package{Name: "Doi Suthep", People: 10, GuideID: &guide.ID}
+	mustCreate(t, db, &pack)
+
+	newReservation := func() entity.Reservation {
+		r := entity.Reservation{Status: "confirmed", MemberID: member.ID}
+		mustCreate(t, db, &r)
+		mustCreate(t, db, &entity.ReservationItem{ReservationID: r.ID, ItemType: "package", PackageID: &pack.ID, Total: 33333})
+		mustCreate(t, db, &entity.ReservationItem{ReservationID: r.ID, ItemType: "room", Total: 10001})
+		return r
+	}
+
+	r := gin.New()
+	r.POST("/ledger/reservation/:id", PostReservationToLedger)
+	r.POST("/reservation/:id/cancel", CancelReservation)
+	r.POST("/payout-batch", CreatePayoutBatch)
+
+	first := newReservation()
+	if code, body := doJSON(t, r, http.MethodPost, "/ledger/reservation/1", nil); code != http.StatusCreated {
+		t.Fatalf("post ledger = %d %s", code, body)
+	}
+	debit, credit := ledgerTotals(t, db, "tx_ref = ?", "RSV-1")
+	if debit != 43334 || credit != debit {
+		t.Fatalf("RSV-1 debit/credit = %d/%d, want 43334 each", debit, credit)
+	}
+	// 33333 × 25% = 8333.25 → 8333 ค่าไกด์ 25000 ห้องพักไม่มีไกด์เป็นรายได้แพลตฟอร์มทั้งหมด
+	if _, got := ledgerTotals(t, db, "account = ?", entity.AccountGuidePayable); got != 25000 {
+		t.Errorf("guide_payable credit = %d, want 25000", got)
+	}
+	if _, got := ledgerTotals(t, db, "account = ?", entity.AccountPlatformCommission); got != 8333+10001 {
+		t.Errorf("platform_commission credit = %d, want %d", got, 8333+10001)
+	}
+
+	if code, body := doJSON(t, r, http.MethodPost, "/reservation/1/cancel", nil); code != http.StatusOK {
+		t.Fatalf("cancel = %d %s", code, body)
+	}
+	debit, credit = ledgerTotals(t, db, "tx_ref = ?", "RSV-1-REV")
+	if debit != 43334 || credit != debit {
+		t.Errorf("RSV-1-REV debit/credit = %d/%d, want 43334 each", debit, credit)
+	}
+	for _, account := range []string{entity.AccountCash, entity.AccountPlatformCommission, entity.AccountGuidePayable} {
+		if debit, credit := ledgerTotals(t, db, "account = ? AND reservation_id = ?", account, first.ID); debit != credit {
+			t.Errorf("%s after cancel: debit %d, credit %d", account, debit, credit)
+		}
+	}
+
+	// การจองที่สอง: ค่าไกด์เข้ารอบจ่ายแล้ว ยกเลิกไม่ได้ และรอบจ่ายไม่รวมค่าไกด์ที่กลับรายการไปแล้ว
+	newReservation()
+	if code, body := doJSON(t, r, http.MethodPost, "/ledger/reservation/2", nil); code != http.StatusCreated {
+		t.Fatalf("post ledger = %d %s", code, body)
+	}
+	if code, body := doJSON(t, r, http.MethodPost, "/payout-batch", map[string]any{"period_end": "2999-12-31"}); code != http.StatusCreated {
+		t.Fatalf("payout batch = %d %s", code, body)
+	}
+	var batch entity.PayoutBatch
+	if err := db.First(&batch).Error; err != nil {
+		t.Fatal(err)
+	}
+	if batch.Total != 25000 {
+		t.Errorf("payout batch total = %d, want 25000 (the cancelled reservation is netted out)", batch.Total)
+	}
+	if code, body := doJSON(t, r, http.MethodPost, "/reservation/2/cancel", nil); code != http.StatusConflict {
+		t.Errorf("cancel after payout batch = %d %s, want 409", code, body)
+	}
+}
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/middlewares"
+)
+
+// GET /legacy-usage - ยอดเรียก route เดิม (ไม่มี /api/v1) แยกตาม client ใช้ดูว่าปิด LEGACY_ROUTES ได้หรือยัง
+func FindLegacyUsage(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data":    middlewares.LegacyUsage(),
+		"enabled": config.LegacyRoutesEnabled(),
+		"sunset":  config.LegacyRoutesSunset().Format("2006-01-02"),
+	})
+}
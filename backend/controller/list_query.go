@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/middlewares"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 500
+)
+
+// listSpec บอกว่า list endpoint หนึ่งเรียงและกรองด้วยคอลัมน์ไหนได้บ้าง (นอก whitelist = 400 / ไม่สนใจ)
+type listSpec struct {
+	table   string
+	sorts   map[string]string // ชื่อใน ?sort= → คอลัมน์
+	filters map[string]string // query param → คอลัมน์ (ค่าคั่นด้วย , = อันใดอันหนึ่ง)
+	// defaultSort ใช้เมื่อไม่ส่ง ?sort= (ว่าง = id จากน้อยไปมาก)
+	defaultSort string
+}
+
+// listPage คือข้อมูลหน้าที่ส่งคู่กับ data
+type listPage struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+}
+
+// sortKey คือคอลัมน์ 1 ตัวใน ?sort= (id ต่อท้ายเสมอให้ลำดับนิ่ง)
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// listCursor คือค่าคอลัมน์ที่เรียงของแถวสุดท้าย หน้าถัดไปเริ่มหลังแถวนี้ (keyset ไม่ช้าลงเมื่อหน้าลึก)
+type listCursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// ตรงกับรูปแบบที่ driver sqlite เก็บ time.Time เทียบเป็นข้อความได้ตรง
+const cursorTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+func (s listSpec) parseSort(raw string) ([]sortKey, error) {
+	var keys []sortKey
+	hasID := false
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		desc := strings.HasPrefix(f, "-")
+		name := strings.TrimPrefix(f, "-")
+		col, ok := s.sorts[name]
+		if !ok && name == "id" {
+			col, ok = "id", true
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %s", name)
+		}
+		hasID = hasID || col == "id"
+		keys = append(keys, sortKey{col, desc})
+	}
+	if !hasID {
+		keys = append(keys, sortKey{"id", false})
+	}
+	return keys, nil
+}
+
+// sortNames คือชื่อที่ใช้ใน ?sort= ได้ (เรียงตามตัวอักษร รวม id)
+func (s listSpec) sortNames() []string {
+	names := []string{"id"}
+	for name := range s.sorts {
+		if name != "id" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s listSpec) column(col string) string { return s.table + "." + col }
+
+// find คืนหน้าหนึ่งของ dest (*[]T) ตาม ?page=&page_size= หรือ ?cursor= พร้อม ?sort= และตัวกรองใน spec
+// route เดิมที่ไม่ระบุทั้งสามค่าจะคืนทุกแถว (frontend เดิมไม่ถูกตัดที่ defaultPageSize)
+// db คือ query ที่ใส่เงื่อนไขของ handler แล้ว preload ใส่ทีหลังเพื่อไม่ให้ไปปนกับ count
+// ค่าที่ผู้ใช้ส่งผิดตอบ 400 ให้แล้ว ok = false
+func (s listSpec) find(c *gin.Context, db *gorm.DB, dest any, preload ...func(*gorm.DB) *gorm.DB) (*listPage, bool) {
+	db = db.WithContext(c.Request.Context()) // log ของ query มี request_id
+	bad := func(msg string) (*listPage, bool) {
+		apierr.Respond(c, apierr.BadRequest(msg))
+		return nil, false
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || size < 1 || size > maxPageSize {
+		return bad(fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return bad("page must be a positive number")
+	}
+	sortRaw := c.DefaultQuery("sort", s.defaultSort)
+	keys, err := s.parseSort(sortRaw)
+	if err != nil {
+		apierr.Respond(c, apierr.Invalid("sort is invalid", apierr.FieldError{
+			Field: "sort", Code: "invalid", Message: "must be a comma-separated list of " + strings.Join(s.sortNames(), ", "),
+		}).WithCause(err))
+		return nil, false
+	}
+
+	for param, col := range s.filters {
+		if v := c.Query(param); v != "" {
+			if vals := strings.Split(v, ","); len(vals) > 1 {
+				db = db.Where(s.column(col)+" IN ?", vals)
+			} else {
+				db = db.Where(s.column(col)+" = ?", v)
+			}
+		}
+	}
+
+	out := &listPage{PageSize: size}
+	if err := db.Session(&gorm.Session{}).Model(dest).Count(&out.Total).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return nil, false
+	}
+
+	// route เดิมที่ไม่ส่ง page / page_size / cursor เลย: frontend ดึงทั้งหมดแล้วแบ่งหน้าเอง คืนทุกแถวในหน้าเดียว
+	if c.GetBool(middlewares.LegacyRouteKey) && c.Query("page") == "" && c.Query("page_size") == "" && c.Query("cursor") == "" {
+		q := s.order(db, keys)
+		for _, p := range preload {
+			q = p(q)
+		}
+		if err := q.Find(dest).Error; err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return nil, false
+		}
+		out.Page, out.PageSize = 1, int(out.Total)
+		if out.Total > 0 {
+			out.TotalPages = 1
+		}
+		return out, true
+	}
+	out.TotalPages = (out.Total + int64(size) - 1) / int64(size)
+
+	q := db
+	if raw := c.Query("cursor"); raw != "" {
+		cur, err := decodeCursor(raw)
+		if err != nil || cur.Sort != sortRaw || len(cur.Values) != len(keys) {
+			return bad("invalid cursor")
+		}
+		where, args := s.afterCursor(keys, cur.Values)
+		q = q.Where(where, args...)
+	} else {
+		out.Page = page
+		q = q.Offset((page - 1) * size)
+	}
+	q = s.order(q, keys)
+	for _, p := range preload {
+		q = p(q)
+	}
+	// ดึงเกิน 1 แถวเพื่อรู้ว่ายังมีหน้าถัดไป
+	if err := q.Limit(size + 1).Find(dest).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return nil, false
+	}
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Len() <= size {
+		return out, true
+	}
+	rows.Set(rows.Slice(0, size))
+
+	last := rows.Index(size - 1).FieldByName("ID").Interface()
+	values, err := s.sortValues(db.Session(&gorm.Session{NewDB: true}), keys, last)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return nil, false
+	}
+	out.NextCursor = encodeCursor(listCursor{Sort: sortRaw, Values: values})
+	next := *c.Request.URL
+	query := next.Query()
+	query.Del("page")
+	query.Set("cursor", out.NextCursor)
+	next.RawQuery = query.Encode()
+	out.Next = next.RequestURI()
+	return out, true
+}
+
+// order เรียง q ตาม keys
+func (s listSpec) order(q *gorm.DB, keys []sortKey) *gorm.DB {
+	for _, k := range keys {
+		if k.desc {
+			q = q.Order(s.column(k.column) + " DESC")
+		} else {
+			q = q.Order(s.column(k.column))
+		}
+	}
+	return q
+}
+
+// afterCursor สร้างเงื่อนไข "อยู่หลังแถวใน cursor" ตามทิศของแต่ละคอลัมน์
+// (a > ?) OR (a = ? AND b > ?) OR ...
+func (s listSpec) afterCursor(keys []sortKey, values []any) (string, []any) {
+	var ors []string
+	var args []any
+	for i, k := range keys {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, s.column(keys[j].column)+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if k.desc {
+			op = " < ?"
+		}
+		ands = append(ands, s.column(k.column)+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// sortValues อ่านค่าคอลัมน์ที่เรียงของแถว id ตรงจากตาราง (field ใน struct อาจถูกแปลงแล้ว เช่น ราคาตามสกุลเงิน)
+func (s listSpec) sortValues(db *gorm.DB, keys []sortKey, id any) ([]any, error) {
+	cols := make([]string, len(keys))
+	for i, k := range keys {
+		cols[i] = k.column
+	}
+	rows, err := db.Table(s.table).Select(cols).Where("id = ?", id).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := make([]any, len(keys))
+	ptrs := make([]any, len(keys))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+	}
+	for i, v := range values {
+		switch t := v.(type) {
+		case time.Time:
+			values[i] = t.Format(cursorTimeFormat)
+		case []byte:
+			values[i] = string(t)
+		}
+	}
+	return values, rows.Err()
+}
+
+func encodeCursor(cur listCursor) string {
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(raw string) (listCursor, error) {
+	var cur listCursor
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(b, &cur)
+	return cur, err
+}
@@ -1,26 +1,30 @@
 package controller
 
 import (
-	"net/http"
-	"github.com/kookkikiv/sa_project/backend/config"
-	"github.com/kookkikiv/sa_project/backend/entity"
 	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"gorm.io/gorm"
+	"net/http"
 )
 
-// GET /provinces
+// activeGeography ซ่อนจังหวัด/อำเภอ/ตำบลที่เลิกใช้แล้ว เว้นแต่ ?include_retired=true (เช่น หน้าแก้ข้อมูลเก่า)
+func activeGeography(c *gin.Context) *gorm.DB {
+	db := dbFor(c)
+	if c.Query("include_retired") != "true" {
+		db = db.Where("retired_at IS NULL")
+	}
+	return db
+}
+
+// GET /provinces?include_retired=true
 func FindProvinces(c *gin.Context) {
 	var provinces []entity.Province
-	
-	if err := config.DB().Find(&provinces).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
 
-	// Debug log ให้ดูข้อมูลที่ส่งออกไป
-	for i, p := range provinces {
-		if i < 3 { // แสดงแค่ 3 ตัวแรก
-			println("Province debug:", p.ID, p.NameTh, p.NameEn, p.ProvinceCode)
-		}
+	if err := activeGeography(c).Find(&provinces).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": provinces})
@@ -31,15 +35,16 @@ func CreateProvince(c *gin.Context) {
 	var province entity.Province
 
 	if err := c.ShouldBindJSON(&province); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
-	if err := config.DB().Create(&province).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create province: " + err.Error()})
+	if err := dbFor(c).Create(&province).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create province"))
 		return
 	}
 
+	resetSearchLexicon() // ชื่อพื้นที่ใหม่ใช้ค้นหา/ตัดคำได้ทันที
 	c.JSON(http.StatusCreated, gin.H{"data": province, "message": "Province created successfully"})
 }
 
@@ -48,29 +53,22 @@ func FindDistricts(c *gin.Context) {
 	var districts []entity.District
 	provinceId := c.Query("province_id")
 
-	db := config.DB()
+	db := activeGeography(c)
 
 	if provinceId != "" {
 		if err := db.Where("province_id = ?", provinceId).Find(&districts).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 			return
 		}
 	} else {
 		if err := db.Find(&districts).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 			return
 		}
 	}
 
-	// Debug log
-	if provinceId != "" {
-		println("Districts for province", provinceId, "count:", len(districts))
-		for i, d := range districts {
-			if i < 3 {
-				println("District debug:", d.ID, d.NameTh, d.NameEn, d.DistrictCode)
-			}
-		}
-	}
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).DebugContext(ctx, "districts", "province_id", provinceId, "count", len(districts))
 
 	c.JSON(http.StatusOK, gin.H{"data": districts})
 }
@@ -80,48 +78,47 @@ func CreateDistrict(c *gin.Context) {
 	var district entity.District
 
 	if err := c.ShouldBindJSON(&district); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	// จังหวัดต้องมีอยู่และยังไม่เลิกใช้
+	if district.ProvinceID == 0 {
+		apierr.Respond(c, apierr.Required("province_id"))
+		return
+	}
+	if _, err := checkGeoHierarchy(dbFor(c), geoRef{ProvinceID: &district.ProvinceID}, geoRef{}); err != nil {
+		apierr.Respond(c, err)
 		return
 	}
 
-	if err := config.DB().Create(&district).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create district: " + err.Error()})
+	if err := dbFor(c).Create(&district).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create district"))
 		return
 	}
 
+	resetSearchLexicon()
 	c.JSON(http.StatusCreated, gin.H{"data": district, "message": "District created successfully"})
 }
 
-// GET /subdistricts
+var subdistrictList = listSpec{
+	table: "subdistricts",
+	sorts: map[string]string{"code": "subdistrict_code", "name_th": "name_th", "name_en": "name_en",
+		"postal_code": "zip_code"},
+	filters: map[string]string{"district_id": "district_id", "postal_code": "zip_code"},
+}
+
+// GET /subdistricts?district_id=&postal_code=&page=&page_size=&sort= (ไม่ระบุอำเภอก็ได้ทีละหน้า ไม่ใช่ทั้ง 7 พันกว่าตำบล)
 func FindSubdistricts(c *gin.Context) {
 	var subdistricts []entity.Subdistrict
-	districtId := c.Query("district_id")
-
-	db := config.DB()
-
-	if districtId != "" {
-		if err := db.Where("district_id = ?", districtId).Find(&subdistricts).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-	} else {
-		if err := db.Find(&subdistricts).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	page, ok := subdistrictList.find(c, activeGeography(c), &subdistricts)
+	if !ok {
+		return
 	}
 
-	// Debug log
-	if districtId != "" {
-		println("Subdistricts for district", districtId, "count:", len(subdistricts))
-		for i, s := range subdistricts {
-			if i < 3 {
-				println("Subdistrict debug:", s.ID, s.NameTh, s.NameEn, s.SubdistrictCode)
-			}
-		}
-	}
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).DebugContext(ctx, "subdistricts", "district_id", c.Query("district_id"), "count", len(subdistricts))
 
-	c.JSON(http.StatusOK, gin.H{"data": subdistricts})
+	c.JSON(http.StatusOK, gin.H{"data": subdistricts, "pagination": page})
 }
 
 // POST /subdistricts - สำหรับสร้างตำบลใหม่
@@ -129,14 +126,71 @@ func CreateSubdistrict(c *gin.Context) {
 	var subdistrict entity.Subdistrict
 
 	if err := c.ShouldBindJSON(&subdistrict); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	// อำเภอต้องมีอยู่และยังไม่เลิกใช้
+	if subdistrict.DistrictID == 0 {
+		apierr.Respond(c, apierr.Required("districtID"))
+		return
+	}
+	if _, err := checkGeoHierarchy(dbFor(c), geoRef{DistrictID: &subdistrict.DistrictID}, geoRef{}); err != nil {
+		apierr.Respond(c, err)
 		return
 	}
 
-	if err := config.DB().Create(&subdistrict).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subdistrict: " + err.Error()})
+	if err := dbFor(c).Create(&subdistrict).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create subdistrict"))
 		return
 	}
 
+	resetSearchLexicon()
 	c.JSON(http.StatusCreated, gin.H{"data": subdistrict, "message": "Subdistrict created successfully"})
-}
\ No newline at end of file
+}
+
+var locationList = listSpec{
+	table:   "locations",
+	sorts:   map[string]string{"name": "name", "created_at": "created_at"},
+	filters: map[string]string{"province_id": "province_id"},
+}
+
+// GET /location/places?province_id=&page=&page_size=&cursor=&sort= - สถานที่ (จุดนัดพบ/สถานที่จัดงาน) พร้อมพิกัด
+func FindLocations(c *gin.Context) {
+	var locations []entity.Location
+	page, ok := locationList.find(c, dbFor(c), &locations)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": locations, "pagination": page})
+}
+
+// POST /location/places - สร้างสถานที่ (latitude/longitude ไม่บังคับ แต่ต้องส่งคู่กัน)
+func CreateLocation(c *gin.Context) {
+	var location entity.Location
+
+	if err := c.ShouldBindJSON(&location); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	if location.Name == "" {
+		apierr.Respond(c, apierr.Required("name"))
+		return
+	}
+	if err := checkCoordinates(location.Latitude, location.Longitude); err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+	geo, err := checkGeoHierarchy(dbFor(c), geoRef{ProvinceID: location.ProvinceID}, geoRef{})
+	if err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+	location.ProvinceID = geo.ProvinceID
+
+	if err := dbFor(c).Create(&location).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create location"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": location, "message": "Location created successfully"})
+}
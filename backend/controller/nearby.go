@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/geo"
+	"gorm.io/gorm"
+)
+
+const (
+	nearbyAccommodation = "accommodation"
+	nearbyEvent         = "event"
+	nearbyLocation      = "location"
+
+	nearbyDefaultRadiusKm = 10.0
+	nearbyMaxRadiusKm     = 500.0
+)
+
+// nearbySources คือ SQL ดึงรายการที่มีพิกัด ที่พัก/อีเวนต์ที่ไม่มีพิกัดของตัวเองใช้พิกัดของ Location
+var nearbySources = map[string]string{
+	nearbyAccommodation: `SELECT a.id, a.name,
+			COALESCE(a.latitude, l.latitude) AS latitude, COALESCE(a.longitude, l.longitude) AS longitude
+		FROM accommodations a LEFT JOIN locations l ON l.id = a.location_id AND l.deleted_at IS NULL
+		WHERE a.deleted_at IS NULL`,
+	nearbyEvent: `SELECT e.id, e.event_name AS name,
+			COALESCE(e.latitude, l.latitude) AS latitude, COALESCE(e.longitude, l.longitude) AS longitude
+		FROM events e LEFT JOIN locations l ON l.id = e.location_id AND l.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL`,
+	nearbyLocation: `SELECT id, name, latitude, longitude FROM locations WHERE deleted_at IS NULL`,
+}
+
+var nearbyTypes = []string{nearbyAccommodation, nearbyEvent, nearbyLocation}
+
+// nearbyHit คือรายการใกล้จุดที่ค้น เรียงตามระยะทาง
+type nearbyHit struct {
+	Type       string  `json:"type"`
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// checkCoordinates ตรวจพิกัดที่ส่งมา ต้องส่งมาคู่กันและอยู่ในช่วงที่ถูกต้อง (ไม่ส่งทั้งคู่ = ไม่มีพิกัด)
+func checkCoordinates(lat, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return errQuote{"latitude and longitude must be given together"}
+	}
+	if lat != nil && !(geo.Point{Lat: *lat, Lng: *lng}).Valid() {
+		return errQuote{"latitude must be between -90 and 90 and longitude between -180 and 180"}
+	}
+	return nil
+}
+
+// pointOf คืนพิกัดของรายการ (ใช้ของ Location ถ้ารายการไม่มีพิกัดเอง) ไม่พบรายการคืน gorm.ErrRecordNotFound
+func pointOf(tx *gorm.DB, typ string, id uint) (geo.Point, error) {
+	var row struct {
+		Latitude  *float64
+		Longitude *float64
+	}
+	res := tx.Raw(`SELECT latitude, longitude FROM (`+nearbySources[typ]+`) WHERE id = ?`, id).Scan(&row)
+	if res.Error != nil {
+		return geo.Point{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return geo.Point{}, gorm.ErrRecordNotFound
+	}
+	if row.Latitude == nil || row.Longitude == nil {
+		return geo.Point{}, errQuote{strings.ToUpper(typ[:1]) + typ[1:] + " has no coordinates"}
+	}
+	return geo.Point{Lat: *row.Latitude, Lng: *row.Longitude}, nil
+}
+
+// findNearby ดึงรายการประเภท typ ในกรอบรอบ center แล้วกรองด้วยระยะ haversine จริง
+func findNearby(tx *gorm.DB, typ string, center geo.Point, radiusKm float64) ([]nearbyHit, error) {
+	box := geo.BoundingBox(center, radiusKm)
+	var rows []nearbyHit
+	if err := tx.Raw(`SELECT * FROM (`+nearbySources[typ]+`)
+		WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?`,
+		box.MinLat, box.MaxLat, box.MinLng, box.MaxLng).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	hits := rows[:0]
+	for _, r := range rows {
+		r.Type = typ
+		r.DistanceKm = geo.Distance(center, geo.Point{Lat: r.Latitude, Lng: r.Longitude})
+		if r.DistanceKm <= radiusKm {
+			hits = append(hits, r)
+		}
+	}
+	return hits, nil
+}
+
+// GET /nearby?lat=&lng=&radius_km=&type=&limit=&offset= - ที่พัก/อีเวนต์/สถานที่ใกล้จุดที่กำหนด เรียงจากใกล้ไปไกล
+// ใช้ event_id / accommodation_id / location_id แทน lat,lng ได้ เช่น หาที่พักใกล้สถานที่จัดงาน
+func FindNearby(c *gin.Context) {
+	db := dbFor(c)
+
+	var center geo.Point
+	var exclude struct {
+		typ string
+		id  uint
+	}
+	switch {
+	case c.Query("lat") != "" || c.Query("lng") != "":
+		lat, err1 := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, err2 := strconv.ParseFloat(c.Query("lng"), 64)
+		center = geo.Point{Lat: lat, Lng: lng}
+		if err1 != nil || err2 != nil || !center.Valid() {
+			apierr.Respond(c, apierr.BadRequest("lat and lng must be valid coordinates"))
+			return
+		}
+	default:
+		for _, typ := range nearbyTypes {
+			v := c.Query(typ + "_id")
+			if v == "" {
+				continue
+			}
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				apierr.Respond(c, apierr.BadRequest("Invalid "+typ+" ID format"))
+				return
+			}
+			p, err := pointOf(db, typ, uint(id))
+			if err == gorm.ErrRecordNotFound {
+				apierr.Respond(c, apierr.NotFound(strings.ToUpper(typ[:1])+typ[1:]+" not found"))
+				return
+			}
+			if err != nil {
+				apierr.Respond(c, err)
+				return
+			}
+			center, exclude.typ, exclude.id = p, typ, uint(id)
+			break
+		}
+		if exclude.typ == "" {
+			apierr.Respond(c, apierr.BadRequest("lat and lng (or event_id, accommodation_id, location_id) are required"))
+			return
+		}
+	}
+
+	radius := nearbyDefaultRadiusKm
+	if v := c.Query("radius_km"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || r > nearbyMaxRadiusKm {
+			apierr.Respond(c, apierr.BadRequest("radius_km must be greater than 0 and at most 500"))
+			return
+		}
+		radius = r
+	}
+	types := nearbyTypes
+	if v := strings.ToLower(c.Query("type")); v != "" {
+		if _, ok := nearbySources[v]; !ok {
+			apierr.Respond(c, apierr.BadRequest("type must be accommodation, event or location"))
+			return
+		}
+		types = []string{v}
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		apierr.Respond(c, apierr.BadRequest("limit must be between 1 and 100"))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		apierr.Respond(c, apierr.BadRequest("offset must not be negative"))
+		return
+	}
+
+	hits := []nearbyHit{}
+	for _, typ := range types {
+		found, err := findNearby(db, typ, center, radius)
+		if err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+		for _, h := range found {
+			if h.Type == exclude.typ && h.ID == exclude.id {
+				continue
+			}
+			hits = append(hits, h)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].DistanceKm < hits[j].DistanceKm })
+
+	total := len(hits)
+	if offset > len(hits) {
+		offset = len(hits)
+	}
+	hits = hits[offset:]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hits, "total": total, "center": center, "radius_km": radius})
+}
+
+// CoordinatesRequest คือพิกัดที่ตั้งให้รายการ ส่ง null ทั้งคู่เพื่อลบพิกัด
+type CoordinatesRequest struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// setCoordinates ตั้งพิกัดของรายการตาม :id
+func setCoordinates(c *gin.Context, label string, model any) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid "+label+" ID format"))
+		return
+	}
+	var req CoordinatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	if err := checkCoordinates(req.Latitude, req.Longitude); err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+
+	db := dbFor(c)
+	if err := db.First(model, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound(strings.ToUpper(label[:1])+label[1:]+" not found"))
+		return
+	}
+	if err := db.Model(model).Updates(map[string]any{"latitude": req.Latitude, "longitude": req.Longitude}).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update coordinates"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": req, "message": "Coordinates updated successfully"})
+}
+
+// PUT /accommodation/:id/coordinates
+func UpdateAccommodationCoordinates(c *gin.Context) {
+	setCoordinates(c, "accommodation", &entity.Accommodation{})
+}
+
+// PUT /event/:id/coordinates - พิกัดสถานที่จัดงาน
+func UpdateEventCoordinates(c *gin.Context) { setCoordinates(c, "event", &entity.Event{}) }
+
+// PUT /location/places/:id/coordinates
+func UpdateLocationCoordinates(c *gin.Context) { setCoordinates(c, "location", &entity.Location{}) }
@@ -0,0 +1,365 @@
+package controller
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/middlewares"
+	"github.com/kookkikiv/sa_project/backend/openapi"
+)
+
+// apiDoc อธิบาย handler หนึ่งสำหรับเอกสาร OpenAPI
+// handler เดียวกันที่ผูกไว้ทั้ง /api/v1 และ route legacy ใช้ entry เดียวกัน
+type apiDoc struct {
+	summary  string
+	tag      string
+	query    []string       // query param เพิ่มเติม (ทุกตัวไม่บังคับ)
+	body     any            // ค่าตัวอย่างของ request body เช่น PackageRequest{}
+	data     any            // ค่าใน "data" ของคำตอบ
+	extra    map[string]any // field ระดับบนอื่นนอกจาก data (ชื่อ → ค่าตัวอย่าง)
+	list     *listSpec      // list ที่แบ่งหน้า: เพิ่ม page/page_size/cursor/sort/ตัวกรอง และ pagination ในคำตอบ
+	status   int            // status เมื่อสำเร็จ (ค่าเริ่มต้น 200)
+	upload   bool           // รับ multipart/form-data แทน JSON
+	rawReply bool           // คำตอบไม่ได้ห่อด้วย data (เช่นตัวเอกสารเอง)
+}
+
+var (
+	handlerDocs = map[string]apiDoc{}
+	pathDocs    = map[string]apiDoc{} // route ที่ handler ไม่ได้อยู่ใน controller เช่นไฟล์ static
+)
+
+func handlerName(h gin.HandlerFunc) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
+
+func document(h gin.HandlerFunc, d apiDoc) { handlerDocs[handlerName(h)] = d }
+
+// DocumentPath บันทึกเอกสารของ route ที่ไม่ได้ใช้ handler ใน controller (key = "GET /uploads/*filepath")
+func DocumentPath(method, path, summary, tag string) {
+	pathDocs[method+" "+path] = apiDoc{summary: summary, tag: tag, rawReply: true}
+}
+
+// guideQuery คือตัวกรองของ guideFilterFromQuery
+var guideQuery = []string{"language", "language_id", "guide_type", "guide_type_id", "province_id", "district_id", "status"}
+
+// currencyQuery คือ ?currency= ของ endpoint ที่แสดงราคาหลายสกุล (currencyFromQuery)
+const currencyQuery = "currency"
+
+func init() {
+	// Location
+	document(FindProvinces, apiDoc{summary: "List provinces", tag: "location", query: []string{"include_retired"}, data: []entity.Province{}})
+	document(CreateProvince, apiDoc{summary: "Create a province", tag: "location", body: entity.Province{}, data: entity.Province{}, status: http.StatusCreated})
+	document(FindDistricts, apiDoc{summary: "List districts", tag: "location", query: []string{"province_id", "include_retired"}, data: []entity.District{}})
+	document(CreateDistrict, apiDoc{summary: "Create a district", tag: "location", body: entity.District{}, data: entity.District{}, status: http.StatusCreated})
+	document(FindSubdistricts, apiDoc{summary: "List subdistricts", tag: "location", query: []string{"district_id", "include_retired"}, list: &subdistrictList, data: []entity.Subdistrict{}})
+	document(CreateSubdistrict, apiDoc{summary: "Create a subdistrict", tag: "location", body: entity.Subdistrict{}, data: entity.Subdistrict{}, status: http.StatusCreated})
+	document(FindLocations, apiDoc{summary: "List places with coordinates", tag: "location", list: &locationList, data: []entity.Location{}})
+	document(CreateLocation, apiDoc{summary: "Create a place", tag: "location", body: entity.Location{}, data: entity.Location{}, status: http.StatusCreated})
+	document(UpdateLocationCoordinates, apiDoc{summary: "Set place coordinates", tag: "location", body: CoordinatesRequest{}, data: entity.Location{}})
+	document(LookupPostalCode, apiDoc{summary: "Look up subdistricts by postal code", tag: "location", query: []string{"postal_code"}, data: []addressTriple{}})
+	document(ParseAddress, apiDoc{summary: "Parse a free-text Thai address", tag: "location", body: AddressParseRequest{}, data: addressTriple{},
+		extra: map[string]any{"matched": []string{}, "confidence": "", "candidates": []addressCandidate{}}})
+
+	// Admin / auth
+	document(SignIn, apiDoc{summary: "Sign in", tag: "auth", body: SignInRequest{}, data: SignInResponse{}})
+	document(FindAdmin, apiDoc{summary: "List admins", tag: "admin", list: &adminList, data: []entity.Admin{}})
+	document(FindAdminById, apiDoc{summary: "Get an admin", tag: "admin", data: entity.Admin{}})
+	document(CreateAdmin, apiDoc{summary: "Create an admin (sign up)", tag: "admin", body: entity.Admin{}, data: map[string]any{}, status: http.StatusCreated})
+	document(UpdateAdminById, apiDoc{summary: "Update an admin", tag: "admin", body: entity.Admin{}, data: entity.Admin{}})
+	document(DeleteAdminById, apiDoc{summary: "Delete an admin", tag: "admin"})
+
+	// Accommodation / room / facility
+	document(FindAccommodation, apiDoc{summary: "List accommodations", tag: "accommodation", list: &accommodationList, data: []entity.Accommodation{}})
+	document(FindAccommodationId, apiDoc{summary: "Get an accommodation", tag: "accommodation", data: entity.Accommodation{}})
+	document(CreateAccommodation, apiDoc{summary: "Create an accommodation", tag: "accommodation", body: AccommodationCreateReq{}, data: entity.Accommodation{}, status: http.StatusCreated})
+	document(UpdateAccommodationById, apiDoc{summary: "Update an accommodation", tag: "accommodation", body: AccommodationUpdateReq{}, data: entity.Accommodation{}})
+	document(DeleteAccommodationById, apiDoc{summary: "Delete an accommodation", tag: "accommodation"})
+	document(UpdateAccommodationCoordinates, apiDoc{summary: "Set accommodation coordinates", tag: "accommodation", body: CoordinatesRequest{}, data: entity.Accommodation{}})
+	document(FindRoom, apiDoc{summary: "List rooms", tag: "room", query: []string{currencyQuery}, list: &roomList, data: []entity.Room{}})
+	document(FindRoomById, apiDoc{summary: "Get a room", tag: "room", query: []string{currencyQuery}, data: entity.Room{}})
+	document(CreateRoom, apiDoc{summary: "Create a room", tag: "room", body: RoomCreateReq{}, data: entity.Room{}, status: http.StatusCreated})
+	document(UpdateRoomById, apiDoc{summary: "Update a room", tag: "room", body: RoomUpdateReq{}, data: entity.Room{}})
+	document(DeleteRoomById, apiDoc{summary: "Delete a room", tag: "room"})
+	document(FindFacility, apiDoc{summary: "List facilities", tag: "facility", list: &facilityList, data: []entity.Facility{}})
+	document(FindFacilityById, apiDoc{summary: "Get a facility", tag: "facility", data: entity.Facility{}})
+	document(CreateFacility, apiDoc{summary: "Create a facility", tag: "facility", body: facilityInput{}, data: entity.Facility{}, status: http.StatusCreated})
+	document(UpdateFacilityById, apiDoc{summary: "Update a facility", tag: "facility", body: facilityInput{}, data: entity.Facility{}})
+	document(DeleteFacilityById, apiDoc{summary: "Delete a facility", tag: "facility"})
+
+	// Package / itinerary / event
+	document(FindPackage, apiDoc{summary: "List packages", tag: "package", query: []string{"accommodation_id", "room_id", currencyQuery}, list: &packageList, data: []entity.Package{}})
+	document(GetPackageStats, apiDoc{summary: "Package statistics", tag: "package", data: map[string]any{}})
+	document(SearchPackages, apiDoc{summary: "Search packages by name, province, dates and price", tag: "package",
+		query: []string{"name", "province_id", "start_date", "end_date", "min_price", "max_price", currencyQuery}, data: []entity.Package{}})
+	document(FindPackageById, apiDoc{summary: "Get a package", tag: "package", query: []string{currencyQuery}, data: entity.Package{}})
+	document(CreatePackage, apiDoc{summary: "Create a package", tag: "package", body: PackageRequest{}, data: entity.Package{}, status: http.StatusCreated})
+	document(UpdatePackageById, apiDoc{summary: "Update a package", tag: "package", body: PackageRequest{}, data: entity.Package{}})
+	document(DeletePackageById, apiDoc{summary: "Delete a package", tag: "package"})
+	document(FindPackageItinerary, apiDoc{summary: "Get a package itinerary", tag: "package", data: ItineraryResponse{}})
+	document(UpdatePackageItinerary, apiDoc{summary: "Replace a package itinerary", tag: "package", body: ItineraryRequest{}, data: ItineraryResponse{}})
+	document(FindPackageSeats, apiDoc{summary: "Seat usage of a package", tag: "package", data: seatUsage{}})
+	document(FindEvent, apiDoc{summary: "List events", tag: "event", query: []string{currencyQuery}, list: &eventList, data: []entity.Event{}})
+	document(FindEventById, apiDoc{summary: "Get an event", tag: "event", query: []string{currencyQuery}, data: entity.Event{}})
+	document(FindEventSeats, apiDoc{summary: "Seat usage of an event", tag: "event", data: seatUsage{}})
+	document(UpdateEventCoordinates, apiDoc{summary: "Set event coordinates", tag: "event", body: CoordinatesRequest{}, data: entity.Event{}})
+
+	// Guide
+	document(FindGuide, apiDoc{summary: "List guides", tag: "guide", query: guideQuery, list: &guideList, data: []GuideResponse{}})
+	document(SuggestAvailableGuides, apiDoc{summary: "Guides free for a date range", tag: "guide", query: append([]string{"start_date", "final_date", "package_id"}, guideQuery...), data: []GuideResponse{}})
+	document(FindGuideById, apiDoc{summary: "Get a guide", tag: "guide", data: GuideResponse{}})
+	document(CreateGuide, apiDoc{summary: "Create a guide", tag: "guide", body: GuideRequest{}, data: GuideResponse{}, status: http.StatusCreated})
+	document(UpdateGuideById, apiDoc{summary: "Update a guide", tag: "guide", body: GuideRequest{}, data: GuideResponse{}})
+	document(DeleteGuideById, apiDoc{summary: "Delete a guide", tag: "guide"})
+	document(FindGuideAvailability, apiDoc{summary: "Guide schedule (packages and blocked days)", tag: "guide", query: []string{"from", "to"}, data: map[string]any{}})
+	document(CreateGuideAvailability, apiDoc{summary: "Block days in a guide schedule", tag: "guide", body: GuideAvailabilityRequest{}, data: entity.GuideAvailability{}, status: http.StatusCreated})
+	document(DeleteGuideAvailability, apiDoc{summary: "Remove a blocked period", tag: "guide"})
+	document(GetGuideStatement, apiDoc{summary: "Guide earnings statement", tag: "payout", query: []string{"from", "to"}, data: map[string]any{}})
+
+	// Ledger / payout
+	document(FindLedgerEntries, apiDoc{summary: "List ledger entries", tag: "payout", query: []string{"account", "guide_id", "reservation_id", "tx_ref"}, data: []entity.LedgerEntry{}})
+	document(ReconcilePayments, apiDoc{summary: "Reconcile payments against the ledger", tag: "payout", query: []string{"from", "to"}, data: map[string]any{}})
+	document(EnqueueReconcilePayments, apiDoc{summary: "Reconcile payments in the background", tag: "payout", query: []string{"from", "to"}, data: jobView{}, status: http.StatusAccepted})
+	document(PostReservationToLedger, apiDoc{summary: "Post a reservation to the ledger", tag: "payout", body: PostReservationRequest{}, data: []entity.LedgerEntry{}, status: http.StatusCreated})
+	document(FindCommissionRules, apiDoc{summary: "List commission rules", tag: "payout", data: []entity.CommissionRule{}})
+	document(UpsertCommissionRule, apiDoc{summary: "Set the commission rule of a guide type", tag: "payout", body: CommissionRuleRequest{}, data: entity.CommissionRule{}})
+	document(FindPayoutBatches, apiDoc{summary: "List payout batches", tag: "payout", list: &payoutBatchList, data: []entity.PayoutBatch{}})
+	document(FindPayoutBatchById, apiDoc{summary: "Get a payout batch", tag: "payout", data: entity.PayoutBatch{}})
+	document(CreatePayoutBatch, apiDoc{summary: "Create a payout batch", tag: "payout", body: PayoutBatchRequest{}, data: entity.PayoutBatch{}, status: http.StatusCreated})
+	document(UpdatePayoutBatchStatus, apiDoc{summary: "Change payout batch status", tag: "payout", body: PayoutStatusRequest{}, data: entity.PayoutBatch{}})
+
+	// Pricing / cart / promotion / waitlist / booking
+	document(QuotePrice, apiDoc{summary: "Quote a price without saving", tag: "pricing", body: QuoteRequest{}, data: quotedItem{}})
+	document(FindPricingRules, apiDoc{summary: "List pricing rules", tag: "pricing", query: []string{"kind", "scope", "target_id"}, data: []entity.PricingRule{}})
+	document(CreatePricingRule, apiDoc{summary: "Create a pricing rule", tag: "pricing", body: PricingRuleRequest{}, data: entity.PricingRule{}, status: http.StatusCreated})
+	document(UpdatePricingRule, apiDoc{summary: "Update a pricing rule", tag: "pricing", body: PricingRuleRequest{}, data: entity.PricingRule{}})
+	document(DeletePricingRule, apiDoc{summary: "Delete a pricing rule", tag: "pricing"})
+	document(FindCart, apiDoc{summary: "Get a member's cart with current prices", tag: "cart", query: []string{"member_id", "code"}, data: map[string]any{}})
+	document(AddCartItem, apiDoc{summary: "Add an item to the cart", tag: "cart", body: CartItemRequest{}, data: map[string]any{}, status: http.StatusCreated})
+	document(DeleteCartItem, apiDoc{summary: "Remove a cart item", tag: "cart"})
+	document(CheckoutCart, apiDoc{summary: "Check out the cart into a reservation", tag: "cart", body: CheckoutRequest{}, data: map[string]any{}, status: http.StatusCreated})
+	document(FindPromotions, apiDoc{summary: "List promotions", tag: "promotion", query: []string{"active"}, data: []entity.Promotion{}})
+	document(FindPromotionById, apiDoc{summary: "Get a promotion", tag: "promotion", data: entity.Promotion{}})
+	document(FindPromotionRedemptions, apiDoc{summary: "List redemptions of a promotion", tag: "promotion", data: []entity.PromotionRedemption{}})
+	document(CreatePromotion, apiDoc{summary: "Create a promotion", tag: "promotion", body: PromotionRequest{}, data: entity.Promotion{}, status: http.StatusCreated})
+	document(CheckPromotion, apiDoc{summary: "Check a promo code against items", tag: "promotion", body: PromotionCheckRequest{}, data: map[string]any{}})
+	document(UpdatePromotionById, apiDoc{summary: "Update a promotion", tag: "promotion", body: PromotionRequest{}, data: entity.Promotion{}})
+	document(DeletePromotionById, apiDoc{summary: "Delete a promotion", tag: "promotion"})
+	document(FindWaitlist, apiDoc{summary: "List waitlist entries", tag: "waitlist", query: []string{"member_id", "package_id", "event_id", "status"}, data: []waitlistView{}})
+	document(JoinWaitlist, apiDoc{summary: "Join the waitlist of a sold-out item", tag: "waitlist", body: WaitlistRequest{}, data: waitlistView{}, status: http.StatusCreated})
+	document(ClaimWaitlistOffer, apiDoc{summary: "Claim a waitlist offer into the cart", tag: "waitlist", body: ClaimWaitlistRequest{}, data: map[string]any{}})
+	document(LeaveWaitlist, apiDoc{summary: "Leave the waitlist", tag: "waitlist", query: []string{"member_id"}})
+	document(CancelReservation, apiDoc{summary: "Cancel a reservation and release its seats", tag: "booking", data: entity.Reservation{}})
+	document(FindBookings, apiDoc{summary: "List bookings", tag: "booking", list: &bookingList, data: []entity.Booking{}})
+	document(FindBookingById, apiDoc{summary: "Get a booking", tag: "booking", data: entity.Booking{}})
+	document(CreateBooking, apiDoc{summary: "Book a room", tag: "booking", body: BookingRequest{}, data: entity.Booking{}, status: http.StatusCreated})
+
+	// Search / nearby / currency
+	document(Search, apiDoc{summary: "Full-text search across packages, accommodations and events", tag: "search", query: []string{"q", "type", "limit", "offset"}, data: []searchHit{}})
+	document(ReindexSearch, apiDoc{summary: "Rebuild the search index", tag: "search", data: map[string]any{}})
+	document(FindPlaceAliases, apiDoc{summary: "List place aliases", tag: "search", query: []string{"level", "code"}, data: []entity.PlaceAlias{}})
+	document(CreatePlaceAlias, apiDoc{summary: "Add a place alias", tag: "search", body: entity.PlaceAlias{}, data: entity.PlaceAlias{}, status: http.StatusCreated})
+	document(DeletePlaceAlias, apiDoc{summary: "Delete a place alias", tag: "search"})
+	document(FindNearby, apiDoc{summary: "Find items within a radius", tag: "search", query: []string{"lat", "lng", "radius_km", "type", "event_id", "accommodation_id", "location_id", "limit", "offset"}, data: []nearbyHit{}})
+	document(FindCurrencies, apiDoc{summary: "List currencies with latest rates", tag: "currency", data: []CurrencyResponse{}})
+	document(UpsertCurrency, apiDoc{summary: "Create or update a currency rate", tag: "currency", body: CurrencyRequest{}, data: CurrencyResponse{}})
+	document(FindExchangeRates, apiDoc{summary: "Rate history of a currency", tag: "currency", data: []entity.ExchangeRate{}})
+	document(ImportExchangeRates, apiDoc{summary: "Import exchange rates from CSV", tag: "currency", upload: true, data: []entity.ExchangeRate{}, status: http.StatusCreated})
+
+	// Jobs / geography admin
+	document(FindJobs, apiDoc{summary: "List background jobs", tag: "jobs", query: []string{"kind", "status", "limit"}, data: []jobView{}})
+	document(GetJob, apiDoc{summary: "Get job status, progress and result", tag: "jobs", data: jobView{}})
+	document(CancelJob, apiDoc{summary: "Cancel a job", tag: "jobs", data: jobView{}})
+	document(RetryJob, apiDoc{summary: "Retry a failed or cancelled job", tag: "jobs", data: jobView{}})
+	document(FindLegacyUsage, apiDoc{summary: "Hits on deprecated unversioned routes per client", tag: "system", data: []middlewares.LegacyHit{}})
+	document(ImportThailandAll, apiDoc{summary: "Import Thailand geography (background job)", tag: "geography", upload: true,
+		query: []string{"source", "dry_run", "version", "effective_date", "note"}, data: jobView{}, status: http.StatusAccepted})
+	document(GetThailandStats, apiDoc{summary: "Geography counts and latest version", tag: "geography", data: map[string]any{}})
+	document(ClearThailandData, apiDoc{summary: "Delete all geography (background job)", tag: "geography", data: jobView{}, status: http.StatusAccepted})
+	document(FindGeographyVersions, apiDoc{summary: "List geography dataset versions", tag: "geography", data: []entity.GeographyVersion{}})
+	document(FindGeographyChanges, apiDoc{summary: "Changes of a geography version", tag: "geography", query: []string{"limit", "offset", "with_dependents"}, data: []entity.GeographyChange{}})
+	document(AuditGeoHierarchy, apiDoc{summary: "Rows whose province/district/subdistrict disagree", tag: "geography", query: []string{"table", "include_retired"}, data: []geoAuditRow{}})
+
+	// Pictures / system
+	document(UploadPictures, apiDoc{summary: "Upload a picture", tag: "picture", upload: true, data: entity.Picture{}})
+	document(Livez, apiDoc{summary: "Liveness: the process is up", tag: "system", rawReply: true})
+	document(Readyz, apiDoc{summary: "Readiness: database reachable and uploads writable (503 if not)", tag: "system", rawReply: true})
+	document(OpenAPISpec, apiDoc{summary: "This OpenAPI document", tag: "system", rawReply: true})
+	document(APIDocs, apiDoc{summary: "Interactive API documentation", tag: "system", rawReply: true})
+}
+
+// problemDoc คือรูปร่างของคำตอบ error จาก apierr (ใช้สร้าง schema เท่านั้น)
+type problemDoc struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail"`
+	Code     apierr.Code         `json:"code"`
+	Instance string              `json:"instance"`
+	Error    string              `json:"error"`
+	Errors   []apierr.FieldError `json:"errors,omitempty"`
+}
+
+var routeParam = regexp.MustCompile(`[:*](\w+)`)
+
+// BuildOpenAPI สร้างเอกสารจาก route ที่ผูกไว้จริง route ที่ไม่มีเอกสารคืนใน missing (เทสต์ใช้ตรวจ)
+func BuildOpenAPI(routes gin.RoutesInfo) (doc openapi.Document, missing []gin.RouteInfo) {
+	gen := openapi.NewGenerator()
+	problem := gen.SchemaOf(problemDoc{})
+	page := gen.SchemaOf(listPage{})
+	doc = openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "SA Project travel API",
+			Version:     "1.0.0",
+			Description: "Routes outside /api/v1 are legacy aliases of the same handlers.",
+		},
+		Paths: map[string]openapi.PathItem{},
+	}
+
+	// route ที่ handler มีทั้งใน /api/v1 และนอก /api/v1 คือ alias legacy
+	versioned := map[string]bool{}
+	for _, r := range routes {
+		if strings.HasPrefix(r.Path, "/api/v1/") {
+			versioned[r.Handler] = true
+		}
+	}
+	tags := map[string]bool{}
+	for _, r := range routes {
+		d, ok := pathDocs[r.Method+" "+r.Path]
+		if !ok {
+			d, ok = handlerDocs[r.Handler]
+		}
+		if !ok {
+			missing = append(missing, r)
+			continue
+		}
+		legacy := versioned[r.Handler] && !strings.HasPrefix(r.Path, "/api/v1/")
+		name := r.Handler[strings.LastIndex(r.Handler, ".")+1:]
+		op := &openapi.Operation{
+			Summary:     d.summary,
+			OperationID: r.Method + "_" + strings.Trim(routeParam.ReplaceAllString(r.Path, "by_$1"), "/"),
+			Tags:        []string{d.tag},
+			Responses:   map[string]openapi.Response{},
+		}
+		op.OperationID = strings.NewReplacer("/", "_", "-", "_").Replace(op.OperationID)
+		if legacy {
+			op.Tags = append(op.Tags, "legacy")
+			op.Deprecated = true
+			op.Description = "Legacy alias of the /api/v1 route (" + name + "); responses carry Deprecation and Sunset headers. " +
+				"Disabled when LEGACY_ROUTES=false."
+		}
+		tags[d.tag] = true
+
+		for _, m := range routeParam.FindAllStringSubmatch(r.Path, -1) {
+			schema := &openapi.Schema{Type: "string"}
+			if strings.HasSuffix(m[1], "id") {
+				schema = &openapi.Schema{Type: "integer"}
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: m[1], In: "path", Required: true, Schema: schema})
+		}
+		query := append([]string{}, d.query...)
+		if d.list != nil {
+			query = append(query, "page", "page_size", "cursor", "sort")
+			for f := range d.list.filters {
+				query = append(query, f)
+			}
+		}
+		seen := map[string]bool{}
+		sort.Strings(query)
+		for _, q := range query {
+			if seen[q] {
+				continue
+			}
+			seen[q] = true
+			p := openapi.Parameter{Name: q, In: "query", Schema: &openapi.Schema{Type: "string"}}
+			if q == "sort" && d.list != nil {
+				var keys []string
+				for k := range d.list.sorts {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				p.Description = "Comma-separated; prefix with - for descending. One of: " + strings.Join(keys, ", ")
+			}
+			op.Parameters = append(op.Parameters, p)
+		}
+
+		if d.body != nil {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+				"application/json": {Schema: gen.SchemaOf(d.body)},
+			}}
+		}
+		if d.upload {
+			op.RequestBody = &openapi.RequestBody{Content: map[string]openapi.MediaType{
+				"multipart/form-data": {Schema: openapi.Object(map[string]*openapi.Schema{
+					"file": {Type: "string", Format: "binary"},
+				})},
+			}}
+		}
+
+		status := d.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok200 := openapi.Response{Description: http.StatusText(status)}
+		if !d.rawReply {
+			props := map[string]*openapi.Schema{"message": {Type: "string"}}
+			if d.data != nil {
+				props["data"] = gen.SchemaOf(d.data)
+			}
+			if d.list != nil {
+				props["pagination"] = page
+			}
+			for name, v := range d.extra {
+				props[name] = gen.SchemaOf(v)
+			}
+			ok200.Content = map[string]openapi.MediaType{"application/json": {Schema: openapi.Object(props)}}
+		}
+		op.Responses[strconv.Itoa(status)] = ok200
+		op.Responses["default"] = openapi.Response{
+			Description: "Error (RFC 7807)",
+			Content:     map[string]openapi.MediaType{apierr.ContentType: {Schema: problem}},
+		}
+
+		path := routeParam.ReplaceAllString(r.Path, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = openapi.PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+	for t := range tags {
+		doc.Tags = append(doc.Tags, openapi.Tag{Name: t})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = gen.Schemas
+	return doc, missing
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// openAPIDoc คือเอกสารที่สร้างตอน ServeOpenAPI (route ไม่เปลี่ยนหลังเริ่มระบบ)
+var openAPIDoc openapi.Document
+
+// ServeOpenAPI สร้างเอกสารจาก route ทั้งหมดของ r เรียกหลังผูก route ครบแล้ว
+func ServeOpenAPI(r *gin.Engine) {
+	openAPIDoc, _ = BuildOpenAPI(r.Routes())
+}
+
+// GET /openapi.json - เอกสาร OpenAPI 3 ของทุก route
+func OpenAPISpec(c *gin.Context) {
+	c.JSON(http.StatusOK, openAPIDoc)
+}
+
+// GET /docs - หน้าเอกสาร (Swagger UI) ที่อ่าน /openapi.json
+func APIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -1,12 +1,13 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"github.com/kookkikiv/sa_project/backend/entity"
 	"gorm.io/gorm"
 )
@@ -18,11 +19,15 @@ type PackageRequest struct {
 	StartDate     string `json:"start_date"` // "YYYY-MM-DD"
 	FinalDate     string `json:"final_date"` // "YYYY-MM-DD"
 	Price         *uint  `json:"price"`
+	PriceUnit     string `json:"price_unit"` // per_person (ค่าเริ่มต้น) | per_room
 	GuideID       *uint  `json:"guide_id"`
 	ProvinceID    *uint  `json:"province_id"`
 	DistrictID    *uint  `json:"district_id"`
 	SubdistrictID *uint  `json:"subdistrict_id"`
 	AdminID       *uint  `json:"admin_id"`
+
+	// ที่พัก/ห้องของแพ็คเกจ: ตอน update ถ้าไม่ส่ง = ไม่แตะ, ส่ง [] = ล้างทั้งหมด
+	Stays *[]PackageStayRequest `json:"stays"`
 }
 
 // parse "YYYY-MM-DD" -> time.Time (UTC 00:00)
@@ -41,39 +46,36 @@ func parseYMD(s string) (time.Time, error) {
 func CreatePackage(c *gin.Context) {
 	var req PackageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid JSON format",
-			"details": err.Error(),
-		})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
 	// validate ขั้นต้น
 	if req.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Package name is required"})
+		apierr.Respond(c, apierr.Required("name"))
 		return
 	}
 	if req.AdminID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "AdminID is required"})
+		apierr.Respond(c, apierr.Required("admin_id"))
 		return
 	}
 	if req.GuideID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "GuideID is required"})
+		apierr.Respond(c, apierr.Required("guide_id"))
 		return
 	}
 
 	start, err := parseYMD(req.StartDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be YYYY-MM-DD"})
+		apierr.Respond(c, apierr.BadRequest("start_date must be YYYY-MM-DD"))
 		return
 	}
 	end, err := parseYMD(req.FinalDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "final_date must be YYYY-MM-DD"})
+		apierr.Respond(c, apierr.BadRequest("final_date must be YYYY-MM-DD"))
 		return
 	}
 
-	db := config.DB()
+	db := dbFor(c)
 	tx := db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -88,9 +90,9 @@ func CreatePackage(c *gin.Context) {
 		if err := tx.First(&admin, req.AdminID).Error; err != nil {
 			tx.Rollback()
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Admin not found"})
+				apierr.Respond(c, apierr.BadRequest("Admin not found"))
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apierr.Respond(c, apierr.Internal(err))
 			}
 			return
 		}
@@ -101,143 +103,161 @@ func CreatePackage(c *gin.Context) {
 		if err := tx.First(&guide, req.GuideID).Error; err != nil {
 			tx.Rollback()
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Guide not found"})
+				apierr.Respond(c, apierr.BadRequest("Guide not found"))
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apierr.Respond(c, apierr.Internal(err))
 			}
 			return
 		}
 	}
-	// Provinces/Districts/Subdistricts (optional)
-	if req.ProvinceID != nil {
-		var p entity.Province
-		if err := tx.First(&p, req.ProvinceID).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Province not found"})
-			return
-		}
+	// Provinces/Districts/Subdistricts (optional) ต้องอยู่ในกันจริง
+	geo, err := checkGeoHierarchy(tx, geoRef{req.ProvinceID, req.DistrictID, req.SubdistrictID}, geoRef{})
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, err)
+		return
 	}
-	if req.DistrictID != nil {
-		var d entity.District
-		if err := tx.First(&d, req.DistrictID).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "District not found"})
-			return
-		}
+
+	if !validPriceUnit(req.PriceUnit) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("price_unit must be per_person or per_room"))
+		return
+	}
+
+	// Guide ต้องว่างตลอดช่วงวันที่ของแพ็คเกจ
+	if !checkGuideSchedule(c, tx, req.GuideID, start, end, 0) {
+		tx.Rollback()
+		return
 	}
-	if req.SubdistrictID != nil {
-		var s entity.Subdistrict
-		if err := tx.First(&s, req.SubdistrictID).Error; err != nil {
+	if req.Stays != nil {
+		if msg := validatePackageStays(tx, *req.Stays); msg != "" {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Subdistrict not found"})
+			apierr.Respond(c, apierr.BadRequest(msg))
 			return
 		}
 	}
 
 	pack := entity.Package{
-		Name:          req.Name,
-		People:        func() uint { if req.People != nil { return *req.People }; return 0 }(),
-		StartDate:     start,
-		FinalDate:     end,
-		Price:         func() uint { if req.Price != nil { return *req.Price }; return 0 }(),
+		Name: req.Name,
+		People: func() uint {
+			if req.People != nil {
+				return *req.People
+			}
+			return 0
+		}(),
+		StartDate: start,
+		FinalDate: end,
+		Price: func() uint {
+			if req.Price != nil {
+				return *req.Price
+			}
+			return 0
+		}(),
+		PriceUnit:     req.PriceUnit,
 		GuideID:       req.GuideID,
-		ProvinceID:    req.ProvinceID,
-		DistrictID:    req.DistrictID,
-		SubdistrictID: req.SubdistrictID,
+		ProvinceID:    geo.ProvinceID,
+		DistrictID:    geo.DistrictID,
+		SubdistrictID: geo.SubdistrictID,
 		AdminID:       req.AdminID,
 	}
 
 	if err := tx.Create(&pack).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to create package",
-			"details": err.Error(),
-		})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create package"))
+		return
+	}
+	if req.Stays != nil {
+		if _, err := syncPackageStays(tx, pack.ID, *req.Stays); err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to save stays"))
+			return
+		}
+	}
+	if err := indexDocument(tx, searchPackage, pack.ID); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update search index"))
 		return
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Commit failed"})
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
 		return
 	}
 
+	preloadPackage(db).First(&pack, pack.ID)
 	c.JSON(http.StatusCreated, gin.H{
 		"data":    pack,
 		"message": "Package created successfully",
 	})
 }
 
-// GET /package
+var packageList = listSpec{
+	table: "packages",
+	sorts: map[string]string{"name": "name", "price": "price", "people": "people", "start_date": "start_date",
+		"final_date": "final_date", "created_at": "created_at"},
+	filters: map[string]string{"admin_id": "admin_id", "guide_id": "guide_id", "province_id": "province_id",
+		"district_id": "district_id", "subdistrict_id": "subdistrict_id", "price_unit": "price_unit"},
+}
+
+// GET /package?page=&page_size=&cursor=&sort=-price,name&province_id=&accommodation_id=&room_id=
 func FindPackage(c *gin.Context) {
-	db := config.DB()
+	db := dbFor(c)
 	var packages []entity.Package
+	conv, ok := currencyFromQuery(c, db)
+	if !ok {
+		return
+	}
 
-	// filters
+	// filters (admin/guide/จังหวัด ฯลฯ อยู่ใน packageList)
 	accommodationId := c.Query("accommodation_id")
-	adminId := c.Query("admin_id")
-	guideId := c.Query("guide_id")
-	provinceId := c.Query("province_id")
+	roomId := c.Query("room_id")
 
-	query := db.
-		Preload("Guide").
-		Preload("Admin").
-		Preload("Province").
-		Preload("District").
-		Preload("Subdistrict")
-		// ถ้าจะ preload many2many accommodations/event ให้เพิ่มได้
-		// .Preload("Accommodation").
-		// .Preload("Event")
+	query := db
 
+	// กรองผ่าน package_stays (แทนตารางเก่า accommodation_package)
 	if accommodationId != "" {
-		// ถ้ามี M2M: packages <-> accommodations (accommodation_package)
-		// ใช้ join กับตารางกลาง
-		query = query.Joins("JOIN accommodation_package ap ON ap.package_id = packages.id").
-			Where("ap.accommodation_id = ?", accommodationId)
-	}
-
-	if adminId != "" {
-		query = query.Where("packages.admin_id = ?", adminId)
-	}
-	if guideId != "" {
-		query = query.Where("packages.guide_id = ?", guideId)
+		query = query.Where("packages.id IN (?)", db.Model(&entity.PackageStay{}).
+			Select("package_id").Where("accommodation_id = ?", accommodationId))
 	}
-	if provinceId != "" {
-		query = query.Where("packages.province_id = ?", provinceId)
+	if roomId != "" {
+		query = query.Where("packages.id IN (?)", db.Model(&entity.PackageStay{}).
+			Select("package_id").Where("room_id = ?", roomId))
 	}
 
-	if err := query.Find(&packages).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, ok := packageList.find(c, query, &packages, preloadPackage)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": packages})
+	for i := range packages {
+		conv.applyPackage(&packages[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"data": packages, "pagination": page})
 }
 
 // GET /package/:id
 func FindPackageById(c *gin.Context) {
 	id := c.Param("id")
 	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID format"})
+		apierr.Respond(c, apierr.BadRequest("Invalid package ID format"))
 		return
 	}
 
-	db := config.DB()
+	db := dbFor(c)
+	conv, ok := currencyFromQuery(c, db)
+	if !ok {
+		return
+	}
 	var pack entity.Package
-	if err := db.
-		Preload("Guide").
-		Preload("Admin").
-		Preload("Province").
-		Preload("District").
-		Preload("Subdistrict").
-		// .Preload("Accommodation").
-		// .Preload("Event").
+	if err := preloadPackage(db).
 		Where("id = ?", id).First(&pack).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+			apierr.Respond(c, apierr.NotFound("Package not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 		}
 		return
 	}
+	conv.applyPackage(&pack)
 	c.JSON(http.StatusOK, gin.H{"data": pack})
 }
 
@@ -245,24 +265,24 @@ func FindPackageById(c *gin.Context) {
 func UpdatePackageById(c *gin.Context) {
 	id := c.Param("id")
 	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID format"})
+		apierr.Respond(c, apierr.BadRequest("Invalid package ID format"))
 		return
 	}
 
 	var req PackageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
+		apierr.Respond(c, apierr.Bind(err))
 		return
 	}
 
-	db := config.DB()
+	db := dbFor(c)
 
 	var pack entity.Package
 	if err := db.First(&pack, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+			apierr.Respond(c, apierr.NotFound("Package not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 		}
 		return
 	}
@@ -278,11 +298,18 @@ func UpdatePackageById(c *gin.Context) {
 	if req.Price != nil {
 		updates.Price = *req.Price
 	}
+	if req.PriceUnit != "" {
+		if !validPriceUnit(req.PriceUnit) {
+			apierr.Respond(c, apierr.BadRequest("price_unit must be per_person or per_room"))
+			return
+		}
+		updates.PriceUnit = req.PriceUnit
+	}
 	if req.StartDate != "" {
 		if t, err := parseYMD(req.StartDate); err == nil {
 			updates.StartDate = t
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be YYYY-MM-DD"})
+			apierr.Respond(c, apierr.BadRequest("start_date must be YYYY-MM-DD"))
 			return
 		}
 	}
@@ -290,7 +317,7 @@ func UpdatePackageById(c *gin.Context) {
 		if t, err := parseYMD(req.FinalDate); err == nil {
 			updates.FinalDate = t
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "final_date must be YYYY-MM-DD"})
+			apierr.Respond(c, apierr.BadRequest("final_date must be YYYY-MM-DD"))
 			return
 		}
 	}
@@ -305,7 +332,7 @@ func UpdatePackageById(c *gin.Context) {
 		var g entity.Guide
 		if err := tx.First(&g, req.GuideID).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Guide not found"})
+			apierr.Respond(c, apierr.BadRequest("Guide not found"))
 			return
 		}
 		updates.GuideID = req.GuideID
@@ -314,58 +341,127 @@ func UpdatePackageById(c *gin.Context) {
 		var a entity.Admin
 		if err := tx.First(&a, req.AdminID).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Admin not found"})
+			apierr.Respond(c, apierr.BadRequest("Admin not found"))
 			return
 		}
 		updates.AdminID = req.AdminID
 	}
-	if req.ProvinceID != nil {
-		var p entity.Province
-		if err := tx.First(&p, req.ProvinceID).Error; err != nil {
+	if req.ProvinceID != nil || req.DistrictID != nil || req.SubdistrictID != nil {
+		prev := geoRef{pack.ProvinceID, pack.DistrictID, pack.SubdistrictID}
+		next := prev
+		if req.ProvinceID != nil {
+			next.ProvinceID = req.ProvinceID
+		}
+		if req.DistrictID != nil {
+			next.DistrictID = req.DistrictID
+		}
+		if req.SubdistrictID != nil {
+			next.SubdistrictID = req.SubdistrictID
+		}
+		geo, err := checkGeoHierarchy(tx, next, prev)
+		if err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Province not found"})
+			apierr.Respond(c, err)
 			return
 		}
-		updates.ProvinceID = req.ProvinceID
+		updates.ProvinceID, updates.DistrictID, updates.SubdistrictID = geo.ProvinceID, geo.DistrictID, geo.SubdistrictID
 	}
-	if req.DistrictID != nil {
-		var d entity.District
-		if err := tx.First(&d, req.DistrictID).Error; err != nil {
+
+	// ตรวจตารางงานไกด์ด้วยค่าหลังแก้ไข (ค่าที่ไม่ได้ส่งมาใช้ของเดิม)
+	{
+		guideID, start, end := pack.GuideID, pack.StartDate, pack.FinalDate
+		if updates.GuideID != nil {
+			guideID = updates.GuideID
+		}
+		if !updates.StartDate.IsZero() {
+			start = updates.StartDate
+		}
+		if !updates.FinalDate.IsZero() {
+			end = updates.FinalDate
+		}
+		if !checkGuideSchedule(c, tx, guideID, start, end, pack.ID) {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "District not found"})
 			return
 		}
-		updates.DistrictID = req.DistrictID
+
+		// วันของกำหนดการเดิมต้องยังอยู่ในช่วงวันที่ใหม่
+		if !updates.StartDate.IsZero() || !updates.FinalDate.IsZero() {
+			ok, err := checkItineraryRange(tx, pack.ID, start, end)
+			if err != nil {
+				tx.Rollback()
+				apierr.Respond(c, apierr.Internal(err))
+				return
+			}
+			if !ok {
+				tx.Rollback()
+				apierr.Respond(c, apierr.Conflict("Itinerary has days outside the new date range"))
+				return
+			}
+		}
 	}
-	if req.SubdistrictID != nil {
-		var s entity.Subdistrict
-		if err := tx.First(&s, req.SubdistrictID).Error; err != nil {
+
+	// ลดจำนวนคนได้ไม่ต่ำกว่าที่นั่งที่ถูกกัน/จองไปแล้ว
+	if req.People != nil && *req.People > 0 {
+		u, err := loadSeatUsage(tx, packageSeats(pack.ID), time.Now())
+		if err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+		if *req.People < u.used() {
 			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Subdistrict not found"})
+			apierr.Respond(c, apierr.Conflict(fmt.Sprintf("people must be at least %d (seats already held or booked)", u.used())))
 			return
 		}
-		updates.SubdistrictID = req.SubdistrictID
 	}
 
 	if err := tx.Model(&pack).Updates(&updates).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update package: " + err.Error()})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update package"))
 		return
 	}
+	// เพิ่มจำนวนคนแล้ว เสนอที่ว่างให้คิว waitlist
+	if req.People != nil {
+		if err := offerWaitlistSeats(tx, packageSeats(pack.ID), time.Now()); err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to process waitlist"))
+			return
+		}
+	}
+
+	if req.Stays != nil {
+		if msg := validatePackageStays(tx, *req.Stays); msg != "" {
+			tx.Rollback()
+			apierr.Respond(c, apierr.BadRequest(msg))
+			return
+		}
+		msg, err := syncPackageStays(tx, pack.ID, *req.Stays)
+		if err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to save stays"))
+			return
+		}
+		if msg != "" {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Conflict(msg))
+			return
+		}
+	}
 
-	if err := tx.Preload("Guide").
-		Preload("Admin").
-		Preload("Province").
-		Preload("District").
-		Preload("Subdistrict").
+	if err := preloadPackage(tx).
 		First(&pack, id).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload package data"})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to reload package data"))
+		return
+	}
+	if err := indexDocument(tx, searchPackage, pack.ID); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update search index"))
 		return
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Commit failed"})
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
 		return
 	}
 
@@ -376,18 +472,18 @@ func UpdatePackageById(c *gin.Context) {
 func DeletePackageById(c *gin.Context) {
 	id := c.Param("id")
 	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID format"})
+		apierr.Respond(c, apierr.BadRequest("Invalid package ID format"))
 		return
 	}
 
-	db := config.DB()
+	db := dbFor(c)
 
 	var pack entity.Package
 	if err := db.First(&pack, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+			apierr.Respond(c, apierr.NotFound("Package not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Internal(err))
 		}
 		return
 	}
@@ -402,18 +498,36 @@ func DeletePackageById(c *gin.Context) {
 	// ถ้ามีตารางกลาง (accommodation_package, event_package) ให้ลบที่ pivot ก่อน
 	if err := tx.Exec("DELETE FROM accommodation_package WHERE package_id = ?", id).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete accommodation mappings"})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to delete accommodation mappings"))
 		return
 	}
 	if err := tx.Exec("DELETE FROM event_package WHERE package_id = ?", id).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event mappings"})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to delete event mappings"))
+		return
+	}
+
+	if err := tx.Where("package_id = ?", id).Delete(&entity.PackageStay{}).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to delete stays"))
+		return
+	}
+
+	// กำหนดการรายวัน (activity ถูกลบตาม FK cascade)
+	if err := tx.Unscoped().Where("package_id = ?", id).Delete(&entity.ItineraryDay{}).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to delete itinerary"))
 		return
 	}
 
 	if err := tx.Delete(&pack).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete package"})
+		apierr.Respond(c, apierr.Wrap(err, "Failed to delete package"))
+		return
+	}
+	if err := indexDocument(tx, searchPackage, pack.ID); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update search index"))
 		return
 	}
 
@@ -423,7 +537,7 @@ func DeletePackageById(c *gin.Context) {
 
 // GET /package/stats
 func GetPackageStats(c *gin.Context) {
-	db := config.DB()
+	db := dbFor(c)
 	var total, active, completed int64
 
 	db.Model(&entity.Package{}).Count(&total)
@@ -446,8 +560,12 @@ func GetPackageStats(c *gin.Context) {
 
 // GET /package/search
 func SearchPackages(c *gin.Context) {
-	db := config.DB()
+	db := dbFor(c)
 	var packs []entity.Package
+	conv, ok := currencyFromQuery(c, db)
+	if !ok {
+		return
+	}
 
 	name := c.Query("name")
 	minPrice := c.Query("min_price")
@@ -456,14 +574,16 @@ func SearchPackages(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
-	q := db.Preload("Guide").
-		Preload("Admin").
-		Preload("Province").
-		Preload("District").
-		Preload("Subdistrict")
+	q := preloadPackage(db)
 
 	if name != "" {
-		q = q.Where("packages.name LIKE ?", "%"+name+"%")
+		// ค้นผ่าน search index: ชื่อแพ็คเกจ ชื่อสถานที่ไทย/อังกฤษ ที่พัก และอีเวนต์
+		ids, err := searchDocIDs(db, searchPackage, name)
+		if err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+		q = q.Where("packages.id IN ?", ids)
 	}
 	if minPrice != "" {
 		q = q.Where("packages.price >= ?", minPrice)
@@ -482,8 +602,11 @@ func SearchPackages(c *gin.Context) {
 	}
 
 	if err := q.Find(&packs).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierr.Respond(c, apierr.Internal(err))
 		return
 	}
+	for i := range packs {
+		conv.applyPackage(&packs[i])
+	}
 	c.JSON(http.StatusOK, gin.H{"data": packs, "count": len(packs)})
 }
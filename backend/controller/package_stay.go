@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// PackageStayRequest คือที่พัก 1 แห่งของแพ็คเกจ (room_id ไม่ส่ง = ยังไม่ระบุห้อง)
+type PackageStayRequest struct {
+	AccommodationID uint  `json:"accommodation_id"`
+	RoomID          *uint `json:"room_id"`
+}
+
+type stayKey struct {
+	AccommodationID uint
+	RoomID          uint // 0 = ไม่ระบุห้อง
+}
+
+func keyOfStay(accommodationID uint, roomID *uint) stayKey {
+	k := stayKey{AccommodationID: accommodationID}
+	if roomID != nil {
+		k.RoomID = *roomID
+	}
+	return k
+}
+
+// validatePackageStays เช็คว่าที่พักมีจริง และห้องต้องเป็นของที่พักนั้น
+func validatePackageStays(tx *gorm.DB, stays []PackageStayRequest) string {
+	seen := make(map[stayKey]bool, len(stays))
+	for i, s := range stays {
+		if s.AccommodationID == 0 {
+			return fmt.Sprintf("stays[%d]: accommodation_id is required", i)
+		}
+		var acc entity.Accommodation
+		if err := tx.First(&acc, s.AccommodationID).Error; err != nil {
+			return fmt.Sprintf("stays[%d]: Accommodation not found", i)
+		}
+		if s.RoomID != nil {
+			var room entity.Room
+			if err := tx.First(&room, *s.RoomID).Error; err != nil {
+				return fmt.Sprintf("stays[%d]: Room not found", i)
+			}
+			if room.AccommodationID == nil || *room.AccommodationID != acc.ID {
+				return fmt.Sprintf("stays[%d]: Room %d does not belong to accommodation %d", i, room.ID, acc.ID)
+			}
+		}
+		k := keyOfStay(s.AccommodationID, s.RoomID)
+		if seen[k] {
+			return fmt.Sprintf("stays[%d]: duplicate stay", i)
+		}
+		seen[k] = true
+	}
+	return ""
+}
+
+// syncPackageStays ทำให้ PackageStay ของแพ็คเกจตรงกับรายการที่ส่งมา
+// stay เดิมที่ยังอยู่จะคง ID ไว้ (itinerary อ้างถึงได้ต่อ) ส่วนที่ถูกเอาออกต้องไม่ถูกใช้ใน itinerary
+func syncPackageStays(tx *gorm.DB, packageID uint, stays []PackageStayRequest) (string, error) {
+	var existing []entity.PackageStay
+	if err := tx.Where("package_id = ?", packageID).Find(&existing).Error; err != nil {
+		return "", err
+	}
+
+	wanted := make(map[stayKey]bool, len(stays))
+	for _, s := range stays {
+		wanted[keyOfStay(s.AccommodationID, s.RoomID)] = true
+	}
+
+	have := make(map[stayKey]bool, len(existing))
+	var removeIDs []uint
+	for _, s := range existing {
+		k := keyOfStay(s.AccommodationID, s.RoomID)
+		if wanted[k] && !have[k] {
+			have[k] = true
+			continue
+		}
+		removeIDs = append(removeIDs, s.ID)
+	}
+
+	if len(removeIDs) > 0 {
+		var used int64
+		if err := tx.Model(&entity.ItineraryActivity{}).Where("package_stay_id IN ?", removeIDs).Count(&used).Error; err != nil {
+			return "", err
+		}
+		if used > 0 {
+			return "Cannot remove stays that are used in the itinerary", nil
+		}
+		if err := tx.Where("id IN ?", removeIDs).Delete(&entity.PackageStay{}).Error; err != nil {
+			return "", err
+		}
+	}
+
+	for _, s := range stays {
+		k := keyOfStay(s.AccommodationID, s.RoomID)
+		if have[k] {
+			continue
+		}
+		have[k] = true
+		stay := entity.PackageStay{PackageID: packageID, AccommodationID: s.AccommodationID, RoomID: s.RoomID}
+		if err := tx.Omit("Package", "Accommodation", "Room").Create(&stay).Error; err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// preloadPackage โหลดความสัมพันธ์ที่หน้าแพ็คเกจใช้ รวมที่พัก/ห้อง
+func preloadPackage(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Guide").
+		Preload("Admin").
+		Preload("Province").
+		Preload("District").
+		Preload("Subdistrict").
+		Preload("PackageStay.Accommodation").
+		Preload("PackageStay.Room")
+}
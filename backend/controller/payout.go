@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+type PayoutBatchRequest struct {
+	PeriodEnd string `json:"period_end"` // "YYYY-MM-DD" รวมรายได้ถึงสิ้นวันนี้
+	Note      string `json:"note"`
+}
+
+type PayoutStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// สถานะที่เปลี่ยนไปได้จากแต่ละสถานะ
+var payoutTransitions = map[string][]string{
+	entity.PayoutDraft:    {entity.PayoutApproved, entity.PayoutCancelled},
+	entity.PayoutApproved: {entity.PayoutPaid, entity.PayoutCancelled},
+}
+
+func canTransitPayout(from, to string) bool {
+	for _, s := range payoutTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+var payoutBatchList = listSpec{
+	table:       "payout_batches",
+	sorts:       map[string]string{"period_end": "period_end", "total": "total", "created_at": "created_at"},
+	filters:     map[string]string{"status": "status"},
+	defaultSort: "-id",
+}
+
+// GET /payout-batch?status=&page=&page_size=&cursor=&sort= (ค่าเริ่มต้นรอบล่าสุดก่อน)
+func FindPayoutBatches(c *gin.Context) {
+	var batches []entity.PayoutBatch
+	page, ok := payoutBatchList.find(c, dbFor(c), &batches, func(db *gorm.DB) *gorm.DB { return db.Preload("Lines") })
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": batches, "pagination": page})
+}
+
+// GET /payout-batch/:id
+func FindPayoutBatchById(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid payout batch ID format"))
+		return
+	}
+	var batch entity.PayoutBatch
+	if err := dbFor(c).Preload("Lines").First(&batch, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Payout batch not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": batch})
+}
+
+// POST /payout-batch - รวมค่าไกด์ที่ยังไม่เข้ารอบจ่ายจนถึง period_end
+func CreatePayoutBatch(c *gin.Context) {
+	var req PayoutBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	periodEnd, err := parseYMD(req.PeriodEnd)
+	if err != nil || periodEnd.IsZero() {
+		apierr.Respond(c, apierr.BadRequest("period_end must be YYYY-MM-DD"))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	type guideTotal struct {
+		GuideID uint
+		Amount  money.Money
+	}
+	var totals []guideTotal
+	// ยอดสุทธิ credit - debit: ค่าไกด์ที่กลับรายการเพราะยกเลิกการจอง (debit ที่ยังไม่เข้ารอบ) หักออกไปในรอบเดียวกัน
+	unbatched := tx.Model(&entity.LedgerEntry{}).
+		Where("account = ? AND guide_id IS NOT NULL AND payout_batch_id IS NULL", entity.AccountGuidePayable).
+		Where("created_at < ?", periodEnd.AddDate(0, 0, 1))
+	if err := unbatched.Session(&gorm.Session{}).
+		Select("guide_id, SUM(credit) - SUM(debit) AS amount").
+		Group("guide_id").
+		Having("SUM(credit) - SUM(debit) > 0").
+		Scan(&totals).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if len(totals) == 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("No guide earnings to pay out for this period"))
+		return
+	}
+
+	batch := entity.PayoutBatch{
+		Status:    entity.PayoutDraft,
+		PeriodEnd: periodEnd,
+		Note:      strings.TrimSpace(req.Note),
+	}
+	guideIDs := make([]uint, 0, len(totals))
+	for _, t := range totals {
+		guideIDs = append(guideIDs, t.GuideID)
+		batch.Lines = append(batch.Lines, entity.PayoutLine{GuideID: t.GuideID, Amount: t.Amount})
+		batch.Total += t.Amount
+	}
+	if err := tx.Create(&batch).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create payout batch"))
+		return
+	}
+	if err := unbatched.Session(&gorm.Session{}).Where("guide_id IN ?", guideIDs).Update("payout_batch_id", batch.ID).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to assign ledger entries"))
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": batch, "message": "Payout batch created successfully"})
+}
+
+// PUT /payout-batch/:id/status
+// paid = ลงบัญชีจ่ายเงินไกด์, cancelled = ปล่อยรายการกลับไปรอรอบถัดไป
+func UpdatePayoutBatchStatus(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid payout batch ID format"))
+		return
+	}
+
+	var req PayoutStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var batch entity.PayoutBatch
+	if err := tx.Preload("Lines").First(&batch, id).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.NotFound("Payout batch not found"))
+		return
+	}
+	if !canTransitPayout(batch.Status, status) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict(fmt.Sprintf("Cannot change payout batch from %s to %s", batch.Status, status)))
+		return
+	}
+
+	updates := map[string]any{"status": status}
+	switch status {
+	case entity.PayoutPaid:
+		now := time.Now()
+		updates["paid_at"] = now
+		txRef := fmt.Sprintf("PAYOUT-%d", batch.ID)
+		var entries []entity.LedgerEntry
+		for _, l := range batch.Lines {
+			guideID := l.GuideID
+			memo := fmt.Sprintf("payout batch #%d", batch.ID)
+			entries = append(entries,
+				entity.LedgerEntry{
+					TxRef: txRef, Account: entity.AccountGuidePayable, Debit: l.Amount, Memo: memo,
+					GuideID: &guideID, PayoutBatchID: &batch.ID,
+				},
+				entity.LedgerEntry{
+					TxRef: txRef, Account: entity.AccountCash, Credit: l.Amount, Memo: memo,
+					GuideID: &guideID, PayoutBatchID: &batch.ID,
+				},
+			)
+		}
+		if len(entries) > 0 {
+			if err := tx.Create(&entries).Error; err != nil {
+				tx.Rollback()
+				apierr.Respond(c, apierr.Wrap(err, "Failed to post payout entries"))
+				return
+			}
+		}
+	case entity.PayoutCancelled:
+		if err := tx.Model(&entity.LedgerEntry{}).
+			Where("payout_batch_id = ?", batch.ID).
+			Update("payout_batch_id", nil).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to release ledger entries"))
+			return
+		}
+	}
+
+	if err := tx.Model(&batch).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update payout batch"))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	db.Preload("Lines").First(&batch, batch.ID)
+	c.JSON(http.StatusOK, gin.H{"data": batch, "message": "Payout batch updated successfully"})
+}
@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"github.com/kookkikiv/sa_project/backend/entity"
 )
 
@@ -20,7 +20,7 @@ func UploadPictures(c *gin.Context) {
 	// 1) รับไฟล์ชื่อฟิลด์ "file"
 	fh, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file: " + err.Error()})
+		apierr.Respond(c, apierr.Invalid("Request validation failed", apierr.FieldError{Field: "file", Code: "required", Message: "is required"}))
 		return
 	}
 
@@ -32,7 +32,7 @@ func UploadPictures(c *gin.Context) {
 
 	// 3) สร้างโฟลเดอร์ปลายทาง
 	if err := os.MkdirAll("./uploads", 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot create uploads dir: " + err.Error()})
+		apierr.Respond(c, apierr.Wrap(err, "cannot create uploads dir"))
 		return
 	}
 
@@ -40,7 +40,7 @@ func UploadPictures(c *gin.Context) {
 	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(fh.Filename))
 	dst := filepath.Join("uploads", filename)
 	if err := c.SaveUploadedFile(fh, dst); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "save file failed: " + err.Error()})
+		apierr.Respond(c, apierr.Wrap(err, "save file failed"))
 		return
 	}
 
@@ -63,7 +63,7 @@ func UploadPictures(c *gin.Context) {
 				OwnerType: ownerType,
 				OwnerID:   uint(v),
 			}
-			if err := config.DB().Create(&pic).Error; err == nil {
+			if err := dbFor(c).Create(&pic).Error; err == nil {
 				picID = pic.ID
 			}
 			// ถ้า save พลาด เราก็ยังคืน url ให้ FE ใช้ต่อได้
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/thai"
+	"gorm.io/gorm"
+)
+
+// maxAliasWords คือจำนวนคำค้นติดกันที่ลองรวมเป็นชื่อพื้นที่ (เช่น "phra nakhon si ayutthaya")
+const maxAliasWords = 5
+
+// maxAliasPlaces จำกัดจำนวนพื้นที่ต่อชื่อ (ชื่อตำบลซ้ำกันได้หลายสิบแห่ง)
+const maxAliasPlaces = 50
+
+// placeRef คือจังหวัด/อำเภอ/ตำบลที่ชื่อค้นหาชี้ไป
+type placeRef struct {
+	Level  string `json:"level"`
+	Code   string `json:"code"`
+	NameTh string `json:"name_th"`
+	NameEn string `json:"name_en"`
+}
+
+var placeLevels = map[string]int{entity.PlaceProvince: 0, entity.PlaceDistrict: 1, entity.PlaceSubdistrict: 2}
+
+// placeToken คือคำที่ index ไว้ใน Keywords ของรายการในพื้นที่นั้น เช่น geop50, geod5001, geos500101
+func placeToken(level, code string) string { return "geo" + level[:1] + code }
+
+func (p placeRef) token() string { return placeToken(p.Level, p.Code) }
+
+// placeTokens คือ token ของตำบล/อำเภอ/จังหวัดของรายการ (ข้ามที่ไม่ได้ระบุ)
+func placeTokens(p *entity.Province, d *entity.District, s *entity.Subdistrict) []string {
+	var out []string
+	if p != nil && p.ProvinceCode != "" {
+		out = append(out, placeToken(entity.PlaceProvince, p.ProvinceCode))
+	}
+	if d != nil && d.DistrictCode != "" {
+		out = append(out, placeToken(entity.PlaceDistrict, d.DistrictCode))
+	}
+	if s != nil && s.SubdistrictCode != "" {
+		out = append(out, placeToken(entity.PlaceSubdistrict, s.SubdistrictCode))
+	}
+	return out
+}
+
+// searchLexicon คือพจนานุกรมตัดคำ (คำพื้นฐาน + ชื่อพื้นที่ไทย) และตาราง thai.Key(ชื่อ) -> พื้นที่
+type searchLexicon struct {
+	seg    *thai.Segmenter
+	places map[string][]placeRef
+}
+
+var (
+	lexiconMu sync.Mutex
+	lexicon   *searchLexicon
+)
+
+// loadSearchLexicon โหลดจากฐานข้อมูลครั้งแรกที่ใช้ แล้วเก็บไว้จนกว่าข้อมูลพื้นที่/ชื่อเรียกอื่นจะเปลี่ยน
+// รับ tx จากผู้เรียกเพราะ sqlite เปิดได้ connection เดียว
+func loadSearchLexicon(tx *gorm.DB) (*searchLexicon, error) {
+	lexiconMu.Lock()
+	defer lexiconMu.Unlock()
+	if lexicon != nil {
+		return lexicon, nil
+	}
+
+	lex := &searchLexicon{seg: thai.Default(), places: map[string][]placeRef{}}
+	byCode := map[string]placeRef{}
+	add := func(alias string, p placeRef) {
+		key := thai.Key(alias)
+		if key == "" {
+			return
+		}
+		for _, q := range lex.places[key] {
+			if q.Level == p.Level && q.Code == p.Code {
+				return
+			}
+		}
+		lex.places[key] = append(lex.places[key], p)
+		lex.seg.Add(alias)
+	}
+	for _, t := range []struct {
+		level, table, code string
+	}{
+		{entity.PlaceProvince, "provinces", "province_code"},
+		{entity.PlaceDistrict, "districts", "district_code"},
+		{entity.PlaceSubdistrict, "subdistricts", "subdistrict_code"},
+	} {
+		var rows []placeRef
+		if err := tx.Table(t.table).
+			Select("? AS level, "+t.code+" AS code, name_th, name_en", t.level).
+			Where("deleted_at IS NULL").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range rows {
+			byCode[p.Level+":"+p.Code] = p
+			add(p.NameTh, p)
+			add(p.NameEn, p)
+		}
+	}
+
+	var aliases []entity.PlaceAlias
+	if err := tx.Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range aliases {
+		if p, ok := byCode[a.Level+":"+a.Code]; ok {
+			add(a.Alias, p)
+		}
+	}
+	for key, refs := range lex.places {
+		sort.SliceStable(refs, func(i, j int) bool { return placeLevels[refs[i].Level] < placeLevels[refs[j].Level] })
+		if len(refs) > maxAliasPlaces {
+			lex.places[key] = refs[:maxAliasPlaces]
+		}
+	}
+	lexicon = lex
+	return lex, nil
+}
+
+// resetSearchLexicon ให้โหลดใหม่ครั้งถัดไป เรียกหลังแก้ข้อมูลพื้นที่หรือชื่อเรียกอื่น
+func resetSearchLexicon() {
+	lexiconMu.Lock()
+	lexicon = nil
+	lexiconMu.Unlock()
+}
+
+// GET /search/alias?level=&code= - ชื่อเรียกอื่นของพื้นที่ที่เพิ่มเอง
+func FindPlaceAliases(c *gin.Context) {
+	db := dbFor(c)
+	if level := c.Query("level"); level != "" {
+		db = db.Where("level = ?", level)
+	}
+	if code := c.Query("code"); code != "" {
+		db = db.Where("code = ?", code)
+	}
+	var aliases []entity.PlaceAlias
+	if err := db.Order("level, code, alias").Find(&aliases).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": aliases})
+}
+
+// POST /search/alias - เพิ่มชื่อเรียกอื่น/คำทับศัพท์ของจังหวัด/อำเภอ/ตำบล (มีผลกับคำค้นทันที ไม่ต้อง reindex)
+func CreatePlaceAlias(c *gin.Context) {
+	var req entity.PlaceAlias
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	req.Alias = strings.TrimSpace(req.Alias)
+	req.Code = strings.TrimSpace(req.Code)
+	if thai.Key(req.Alias) == "" {
+		apierr.Respond(c, apierr.Required("alias"))
+		return
+	}
+
+	db := dbFor(c)
+	var model any
+	column := "province_code"
+	switch req.Level {
+	case entity.PlaceProvince:
+		model = &entity.Province{}
+	case entity.PlaceDistrict:
+		model, column = &entity.District{}, "district_code"
+	case entity.PlaceSubdistrict:
+		model, column = &entity.Subdistrict{}, "subdistrict_code"
+	default:
+		apierr.Respond(c, apierr.BadRequest("level must be province, district or subdistrict"))
+		return
+	}
+	var n int64
+	if err := db.Model(model).Where(column+" = ?", req.Code).Count(&n).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if n == 0 {
+		apierr.Respond(c, apierr.NotFound("No "+req.Level+" with code "+req.Code))
+		return
+	}
+	if err := db.Where("alias = ? AND level = ? AND code = ?", req.Alias, req.Level, req.Code).First(&entity.PlaceAlias{}).Error; err == nil {
+		apierr.Respond(c, apierr.Conflict("Alias already exists"))
+		return
+	}
+
+	alias := entity.PlaceAlias{Alias: req.Alias, Level: req.Level, Code: req.Code}
+	if err := db.Create(&alias).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create alias"))
+		return
+	}
+	resetSearchLexicon()
+	c.JSON(http.StatusCreated, gin.H{"data": alias, "message": "Alias created successfully"})
+}
+
+// DELETE /search/alias/:id
+func DeletePlaceAlias(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid alias ID format"))
+		return
+	}
+	// ลบจริงเพื่อให้เพิ่มชื่อเดิมกลับได้ (unique index)
+	res := dbFor(c).Unscoped().Delete(&entity.PlaceAlias{}, id)
+	if res.Error != nil {
+		apierr.Respond(c, apierr.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		apierr.Respond(c, apierr.NotFound("Alias not found"))
+		return
+	}
+	resetSearchLexicon()
+	c.JSON(http.StatusOK, gin.H{"message": "Alias deleted successfully"})
+}
@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"github.com/kookkikiv/sa_project/backend/pricing"
+	"gorm.io/gorm"
+)
+
+// QuoteRequest คือรายการที่ต้องการตีราคา ใช้ร่วมกันทั้ง /quote ตะกร้า และการจอง
+type QuoteRequest struct {
+	ItemType  string `json:"item_type"` // room|package|event
+	RoomID    *uint  `json:"room_id"`
+	PackageID *uint  `json:"package_id"`
+	EventID   *uint  `json:"event_id"`
+	CheckIn   string `json:"check_in"`  // "YYYY-MM-DD" ห้องพักต้องระบุ, แพ็คเกจใช้ start_date ของแพ็คเกจ
+	CheckOut  string `json:"check_out"` // "YYYY-MM-DD" ห้องพักต้องระบุ
+	Adults    uint   `json:"adults"`
+	Children  uint   `json:"children"`
+	Rooms     uint   `json:"rooms"` // ห้องพักจองได้ครั้งละ 1 ห้อง (Room ไม่มีจำนวนห้องในคลัง)
+}
+
+type PricingRuleRequest struct {
+	Name        *string `json:"name"`
+	Scope       *string `json:"scope"`
+	TargetID    *uint   `json:"target_id"`
+	Kind        *string `json:"kind"`
+	Percent     *int    `json:"percent"`
+	Days        *int    `json:"days"`
+	MinQuantity *int    `json:"min_quantity"`
+	Active      *bool   `json:"active"`
+	StartDate   *string `json:"start_date"` // "YYYY-MM-DD"
+	EndDate     *string `json:"end_date"`   // "YYYY-MM-DD"
+}
+
+// quotedItem คือผลตีราคาของรายการ พร้อมวันที่ที่ใช้จริง
+type quotedItem struct {
+	ItemType string
+	Start    time.Time
+	End      time.Time
+	Quote    pricing.Quote
+}
+
+// errQuote คือข้อผิดพลาดจากข้อมูลที่ผู้ใช้ส่งมา (ตอบ 400)
+type errQuote struct{ msg string }
+
+func (e errQuote) Error() string { return e.msg }
+
+func (e errQuote) Problem() *apierr.Error { return apierr.BadRequest(e.msg) }
+
+var pricingScopes = map[string]bool{"room": true, "package": true, "event": true}
+
+var pricingKinds = map[string]bool{
+	string(pricing.Season):     true,
+	string(pricing.Weekend):    true,
+	string(pricing.Child):      true,
+	string(pricing.EarlyBird):  true,
+	string(pricing.LastMinute): true,
+	string(pricing.Group):      true,
+}
+
+// validPriceUnit ยอมรับค่าว่าง (ใช้ค่าเริ่มต้นของ entity) หรือหน่วยที่ pricing รู้จัก
+func validPriceUnit(unit string) bool {
+	switch pricing.Unit(unit) {
+	case "", pricing.PerPerson, pricing.PerRoom:
+		return true
+	}
+	return false
+}
+
+// loadPricingRules ดึงกฎที่ active ของ scope นั้น ทั้งกฎรวมและกฎเฉพาะรายการ
+func loadPricingRules(tx *gorm.DB, scope string, targetID uint) ([]pricing.Rule, error) {
+	var rows []entity.PricingRule
+	if err := tx.
+		Where("active = ? AND scope = ?", true, scope).
+		Where("target_id IS NULL OR target_id = ?", targetID).
+		Order("target_id IS NOT NULL, id"). // กฎรวมก่อน กฎเฉพาะรายการทีหลัง (Evaluate ให้กฎเฉพาะชนะ)
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	rules := make([]pricing.Rule, 0, len(rows))
+	for _, r := range rows {
+		rule := pricing.Rule{ID: r.ID, Name: r.Name, Kind: pricing.RuleKind(r.Kind), Percent: r.Percent, Days: r.Days,
+			MinQuantity: r.MinQuantity, TargetID: r.TargetID}
+		if r.StartDate != nil {
+			rule.From = *r.StartDate
+		}
+		if r.EndDate != nil {
+			rule.To = *r.EndDate
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// quoteItem ตีราคา 1 รายการจากข้อมูลในฐานข้อมูล ณ เวลา bookedAt
+// ทุกจุดที่แสดงหรือเก็บราคา (quote, cart, booking, checkout) ต้องเรียกผ่านฟังก์ชันนี้
+func quoteItem(tx *gorm.DB, req QuoteRequest, bookedAt time.Time) (quotedItem, error) {
+	itemType := strings.ToLower(strings.TrimSpace(req.ItemType))
+	var (
+		base     money.Money
+		unit     pricing.Unit
+		nightly  bool
+		start    time.Time
+		end      time.Time
+		targetID uint
+	)
+
+	switch itemType {
+	case "room":
+		if req.RoomID == nil {
+			return quotedItem{}, errQuote{"room_id is required"}
+		}
+		var room entity.Room
+		if err := tx.Preload("Accommodation").First(&room, *req.RoomID).Error; err != nil {
+			return quotedItem{}, errQuote{"Room not found"}
+		}
+		if room.Status == "closed" || room.Accommodation.Status == "closed" {
+			return quotedItem{}, errQuote{"Room is closed"}
+		}
+		// Room 1 แถวคือห้องจริง 1 ห้อง (ไม่มีจำนวนห้องในคลัง) จองได้ครั้งละห้องเดียว
+		if req.Rooms > 1 {
+			return quotedItem{}, errQuote{"rooms must be 1: each room can only be booked once per stay"}
+		}
+		var err error
+		if start, err = parseYMD(req.CheckIn); err != nil || start.IsZero() {
+			return quotedItem{}, errQuote{"check_in must be YYYY-MM-DD"}
+		}
+		if end, err = parseYMD(req.CheckOut); err != nil || end.IsZero() {
+			return quotedItem{}, errQuote{"check_out must be YYYY-MM-DD"}
+		}
+		base, unit, nightly, targetID = money.FromMajor(int64(room.Price)), pricing.Unit(room.PriceUnit), true, room.ID
+		if unit == "" {
+			unit = pricing.PerRoom
+		}
+	case "package":
+		if req.PackageID == nil {
+			return quotedItem{}, errQuote{"package_id is required"}
+		}
+		var pack entity.Package
+		if err := tx.First(&pack, *req.PackageID).Error; err != nil {
+			return quotedItem{}, errQuote{"Package not found"}
+		}
+		if pack.StartDate.IsZero() {
+			return quotedItem{}, errQuote{"Package has no start date"}
+		}
+		start, end = pack.StartDate, pack.FinalDate
+		base, unit, targetID = money.FromMajor(int64(pack.Price)), pricing.Unit(pack.PriceUnit), pack.ID
+		if unit == "" {
+			unit = pricing.PerPerson
+		}
+	case "event":
+		if req.EventID == nil {
+			return quotedItem{}, errQuote{"event_id is required"}
+		}
+		var event entity.Event
+		if err := tx.First(&event, *req.EventID).Error; err != nil {
+			return quotedItem{}, errQuote{"Event not found"}
+		}
+		// อีเวนต์ยังไม่มีวันจัดงาน ใช้วันที่จองแทนในการหา season
+		start = bookedAt
+		base, unit, targetID = money.FromFloat(event.Price), pricing.PerPerson, event.ID
+	default:
+		return quotedItem{}, errQuote{"item_type must be room, package or event"}
+	}
+
+	rules, err := loadPricingRules(tx, itemType, targetID)
+	if err != nil {
+		return quotedItem{}, err
+	}
+	// อีเวนต์ไม่มีวันเริ่มจริง ระยะจองล่วงหน้าจึงเป็น 0 เสมอ ไม่ส่งวันที่จองเพื่อข้ามส่วนลด early_bird/last_minute
+	leadFrom := bookedAt
+	if itemType == "event" {
+		leadFrom = time.Time{}
+	}
+	q, err := pricing.Evaluate(pricing.Request{
+		BasePrice: base,
+		Unit:      unit,
+		Nightly:   nightly,
+		Start:     start,
+		End:       end,
+		Adults:    int(req.Adults),
+		Children:  int(req.Children),
+		Rooms:     int(req.Rooms),
+		BookedAt:  leadFrom,
+	}, rules)
+	if err != nil {
+		return quotedItem{}, errQuote{strings.TrimPrefix(err.Error(), "pricing: ")}
+	}
+	return quotedItem{ItemType: itemType, Start: start, End: end, Quote: q}, nil
+}
+
+// POST /quote - ตีราคาแบบแจกแจงโดยไม่บันทึกอะไร
+func QuotePrice(c *gin.Context) {
+	var req QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	item, err := quoteItem(dbFor(c), req, time.Now())
+	if err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item.Quote})
+}
+
+// GET /pricing-rule?scope=&target_id=&kind=
+func FindPricingRules(c *gin.Context) {
+	q := dbFor(c).Order("id")
+	if v := c.Query("scope"); v != "" {
+		q = q.Where("scope = ?", v)
+	}
+	if v := c.Query("target_id"); v != "" {
+		q = q.Where("target_id = ?", v)
+	}
+	if v := c.Query("kind"); v != "" {
+		q = q.Where("kind = ?", v)
+	}
+	var rules []entity.PricingRule
+	if err := q.Find(&rules).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// applyPricingRuleRequest ใส่ค่าจาก request ลง rule แล้วตรวจความถูกต้อง
+func applyPricingRuleRequest(rule *entity.PricingRule, req PricingRuleRequest) string {
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Scope != nil {
+		rule.Scope = strings.ToLower(strings.TrimSpace(*req.Scope))
+	}
+	if req.TargetID != nil {
+		rule.TargetID = req.TargetID
+		if *req.TargetID == 0 {
+			rule.TargetID = nil
+		}
+	}
+	if req.Kind != nil {
+		rule.Kind = strings.ToLower(strings.TrimSpace(*req.Kind))
+	}
+	if req.Percent != nil {
+		rule.Percent = *req.Percent
+	}
+	if req.Days != nil {
+		rule.Days = *req.Days
+	}
+	if req.MinQuantity != nil {
+		rule.MinQuantity = *req.MinQuantity
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	if req.StartDate != nil {
+		t, err := parseYMD(*req.StartDate)
+		if err != nil {
+			return "start_date must be YYYY-MM-DD"
+		}
+		rule.StartDate = nil
+		if !t.IsZero() {
+			rule.StartDate = &t
+		}
+	}
+	if req.EndDate != nil {
+		t, err := parseYMD(*req.EndDate)
+		if err != nil {
+			return "end_date must be YYYY-MM-DD"
+		}
+		rule.EndDate = nil
+		if !t.IsZero() {
+			rule.EndDate = &t
+		}
+	}
+
+	if rule.Name == "" {
+		return "name is required"
+	}
+	if !pricingScopes[rule.Scope] {
+		return "scope must be room, package or event"
+	}
+	if !pricingKinds[rule.Kind] {
+		return "kind must be season, weekend, child, early_bird, last_minute or group"
+	}
+	if rule.Percent < -100 {
+		return "percent must not be less than -100"
+	}
+	switch pricing.RuleKind(rule.Kind) {
+	case pricing.Season:
+		if rule.StartDate == nil || rule.EndDate == nil {
+			return "season rule requires start_date and end_date"
+		}
+		if rule.EndDate.Before(*rule.StartDate) {
+			return "end_date must be on or after start_date"
+		}
+	case pricing.Child:
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return "child percent must be between 0 and 100"
+		}
+	case pricing.EarlyBird, pricing.LastMinute:
+		if rule.Scope == "event" {
+			return "early_bird and last_minute rules need a start date and cannot use scope event"
+		}
+		if rule.Percent >= 0 {
+			return "discount percent must be negative"
+		}
+		if rule.Days < 0 {
+			return "days must not be negative"
+		}
+	case pricing.Group:
+		if rule.Percent >= 0 {
+			return "group percent must be negative"
+		}
+		if rule.MinQuantity < 2 {
+			return "group rule requires min_quantity of at least 2"
+		}
+	}
+	return ""
+}
+
+// POST /pricing-rule
+func CreatePricingRule(c *gin.Context) {
+	var req PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	rule := entity.PricingRule{Active: true}
+	if msg := applyPricingRuleRequest(&rule, req); msg != "" {
+		apierr.Respond(c, apierr.BadRequest(msg))
+		return
+	}
+	if err := dbFor(c).Create(&rule).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create pricing rule"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": rule, "message": "Pricing rule created successfully"})
+}
+
+// PUT /pricing-rule/:id
+func UpdatePricingRule(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid pricing rule ID format"))
+		return
+	}
+	var req PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	var rule entity.PricingRule
+	if err := db.First(&rule, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Pricing rule not found"))
+		return
+	}
+	if msg := applyPricingRuleRequest(&rule, req); msg != "" {
+		apierr.Respond(c, apierr.BadRequest(msg))
+		return
+	}
+	if err := db.Save(&rule).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update pricing rule"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule, "message": "Pricing rule updated successfully"})
+}
+
+// DELETE /pricing-rule/:id
+func DeletePricingRule(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid pricing rule ID format"))
+		return
+	}
+	res := dbFor(c).Delete(&entity.PricingRule{}, id)
+	if res.Error != nil {
+		apierr.Respond(c, apierr.Internal(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		apierr.Respond(c, apierr.NotFound("Pricing rule not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing rule deleted successfully"})
+}
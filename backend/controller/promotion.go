@@ -0,0 +1,622 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+	"gorm.io/gorm"
+)
+
+type PromotionScopeRequest struct {
+	Kind        string `json:"kind"` // package|event|province|accommodation_type
+	TargetID    *uint  `json:"target_id"`
+	TargetValue string `json:"target_value"` // accommodation_type เช่น "hotel"
+}
+
+type PromotionRequest struct {
+	Code           *string      `json:"code"`
+	Name           *string      `json:"name"`
+	Kind           *string      `json:"kind"` // percent|fixed
+	Value          *int64       `json:"value"`
+	MinSpend       *money.Money `json:"min_spend"` // หน่วยย่อย (สตางค์)
+	Active         *bool        `json:"active"`
+	StartDate      *string      `json:"start_date"` // "YYYY-MM-DD"
+	EndDate        *string      `json:"end_date"`   // "YYYY-MM-DD" (ใช้ได้ถึงสิ้นวัน)
+	UsageLimit     *uint        `json:"usage_limit"`
+	PerMemberLimit *uint        `json:"per_member_limit"`
+
+	// ไม่ส่ง = ไม่แตะ, ส่ง [] = ใช้ได้กับทุกรายการ
+	Scopes *[]PromotionScopeRequest `json:"scopes"`
+}
+
+type PromotionCheckRequest struct {
+	Code     string `json:"code"`
+	MemberID *uint  `json:"member_id"`
+}
+
+// promoLine คือรายการ 1 รายการที่นำไปคิดส่วนลด
+type promoLine struct {
+	ItemType  string
+	PackageID *uint
+	EventID   *uint
+	RoomID    *uint
+	Total     money.Money
+}
+
+// promoResult คือผลคิดส่วนลด Allocations คือส่วนลดที่แบ่งให้แต่ละรายการ (ลำดับเดียวกับ lines)
+type promoResult struct {
+	Promotion   entity.Promotion
+	Eligible    money.Money
+	Discount    money.Money
+	Allocations []money.Money
+}
+
+var promotionScopeKinds = map[string]bool{
+	entity.PromotionScopePackage:           true,
+	entity.PromotionScopeEvent:             true,
+	entity.PromotionScopeProvince:          true,
+	entity.PromotionScopeAccommodationType: true,
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// lineProvinceID หาจังหวัดของรายการ (แพ็คเกจ/อีเวนต์ใช้ของตัวเอง ห้องพักใช้ของที่พัก)
+func lineProvinceID(tx *gorm.DB, l promoLine) (*uint, error) {
+	switch {
+	case l.PackageID != nil:
+		var p entity.Package
+		if err := tx.Select("province_id").First(&p, *l.PackageID).Error; err != nil {
+			return nil, err
+		}
+		return p.ProvinceID, nil
+	case l.EventID != nil:
+		var e entity.Event
+		if err := tx.Select("province_id").First(&e, *l.EventID).Error; err != nil {
+			return nil, err
+		}
+		return e.ProvinceID, nil
+	case l.RoomID != nil:
+		var a entity.Accommodation
+		if err := tx.Select("accommodations.province_id").
+			Joins("JOIN rooms ON rooms.accommodation_id = accommodations.id").
+			Where("rooms.id = ?", *l.RoomID).
+			First(&a).Error; err != nil {
+			return nil, err
+		}
+		return a.ProvinceID, nil
+	}
+	return nil, nil
+}
+
+// lineAccommodationTypes หาประเภทที่พักของรายการ (ห้องพัก = ที่พักของห้อง, แพ็คเกจ = ที่พักใน stays)
+func lineAccommodationTypes(tx *gorm.DB, l promoLine) ([]string, error) {
+	var types []string
+	q := tx.Model(&entity.Accommodation{}).Distinct("accommodations.type")
+	switch {
+	case l.RoomID != nil:
+		q = q.Joins("JOIN rooms ON rooms.accommodation_id = accommodations.id").Where("rooms.id = ?", *l.RoomID)
+	case l.PackageID != nil:
+		q = q.Joins("JOIN package_stays ON package_stays.accommodation_id = accommodations.id").
+			Where("package_stays.package_id = ? AND package_stays.deleted_at IS NULL", *l.PackageID)
+	default:
+		return nil, nil
+	}
+	err := q.Pluck("accommodations.type", &types).Error
+	return types, err
+}
+
+// lineInScope ตรวจว่ารายการเข้าเงื่อนไข scope ข้อใดข้อหนึ่งของโค้ด
+func lineInScope(tx *gorm.DB, l promoLine, scopes []entity.PromotionScope) (bool, error) {
+	if len(scopes) == 0 {
+		return true, nil
+	}
+	for _, s := range scopes {
+		switch s.Kind {
+		case entity.PromotionScopePackage:
+			if l.PackageID != nil && s.TargetID != nil && *l.PackageID == *s.TargetID {
+				return true, nil
+			}
+		case entity.PromotionScopeEvent:
+			if l.EventID != nil && s.TargetID != nil && *l.EventID == *s.TargetID {
+				return true, nil
+			}
+		case entity.PromotionScopeProvince:
+			provinceID, err := lineProvinceID(tx, l)
+			if err != nil {
+				return false, err
+			}
+			if provinceID != nil && s.TargetID != nil && *provinceID == *s.TargetID {
+				return true, nil
+			}
+		case entity.PromotionScopeAccommodationType:
+			types, err := lineAccommodationTypes(tx, l)
+			if err != nil {
+				return false, err
+			}
+			for _, t := range types {
+				if strings.EqualFold(t, s.TargetValue) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// evaluatePromotion ตรวจโค้ดกับรายการแล้วคิดส่วนลด (ยังไม่นับการใช้ ดู redeemPromotion)
+func evaluatePromotion(tx *gorm.DB, code string, memberID uint, lines []promoLine, now time.Time) (promoResult, error) {
+	var res promoResult
+	if err := tx.Preload("Scopes").Where("code = ?", normalizePromoCode(code)).First(&res.Promotion).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return res, errQuote{"Promotion code not found"}
+		}
+		return res, err
+	}
+	p := res.Promotion
+	if !p.Active {
+		return res, errQuote{"Promotion code is not active"}
+	}
+	if p.StartDate != nil && now.Before(*p.StartDate) {
+		return res, errQuote{"Promotion code is not valid yet"}
+	}
+	if p.EndDate != nil && !now.Before(p.EndDate.AddDate(0, 0, 1)) {
+		return res, errQuote{"Promotion code has expired"}
+	}
+	if p.UsageLimit != nil && p.UsedCount >= *p.UsageLimit {
+		return res, errQuote{"Promotion code usage limit reached"}
+	}
+	if p.PerMemberLimit != nil {
+		var used int64
+		if err := tx.Model(&entity.PromotionRedemption{}).
+			Where("promotion_id = ? AND member_id = ?", p.ID, memberID).
+			Count(&used).Error; err != nil {
+			return res, err
+		}
+		if used >= int64(*p.PerMemberLimit) {
+			return res, errQuote{"Promotion code already used the maximum number of times"}
+		}
+	}
+
+	eligible := make([]bool, len(lines))
+	for i, l := range lines {
+		ok, err := lineInScope(tx, l, p.Scopes)
+		if err != nil {
+			return res, err
+		}
+		if ok {
+			eligible[i] = true
+			res.Eligible += l.Total
+		}
+	}
+	if res.Eligible == 0 {
+		return res, errQuote{"Promotion code does not apply to any item"}
+	}
+	if res.Eligible < p.MinSpend {
+		return res, errQuote{"Minimum spend for this promotion code is not met"}
+	}
+
+	switch p.Kind {
+	case entity.PromotionPercent:
+		res.Discount = (res.Eligible*money.Money(p.Value) + 50) / 100
+	case entity.PromotionFixed:
+		res.Discount = money.Money(p.Value)
+	}
+	if res.Discount > res.Eligible {
+		res.Discount = res.Eligible
+	}
+
+	res.Allocations = allocateDiscount(res.Discount, res.Eligible, lines, eligible)
+	return res, nil
+}
+
+// allocateDiscount แบ่งส่วนลดตามสัดส่วนยอดของรายการที่เข้าเงื่อนไข ผลรวมเท่าส่วนลดพอดี
+// เศษแจกทีละ 1 หน่วยให้รายการที่เศษมากที่สุดก่อน (เท่ากันให้รายการแรก) จึงไม่มีรายการใดได้ส่วนลดเกินยอดของตัวเอง
+func allocateDiscount(discount, eligibleTotal money.Money, lines []promoLine, eligible []bool) []money.Money {
+	out := make([]money.Money, len(lines))
+	remainders := make([]money.Money, len(lines))
+	var order []int
+	var given money.Money
+	for i, l := range lines {
+		if !eligible[i] {
+			continue
+		}
+		out[i] = discount * l.Total / eligibleTotal
+		remainders[i] = discount * l.Total % eligibleTotal
+		given += out[i]
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:int(discount-given)] {
+		out[i]++
+	}
+	return out
+}
+
+// redeemPromotion นับการใช้โค้ดแล้วบันทึก redemption ภายใน transaction เดียวกับ checkout
+// เพิ่ม used_count ด้วย UPDATE แบบมีเงื่อนไข ทำให้ checkout พร้อมกันใช้เกิน usage_limit ไม่ได้
+func redeemPromotion(tx *gorm.DB, res promoResult, memberID, reservationID uint) error {
+	p := res.Promotion
+	r := tx.Model(&entity.Promotion{}).
+		Where("id = ? AND (usage_limit IS NULL OR used_count < usage_limit)", p.ID).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.RowsAffected == 0 {
+		return errQuote{"Promotion code usage limit reached"}
+	}
+	// นับซ้ำหลัง UPDATE (ถือ write lock แล้ว) เพื่อกัน checkout พร้อมกันของสมาชิกคนเดียว
+	if p.PerMemberLimit != nil {
+		var used int64
+		if err := tx.Model(&entity.PromotionRedemption{}).
+			Where("promotion_id = ? AND member_id = ?", p.ID, memberID).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		if used >= int64(*p.PerMemberLimit) {
+			return errQuote{"Promotion code already used the maximum number of times"}
+		}
+	}
+	return tx.Create(&entity.PromotionRedemption{
+		PromotionID:   p.ID,
+		MemberID:      memberID,
+		ReservationID: reservationID,
+		Code:          p.Code,
+		Amount:        res.Discount,
+	}).Error
+}
+
+// releasePromotion คืนสิทธิ์โค้ดที่ reservation ใช้ไป (ตอนยกเลิก) ลบ redemption แบบ soft delete ให้ยังตรวจย้อนหลังได้
+// แล้วลด used_count โดยไม่ให้ติดลบ ต้องเรียกใน transaction เดียวกับที่ยกเลิก reservation
+func releasePromotion(tx *gorm.DB, reservationID uint) error {
+	var redemption entity.PromotionRedemption
+	if err := tx.Where("reservation_id = ?", reservationID).First(&redemption).Error; err != nil {
+		return ignoreNotFound(err)
+	}
+	if err := tx.Delete(&redemption).Error; err != nil {
+		return err
+	}
+	return tx.Model(&entity.Promotion{}).
+		Where("id = ? AND used_count > 0", redemption.PromotionID).
+		UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// GET /promotion?active=
+func FindPromotions(c *gin.Context) {
+	q := dbFor(c).Preload("Scopes").Order("id DESC")
+	if v := c.Query("active"); v != "" {
+		q = q.Where("active = ?", v == "true" || v == "1")
+	}
+	var promos []entity.Promotion
+	if err := q.Find(&promos).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promos})
+}
+
+// GET /promotion/:id
+func FindPromotionById(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid promotion ID format"))
+		return
+	}
+	var promo entity.Promotion
+	if err := dbFor(c).Preload("Scopes").First(&promo, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Promotion not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promo})
+}
+
+// applyPromotionRequest ใส่ค่าจาก request ลง promotion แล้วตรวจความถูกต้อง
+func applyPromotionRequest(p *entity.Promotion, req PromotionRequest) string {
+	if req.Code != nil {
+		p.Code = normalizePromoCode(*req.Code)
+	}
+	if req.Name != nil {
+		p.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Kind != nil {
+		p.Kind = strings.ToLower(strings.TrimSpace(*req.Kind))
+	}
+	if req.Value != nil {
+		p.Value = *req.Value
+	}
+	if req.MinSpend != nil {
+		p.MinSpend = *req.MinSpend
+	}
+	if req.Active != nil {
+		p.Active = *req.Active
+	}
+	if req.UsageLimit != nil {
+		p.UsageLimit = req.UsageLimit
+		if *req.UsageLimit == 0 {
+			p.UsageLimit = nil
+		}
+	}
+	if req.PerMemberLimit != nil {
+		p.PerMemberLimit = req.PerMemberLimit
+		if *req.PerMemberLimit == 0 {
+			p.PerMemberLimit = nil
+		}
+	}
+	if req.StartDate != nil {
+		t, err := parseYMD(*req.StartDate)
+		if err != nil {
+			return "start_date must be YYYY-MM-DD"
+		}
+		p.StartDate = nil
+		if !t.IsZero() {
+			p.StartDate = &t
+		}
+	}
+	if req.EndDate != nil {
+		t, err := parseYMD(*req.EndDate)
+		if err != nil {
+			return "end_date must be YYYY-MM-DD"
+		}
+		p.EndDate = nil
+		if !t.IsZero() {
+			p.EndDate = &t
+		}
+	}
+
+	if p.Code == "" {
+		return "code is required"
+	}
+	switch p.Kind {
+	case entity.PromotionPercent:
+		if p.Value < 1 || p.Value > 100 {
+			return "percent value must be between 1 and 100"
+		}
+	case entity.PromotionFixed:
+		if p.Value < 1 {
+			return "fixed value must be greater than 0"
+		}
+	default:
+		return "kind must be percent or fixed"
+	}
+	if p.MinSpend < 0 {
+		return "min_spend must not be negative"
+	}
+	if p.StartDate != nil && p.EndDate != nil && p.EndDate.Before(*p.StartDate) {
+		return "end_date must be on or after start_date"
+	}
+	if req.Scopes != nil {
+		for _, s := range *req.Scopes {
+			kind := strings.ToLower(strings.TrimSpace(s.Kind))
+			if !promotionScopeKinds[kind] {
+				return "scope kind must be package, event, province or accommodation_type"
+			}
+			if kind == entity.PromotionScopeAccommodationType {
+				if strings.TrimSpace(s.TargetValue) == "" {
+					return "accommodation_type scope requires target_value"
+				}
+			} else if s.TargetID == nil {
+				return kind + " scope requires target_id"
+			}
+		}
+	}
+	return ""
+}
+
+func promotionScopes(reqs []PromotionScopeRequest) []entity.PromotionScope {
+	scopes := make([]entity.PromotionScope, 0, len(reqs))
+	for _, s := range reqs {
+		kind := strings.ToLower(strings.TrimSpace(s.Kind))
+		scope := entity.PromotionScope{Kind: kind}
+		if kind == entity.PromotionScopeAccommodationType {
+			scope.TargetValue = strings.TrimSpace(s.TargetValue)
+		} else {
+			scope.TargetID = s.TargetID
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
+// POST /promotion
+func CreatePromotion(c *gin.Context) {
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	promo := entity.Promotion{Active: true}
+	if msg := applyPromotionRequest(&promo, req); msg != "" {
+		apierr.Respond(c, apierr.BadRequest(msg))
+		return
+	}
+	if req.Scopes != nil {
+		promo.Scopes = promotionScopes(*req.Scopes)
+	}
+
+	db := dbFor(c)
+	// code เป็น uniqueIndex ที่รวมแถวที่ลบแบบ soft delete ด้วย จึงต้องนับแบบ Unscoped
+	var count int64
+	if err := db.Unscoped().Model(&entity.Promotion{}).Where("code = ?", promo.Code).Count(&count).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if count > 0 {
+		apierr.Respond(c, apierr.Conflict("Promotion code already exists"))
+		return
+	}
+	if err := db.Create(&promo).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create promotion"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": promo, "message": "Promotion created successfully"})
+}
+
+// PUT /promotion/:id
+func UpdatePromotionById(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid promotion ID format"))
+		return
+	}
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var promo entity.Promotion
+	if err := tx.First(&promo, id).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.NotFound("Promotion not found"))
+		return
+	}
+	if msg := applyPromotionRequest(&promo, req); msg != "" {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest(msg))
+		return
+	}
+	var count int64
+	if err := tx.Unscoped().Model(&entity.Promotion{}).Where("code = ? AND id <> ?", promo.Code, promo.ID).Count(&count).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if count > 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Promotion code already exists"))
+		return
+	}
+	if err := tx.Omit("Scopes").Save(&promo).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update promotion"))
+		return
+	}
+	if req.Scopes != nil {
+		if err := tx.Unscoped().Where("promotion_id = ?", promo.ID).Delete(&entity.PromotionScope{}).Error; err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to update scopes"))
+			return
+		}
+		scopes := promotionScopes(*req.Scopes)
+		for i := range scopes {
+			scopes[i].PromotionID = promo.ID
+		}
+		if len(scopes) > 0 {
+			if err := tx.Create(&scopes).Error; err != nil {
+				tx.Rollback()
+				apierr.Respond(c, apierr.Wrap(err, "Failed to update scopes"))
+				return
+			}
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	db.Preload("Scopes").First(&promo, promo.ID)
+	c.JSON(http.StatusOK, gin.H{"data": promo, "message": "Promotion updated successfully"})
+}
+
+// DELETE /promotion/:id - โค้ดที่เคยถูกใช้แล้วปิดได้อย่างเดียว (active=false) เพื่อเก็บประวัติ
+func DeletePromotionById(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid promotion ID format"))
+		return
+	}
+	db := dbFor(c)
+	var promo entity.Promotion
+	if err := db.First(&promo, id).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Promotion not found"))
+		return
+	}
+	var used int64
+	db.Model(&entity.PromotionRedemption{}).Where("promotion_id = ?", promo.ID).Count(&used)
+	if used > 0 {
+		apierr.Respond(c, apierr.Conflict("Promotion has redemptions; deactivate it instead"))
+		return
+	}
+	if err := db.Select("Scopes").Delete(&promo).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+// GET /promotion/:id/redemptions
+func FindPromotionRedemptions(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid promotion ID format"))
+		return
+	}
+	var rows []entity.PromotionRedemption
+	if err := dbFor(c).Where("promotion_id = ?", id).Order("id").Find(&rows).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+// POST /promotion/check - ตรวจโค้ดกับตะกร้าปัจจุบันของสมาชิก (ไม่นับการใช้)
+func CheckPromotion(c *gin.Context) {
+	var req PromotionCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	db := dbFor(c)
+	if !memberExists(db, req.MemberID) {
+		apierr.Respond(c, apierr.BadRequest("Member not found"))
+		return
+	}
+
+	now := time.Now()
+	var cartItems []entity.CartItems
+	if err := memberCartItems(db, *req.MemberID).Find(&cartItems).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	lines := make([]promoLine, 0, len(cartItems))
+	for _, ci := range cartItems {
+		item, err := quoteItem(db, cartItemQuoteRequest(ci), now)
+		if err != nil {
+			apierr.Respond(c, err)
+			return
+		}
+		lines = append(lines, cartPromoLine(ci, item))
+	}
+	if len(lines) == 0 {
+		apierr.Respond(c, apierr.BadRequest("Cart is empty"))
+		return
+	}
+
+	res, err := evaluatePromotion(db, req.Code, *req.MemberID, lines, now)
+	if err != nil {
+		apierr.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"code":     res.Promotion.Code,
+		"eligible": res.Eligible,
+		"discount": res.Discount,
+	}})
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+// ส่วนลดที่แบ่งให้แต่ละรายการรวมกันเท่าส่วนลดพอดี ไม่เกินยอดของรายการ และรายการนอกเงื่อนไขได้ 0
+func TestEvaluatePromotionAllocationsSumToDiscount(t *testing.T) {
+	db := openTestDB(t)
+	pkg := func(id uint, total money.Money) promoLine {
+		return promoLine{ItemType: "package", PackageID: &id, Total: total}
+	}
+	scopedTo := uint(1)
+
+	cases := []struct {
+		name     string
+		kind     string
+		value    int64
+		scopes   []entity.PromotionScope
+		lines    []promoLine
+		discount money.Money
+		want     []money.Money
+	}{
+		{"percent even split", entity.PromotionPercent, 15, nil,
+			[]promoLine{pkg(1, 3333), pkg(2, 3333), pkg(3, 3334)}, 1500, []money.Money{500, 500, 500}},
+		{"remainder never exceeds a line", entity.PromotionPercent, 50, nil,
+			[]promoLine{pkg(1, 1), pkg(2, 1), pkg(3, 1)}, 2, []money.Money{1, 1, 0}},
+		{"fixed capped at eligible total", entity.PromotionFixed, 5000, nil,
+			[]promoLine{pkg(1, 1000), pkg(2, 999)}, 1999, []money.Money{1000, 999}},
+		{"fixed over small lines", entity.PromotionFixed, 7, nil,
+			[]promoLine{pkg(1, 3), pkg(2, 3), pkg(3, 3), pkg(4, 3)}, 7, []money.Money{2, 2, 2, 1}},
+		{"scoped to one package", entity.PromotionPercent, 10,
+			[]entity.PromotionScope{{Kind: entity.PromotionScopePackage, TargetID: &scopedTo}},
+			[]promoLine{pkg(1, 5000), pkg(2, 7000), pkg(1, 2501)}, 750, []money.Money{500, 0, 250}},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			promo := entity.Promotion{
+				Code: fmt.Sprintf("ALLOC%d", i), Kind: tc.kind, Value: tc.value, Active: true, Scopes: tc.scopes,
+			}
+			mustCreate(t, db, &promo)
+
+			res, err := evaluatePromotion(db, promo.Code, 1, tc.lines, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Discount != tc.discount {
+				t.Errorf("discount = %d, want %d", res.Discount, tc.discount)
+			}
+			var sum money.Money
+			for j, a := range res.Allocations {
+				sum += a
+				if a < 0 || a > tc.lines[j].Total {
+					t.Errorf("allocation[%d] = %d, outside 0..%d", j, a, tc.lines[j].Total)
+				}
+				if a != tc.want[j] {
+					t.Errorf("allocation[%d] = %d, want %d", j, a, tc.want[j])
+				}
+			}
+			if sum != res.Discount {
+				t.Errorf("allocations sum to %d, want %d", sum, res.Discount)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// POST /reservation/:id/cancel - ยกเลิกการจอง คืนที่นั่งแพ็คเกจ/อีเวนต์ ยกเลิกห้องที่จองไว้ คืนสิทธิ์โค้ดโปรโมชัน
+// แล้วเสนอที่นั่งให้คิว waitlist ถัดไป
+// การจองที่ลงบัญชีแล้วจะกลับรายการบัญชีให้ ยกเว้นค่าไกด์เข้ารอบจ่ายแล้ว (409 ต้องยกเลิกรอบจ่ายก่อน)
+func CancelReservation(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid reservation ID format"))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var reservation entity.Reservation
+	if err := tx.First(&reservation, id).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			apierr.Respond(c, apierr.NotFound("Reservation not found"))
+		} else {
+			apierr.Respond(c, apierr.Internal(err))
+		}
+		return
+	}
+	if reservation.Status == "cancelled" {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Reservation is already cancelled"))
+		return
+	}
+	if err := reverseReservationEntries(tx, reservation.ID); err != nil {
+		tx.Rollback()
+		if errors.Is(err, errGuideFeeBatched) {
+			apierr.Respond(c, apierr.Conflict("Guide fee for this reservation is already in a payout batch"))
+		} else {
+			apierr.Respond(c, apierr.Wrap(err, "Failed to reverse ledger entries"))
+		}
+		return
+	}
+
+	if err := tx.Model(&reservation).Update("status", "cancelled").Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to cancel reservation"))
+		return
+	}
+	query := tx.Where("reservation_id = ? AND status IN ?", reservation.ID, []string{entity.SeatReserved, entity.SeatConfirmed})
+	if _, err := releaseSeats(tx, query, entity.SeatReleased, time.Now()); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to release seats"))
+		return
+	}
+	// ห้องที่จองตอน checkout ของ reservation นี้ยกเลิกตามด้วย ห้องจึงว่างให้คนอื่นจองได้
+	bookingIDs := tx.Model(&entity.ReservationItem{}).Select("booking_id").
+		Where("reservation_id = ? AND booking_id IS NOT NULL", reservation.ID)
+	if err := tx.Model(&entity.Booking{}).Where("id IN (?)", bookingIDs).
+		Update("status_booking", "cancelled").Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to cancel bookings"))
+		return
+	}
+	if err := releasePromotion(tx, reservation.ID); err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to release promotion code"))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reservation, "message": "Reservation cancelled successfully"})
+}
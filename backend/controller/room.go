@@ -1,194 +1,244 @@
 package controller
 
 import (
-    "net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+	"net/http"
 	"strings"
-    "github.com/gin-gonic/gin"
-    "github.com/kookkikiv/sa_project/backend/config"
-    "github.com/kookkikiv/sa_project/backend/entity"
 )
 
 // ---------- DTOs สำหรับสร้าง/แก้ไขห้อง ----------
 type RoomCreateReq struct {
-    Name            string   `json:"name"`
-    Type            string   `json:"type"`
-    BedType         string   `json:"bed_type"`
-    Price           uint  `json:"price"`
-    People          uint     `json:"people"`
-    Status          string   `json:"status"`
-    AccommodationID *uint     `json:"accommodation_id"`
-    AdminID         *uint    `json:"admin_id"`     // จะใช้หรือไม่ใช้ก็ได้
-    PictureURLs     []string `json:"picture_urls"` // ✅ ใหม่
+	Name            string   `json:"name" binding:"required"`
+	Type            string   `json:"type"`
+	BedType         string   `json:"bed_type"`
+	Price           uint     `json:"price"`
+	PriceUnit       string   `json:"price_unit"` // per_room (ค่าเริ่มต้น) | per_person
+	People          uint     `json:"people"`
+	Status          string   `json:"status"`
+	AccommodationID *uint    `json:"accommodation_id"`
+	AdminID         *uint    `json:"admin_id"`     // จะใช้หรือไม่ใช้ก็ได้
+	PictureURLs     []string `json:"picture_urls"` // ✅ ใหม่
 }
 
 type RoomUpdateReq struct {
-    Name            *string   `json:"name"`
-    Type            *string   `json:"type"`
-    BedType         *string   `json:"bed_type"`
-    Price           *uint  `json:"price"`
-    People          *uint     `json:"people"`
-    Status          *string   `json:"status"`
-    AccommodationID *uint     `json:"accommodation_id"`
-    AdminID         *uint     `json:"admin_id"`
-    PictureURLs     *[]string `json:"picture_urls"` // ✅ ส่งมา = แทนที่รูปทั้งหมด
+	Name            *string   `json:"name"`
+	Type            *string   `json:"type"`
+	BedType         *string   `json:"bed_type"`
+	Price           *uint     `json:"price"`
+	PriceUnit       *string   `json:"price_unit"`
+	People          *uint     `json:"people"`
+	Status          *string   `json:"status"`
+	AccommodationID *uint     `json:"accommodation_id"`
+	AdminID         *uint     `json:"admin_id"`
+	PictureURLs     *[]string `json:"picture_urls"` // ✅ ส่งมา = แทนที่รูปทั้งหมด
 }
 
-// GET /room
+var roomList = listSpec{
+	table: "rooms",
+	sorts: map[string]string{"name": "name", "price": "price", "people": "people", "created_at": "created_at"},
+	filters: map[string]string{"accommodation_id": "accommodation_id", "type": "type", "bed_type": "bed_type",
+		"status": "status", "price_unit": "price_unit"},
+}
+
+// GET /room?page=&page_size=&sort=-price&accommodation_id=
 func FindRoom(c *gin.Context) {
-    var items []entity.Room
-    if err := config.DB().
-        Preload("Accommodation").
-        Preload("Facilities").
-        Preload("Pictures"). // ✅ preload รูป
-        Find(&items).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
-    c.JSON(http.StatusOK, gin.H{"data": items})
+	conv, ok := currencyFromQuery(c, dbFor(c))
+	if !ok {
+		return
+	}
+	var items []entity.Room
+	page, ok := roomList.find(c, dbFor(c), &items, func(db *gorm.DB) *gorm.DB {
+		return db.
+			Preload("Accommodation").
+			Preload("Facilities").
+			Preload("Pictures") // ✅ preload รูป
+	})
+	if !ok {
+		return
+	}
+	for i := range items {
+		conv.applyRoom(&items[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items, "pagination": page})
 }
 
 // GET /room/:id
 func FindRoomById(c *gin.Context) {
-    var item entity.Room
-    id := c.Param("id")
-
-    if err := config.DB().
-        Preload("Accommodation").
-        Preload("Facilities").
-        Preload("Pictures"). // ✅ preload รูป
-        Where("id = ?", id).
-        First(&item).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
-        return
-    }
-    c.JSON(http.StatusOK, gin.H{"data": item})
+	var item entity.Room
+	id := c.Param("id")
+	conv, ok := currencyFromQuery(c, dbFor(c))
+	if !ok {
+		return
+	}
+
+	if err := dbFor(c).
+		Preload("Accommodation").
+		Preload("Facilities").
+		Preload("Pictures"). // ✅ preload รูป
+		Where("id = ?", id).
+		First(&item).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("room not found"))
+		return
+	}
+	conv.applyRoom(&item)
+	c.JSON(http.StatusOK, gin.H{"data": item})
 }
 
 // POST /room
 // POST /room
 func CreateRoom(c *gin.Context) {
-    var req RoomCreateReq
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
-        return
-    }
-
-    room := entity.Room{
-        Name:            req.Name,
-        Type:            req.Type,
-        BedType:         req.BedType,
-        Price:           req.Price,
-        People:          req.People,
-        Status:          req.Status,
-        AccommodationID: req.AccommodationID,
-    }
-
-    if err := config.DB().Create(&room).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room: " + err.Error()})
-        return
-    }
-
-    // แนบรูป (owner_type = "room")
-    for _, u := range req.PictureURLs {
-        if strings.TrimSpace(u) == "" {
-            continue
-        }
-        pic := entity.Picture{
-            Url:       u,
-            OwnerType: "room",
-            OwnerID:   room.ID,
-        }
-        _ = config.DB().Create(&pic).Error // ถ้าพลาดบางรูป ข้ามไป (ไม่ล้มทั้งคำสั่ง)
-    }
-
-    // reload พร้อมความสัมพันธ์
-    _ = config.DB().
-        Preload("Accommodation").
-        Preload("Facilities").
-        Preload("Pictures").
-        First(&room, room.ID)
-
-    c.JSON(http.StatusCreated, gin.H{"data": room, "message": "Room created successfully"})
+	var req RoomCreateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	if !validPriceUnit(req.PriceUnit) {
+		apierr.Respond(c, apierr.BadRequest("price_unit must be per_room or per_person"))
+		return
+	}
+
+	room := entity.Room{
+		Name:            req.Name,
+		Type:            req.Type,
+		BedType:         req.BedType,
+		Price:           req.Price,
+		PriceUnit:       req.PriceUnit,
+		People:          req.People,
+		Status:          req.Status,
+		AccommodationID: req.AccommodationID,
+	}
+
+	if err := dbFor(c).Create(&room).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to create room"))
+		return
+	}
+
+	// แนบรูป (owner_type = "room")
+	for _, u := range req.PictureURLs {
+		if strings.TrimSpace(u) == "" {
+			continue
+		}
+		pic := entity.Picture{
+			Url:       u,
+			OwnerType: "room",
+			OwnerID:   room.ID,
+		}
+		_ = dbFor(c).Create(&pic).Error // ถ้าพลาดบางรูป ข้ามไป (ไม่ล้มทั้งคำสั่ง)
+	}
+
+	// reload พร้อมความสัมพันธ์
+	_ = dbFor(c).
+		Preload("Accommodation").
+		Preload("Facilities").
+		Preload("Pictures").
+		First(&room, room.ID)
+
+	c.JSON(http.StatusCreated, gin.H{"data": room, "message": "Room created successfully"})
 }
 
 // PUT /room/:id
 // PUT /room/:id
 func UpdateRoomById(c *gin.Context) {
-    id := c.Param("id")
-
-    var req RoomUpdateReq
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request body: " + err.Error()})
-        return
-    }
-
-    var item entity.Room
-    if err := config.DB().Where("id = ?", id).First(&item).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
-        return
-    }
-
-    // อัปเดตเฉพาะฟิลด์ที่ส่งมา
-    if req.Name != nil { item.Name = *req.Name }
-    if req.Type != nil { item.Type = *req.Type }
-    if req.BedType != nil { item.BedType = *req.BedType }
-    if req.Price != nil { item.Price = *req.Price }
-    if req.People != nil { item.People = *req.People }
-    if req.Status != nil { item.Status = *req.Status }
-    if req.AccommodationID != nil { item.AccommodationID = req.AccommodationID }
-    // if req.AdminID != nil { item.AdminID = *req.AdminID } // ถ้ามีฟิลด์นี้ใน Room ก็ใช้
-
-    if err := config.DB().Save(&item).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room: " + err.Error()})
-        return
-    }
-
-    // ถ้าส่ง picture_urls มา → ลบของเดิมแล้วเพิ่มใหม่
-    if req.PictureURLs != nil {
-        _ = config.DB().
-            Where("owner_type = ? AND owner_id = ?", "room", item.ID).
-            Delete(&entity.Picture{}).Error
-
-        for _, u := range *req.PictureURLs {
-            if strings.TrimSpace(u) == "" {
-                continue
-            }
-            pic := entity.Picture{
-                Url:       u,
-                OwnerType: "room",
-                OwnerID:   item.ID,
-            }
-            _ = config.DB().Create(&pic).Error
-        }
-    }
-
-    // reload พร้อมความสัมพันธ์
-    _ = config.DB().
-        Preload("Accommodation").
-        Preload("Facilities").
-        Preload("Pictures").
-        First(&item, item.ID)
-
-    c.JSON(http.StatusOK, gin.H{"data": item, "message": "Room updated successfully"})
+	id := c.Param("id")
+
+	var req RoomUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	var item entity.Room
+	if err := dbFor(c).Where("id = ?", id).First(&item).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("Room not found"))
+		return
+	}
+
+	// อัปเดตเฉพาะฟิลด์ที่ส่งมา
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Type != nil {
+		item.Type = *req.Type
+	}
+	if req.BedType != nil {
+		item.BedType = *req.BedType
+	}
+	if req.Price != nil {
+		item.Price = *req.Price
+	}
+	if req.PriceUnit != nil {
+		if !validPriceUnit(*req.PriceUnit) || *req.PriceUnit == "" {
+			apierr.Respond(c, apierr.BadRequest("price_unit must be per_room or per_person"))
+			return
+		}
+		item.PriceUnit = *req.PriceUnit
+	}
+	if req.People != nil {
+		item.People = *req.People
+	}
+	if req.Status != nil {
+		item.Status = *req.Status
+	}
+	if req.AccommodationID != nil {
+		item.AccommodationID = req.AccommodationID
+	}
+	// if req.AdminID != nil { item.AdminID = *req.AdminID } // ถ้ามีฟิลด์นี้ใน Room ก็ใช้
+
+	if err := dbFor(c).Save(&item).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to update room"))
+		return
+	}
+
+	// ถ้าส่ง picture_urls มา → ลบของเดิมแล้วเพิ่มใหม่
+	if req.PictureURLs != nil {
+		_ = dbFor(c).
+			Where("owner_type = ? AND owner_id = ?", "room", item.ID).
+			Delete(&entity.Picture{}).Error
+
+		for _, u := range *req.PictureURLs {
+			if strings.TrimSpace(u) == "" {
+				continue
+			}
+			pic := entity.Picture{
+				Url:       u,
+				OwnerType: "room",
+				OwnerID:   item.ID,
+			}
+			_ = dbFor(c).Create(&pic).Error
+		}
+	}
+
+	// reload พร้อมความสัมพันธ์
+	_ = dbFor(c).
+		Preload("Accommodation").
+		Preload("Facilities").
+		Preload("Pictures").
+		First(&item, item.ID)
+
+	c.JSON(http.StatusOK, gin.H{"data": item, "message": "Room updated successfully"})
 }
 
 // DELETE /room/:id (เดิมเหมือนเดิม แต่อาจเพิ่มลบรูป)
 // DELETE /room/:id
 func DeleteRoomById(c *gin.Context) {
-    var item entity.Room
-    id := c.Param("id")
-
-    if err := config.DB().Where("id = ?", id).First(&item).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
-        return
-    }
-
-    _ = config.DB().Model(&item).Association("Facilities").Clear()
-    _ = config.DB().Where("owner_type = ? AND owner_id = ?", "room", item.ID).Delete(&entity.Picture{})
-
-    if err := config.DB().Delete(&item, id).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete room"})
-        return
-    }
-    c.JSON(http.StatusOK, gin.H{"message": "room deleted successfully"})
+	var item entity.Room
+	id := c.Param("id")
+
+	if err := dbFor(c).Where("id = ?", id).First(&item).Error; err != nil {
+		apierr.Respond(c, apierr.NotFound("room not found"))
+		return
+	}
+
+	_ = dbFor(c).Model(&item).Association("Facilities").Clear()
+	_ = dbFor(c).Where("owner_type = ? AND owner_id = ?", "room", item.ID).Delete(&entity.Picture{})
+
+	if err := dbFor(c).Delete(&item, id).Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "failed to delete room"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "room deleted successfully"})
 }
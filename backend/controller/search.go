@@ -0,0 +1,609 @@
+package controller
+
+import (
+	"html"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"github.com/kookkikiv/sa_project/backend/thai"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	searchPackage       = "package"
+	searchAccommodation = "accommodation"
+	searchEvent         = "event"
+)
+
+var searchTypes = []string{searchPackage, searchAccommodation, searchEvent}
+
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+)
+
+// searchHit คือผลค้นหา 1 รายการ Score ยิ่งมากยิ่งตรง
+type searchHit struct {
+	Type    string  `json:"type"`
+	ID      uint    `json:"id"`
+	Title   string  `json:"title"`   // ชื่อพร้อม <mark> รอบคำที่ตรง
+	Snippet string  `json:"snippet"` // ข้อความบางส่วนพร้อม <mark>
+	Score   float64 `json:"score"`
+}
+
+// placeNames คือชื่อตำบล/อำเภอ/จังหวัด ทั้งไทยและอังกฤษ (ข้ามที่ว่าง)
+func placeNames(p *entity.Province, d *entity.District, s *entity.Subdistrict) []string {
+	var out []string
+	if s != nil {
+		out = append(out, s.NameTh, s.NameEn)
+	}
+	if d != nil {
+		out = append(out, d.NameTh, d.NameEn)
+	}
+	if p != nil {
+		out = append(out, p.NameTh, p.NameEn)
+	}
+	return out
+}
+
+// joinText รวมข้อความที่ไม่ว่างด้วย " · " ตัดตัวซ้ำ
+func joinText(parts ...string) string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return strings.Join(out, " · ")
+}
+
+// buildSearchDocument สร้างข้อความค้นหาของรายการจากฐานข้อมูล คืน false ถ้ารายการไม่มีแล้ว
+func buildSearchDocument(tx *gorm.DB, docType string, id uint) (entity.SearchDocument, bool, error) {
+	doc := entity.SearchDocument{DocType: docType, DocID: id}
+	var parts, places []string
+	switch docType {
+	case searchPackage:
+		var pack entity.Package
+		if err := tx.Preload("Province").Preload("District").Preload("Subdistrict").First(&pack, id).Error; err != nil {
+			return doc, false, ignoreNotFound(err)
+		}
+		doc.Title = pack.Name
+		parts = placeNames(&pack.Province, &pack.District, &pack.Subdistrict)
+		places = placeTokens(&pack.Province, &pack.District, &pack.Subdistrict)
+
+		var accNames, eventTexts []string
+		if err := tx.Model(&entity.Accommodation{}).
+			Where("id IN (SELECT accommodation_id FROM accommodation_package WHERE package_id = ?) OR id IN (SELECT accommodation_id FROM package_stays WHERE package_id = ? AND deleted_at IS NULL)", id, id).
+			Pluck("name", &accNames).Error; err != nil {
+			return doc, false, err
+		}
+		if err := tx.Model(&entity.Event{}).
+			Where("id IN (SELECT event_id FROM event_package WHERE package_id = ?)", id).
+			Pluck("event_name || ' ' || COALESCE(host, '')", &eventTexts).Error; err != nil {
+			return doc, false, err
+		}
+		parts = append(parts, accNames...)
+		parts = append(parts, eventTexts...)
+	case searchAccommodation:
+		var acc entity.Accommodation
+		if err := tx.Preload("Province").Preload("District").Preload("Subdistrict").First(&acc, id).Error; err != nil {
+			return doc, false, ignoreNotFound(err)
+		}
+		doc.Title = acc.Name
+		parts = append([]string{acc.Type}, placeNames(&acc.Province, &acc.District, &acc.Subdistrict)...)
+		places = placeTokens(&acc.Province, &acc.District, &acc.Subdistrict)
+
+		var facilities []string
+		if err := tx.Model(&entity.Facility{}).
+			Where("accommodation_id = ? OR id IN (SELECT facility_id FROM accommodation_facility WHERE accommodation_id = ?)", id, id).
+			Pluck("name", &facilities).Error; err != nil {
+			return doc, false, err
+		}
+		parts = append(parts, facilities...)
+	case searchEvent:
+		var event entity.Event
+		if err := tx.Preload("EventType").Preload("Province").Preload("District").Preload("Subdistrict").First(&event, id).Error; err != nil {
+			return doc, false, ignoreNotFound(err)
+		}
+		doc.Title = event.Event_Name
+		parts = []string{event.Host}
+		if event.EventType != nil {
+			parts = append(parts, event.EventType.Type_Name)
+		}
+		parts = append(parts, placeNames(&event.Province, &event.District, &event.Subdistrict)...)
+		places = placeTokens(&event.Province, &event.District, &event.Subdistrict)
+	}
+	doc.Body = joinText(parts...)
+	lex, err := loadSearchLexicon(tx)
+	if err != nil {
+		return doc, false, err
+	}
+	doc.TitleWords = strings.Join(lex.seg.Segment(doc.Title), " ")
+	// เว้นวรรคหัวท้ายให้ LIKE หา token พื้นที่แบบทั้งคำได้ (" geop50 ")
+	doc.Keywords = " " + strings.Join(append(lex.seg.Segment(doc.Body), places...), " ") + " "
+	doc.UpdatedAt = time.Now()
+	return doc, true, nil
+}
+
+func ignoreNotFound(err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	return err
+}
+
+// indexDocument อัปเดตข้อความค้นหาของรายการ (ลบออกจาก index ถ้ารายการถูกลบแล้ว)
+// เรียกใน transaction เดียวกับที่แก้รายการ trigger จะอัปเดต search_fts ให้เอง
+func indexDocument(tx *gorm.DB, docType string, id uint) error {
+	doc, ok, err := buildSearchDocument(tx, docType, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return tx.Where("doc_type = ? AND doc_id = ?", docType, id).Delete(&entity.SearchDocument{}).Error
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "doc_type"}, {Name: "doc_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "body", "title_words", "keywords", "updated_at"}),
+	}).Create(&doc).Error
+}
+
+// refreshSearch ใช้กับ handler ที่ไม่ได้ทำงานใน transaction: index ไม่สำเร็จแค่ log ไว้ (แก้ได้ด้วย /search/reindex)
+func refreshSearch(c *gin.Context, docType string, ids ...uint) {
+	ctx := c.Request.Context()
+	for _, id := range ids {
+		if err := indexDocument(dbFor(c), docType, id); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "search index: index document", "type", docType, "id", id, "error", err)
+		}
+	}
+}
+
+// refreshAccommodationPackages index แพ็คเกจที่มีชื่อที่พักนี้ใหม่ (ผิดพลาดแค่ log เหมือน refreshSearch)
+func refreshAccommodationPackages(c *gin.Context, accID uint) {
+	ids, err := accommodationPackageIDs(dbFor(c), accID)
+	if err != nil {
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).ErrorContext(ctx, "search index: accommodation packages", "accommodation_id", accID, "error", err)
+		return
+	}
+	refreshSearch(c, searchPackage, ids...)
+}
+
+// RebuildSearchIndex สร้างข้อความค้นหาใหม่ทุกรายการ
+func RebuildSearchIndex(db *gorm.DB) (int, error) {
+	count := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&entity.SearchDocument{}).Error; err != nil {
+			return err
+		}
+		for _, t := range []struct {
+			docType string
+			model   any
+		}{
+			{searchPackage, &entity.Package{}},
+			{searchAccommodation, &entity.Accommodation{}},
+			{searchEvent, &entity.Event{}},
+		} {
+			var ids []uint
+			if err := tx.Model(t.model).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := indexDocument(tx, t.docType, id); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// EnsureSearchIndex สร้าง index ตอนเปิดระบบถ้ายังว่าง (เช่น ฐานข้อมูลเดิมก่อนมีการค้นหา)
+// หรือมีรายการที่ยังไม่ได้ตัดคำ (index จากรุ่นก่อนมี TitleWords/Keywords)
+func EnsureSearchIndex() {
+	db := config.DB()
+	var n, stale int64
+	db.Model(&entity.SearchDocument{}).Count(&n)
+	db.Model(&entity.SearchDocument{}).Where("title_words = '' AND title <> ''").Count(&stale)
+	if n > 0 && stale == 0 {
+		return
+	}
+	if count, err := RebuildSearchIndex(db); err != nil {
+		slog.Error("search index: rebuild failed", "error", err)
+	} else {
+		slog.Info("search index: rebuilt", "documents", count)
+	}
+}
+
+// searchStopWords คือคำบอกระดับพื้นที่ที่ตัดทิ้งจากคำค้น ("จ.เชียงใหม่", "อำเภอหัวหิน", "Chiang Mai province")
+var searchStopWords = map[string]bool{
+	"จังหวัด": true, "อำเภอ": true, "กิ่งอำเภอ": true, "ตำบล": true, "แขวง": true, "เขต": true,
+	"จ": true, "อ": true, "ต": true,
+	"changwat": true, "amphoe": true, "amphur": true, "tambon": true, "khet": true, "khwaeng": true,
+	"province": true, "district": true, "subdistrict": true,
+}
+
+// searchGroup คือคำค้นหนึ่งช่วง: ต้องพบทุกคำใน words หรือ (ถ้าช่วงนี้เป็นชื่อพื้นที่) อยู่ในพื้นที่ใดพื้นที่หนึ่งใน places
+type searchGroup struct {
+	words  []string
+	places []placeRef
+}
+
+// searchQuery คือคำค้นที่ตัดคำแล้ว ทุก group ต้องตรง (AND)
+type searchQuery struct {
+	groups []searchGroup
+}
+
+// parseSearch ตัดคำค้น (ไทยตามพจนานุกรม) แล้วหาช่วงคำที่ยาวที่สุดที่ตรงกับชื่อพื้นที่
+// "Chiang Mai", "Chiangmai", "เชียงใหม่" จึงได้ group ที่ชี้ไปจังหวัดเดียวกัน
+func parseSearch(tx *gorm.DB, q string) (searchQuery, error) {
+	var query searchQuery
+	lex, err := loadSearchLexicon(tx)
+	if err != nil {
+		return query, err
+	}
+	all := lex.seg.Segment(q)
+	words := all[:0:0]
+	for _, w := range all {
+		if !searchStopWords[w] {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		words = all // ค้นคำบอกระดับพื้นที่อย่างเดียว เช่น "เขต"
+	}
+
+	for i := 0; i < len(words); {
+		n := 1
+		group := searchGroup{}
+		for size := min(maxAliasWords, len(words)-i); size >= 1; size-- {
+			if refs := lex.places[thai.Key(strings.Join(words[i:i+size], ""))]; len(refs) > 0 {
+				n, group.places = size, refs
+				break
+			}
+		}
+		group.words = words[i : i+n]
+		query.groups = append(query.groups, group)
+		i += n
+	}
+	return query, nil
+}
+
+func (q searchQuery) empty() bool { return len(q.groups) == 0 }
+
+// highlightTerms คือคำที่ใส่ <mark>: คำที่พิมพ์ และชื่อไทย/อังกฤษของพื้นที่ที่ตรง
+func (q searchQuery) highlightTerms() []string {
+	var terms []string
+	for _, g := range q.groups {
+		terms = append(terms, g.words...)
+		for _, p := range g.places {
+			terms = append(terms, p.NameTh, p.NameEn)
+		}
+	}
+	return terms
+}
+
+// matchedPlaces คือพื้นที่ที่คำค้นตรง (ตอบกลับให้ FE แสดง "ผลในจังหวัด ...")
+func (q searchQuery) matchedPlaces() []placeRef {
+	out := []placeRef{}
+	for _, g := range q.groups {
+		out = append(out, g.places...)
+	}
+	return out
+}
+
+// ftsMatch แปลงเป็น query ของ FTS5: คำขึ้นต้นด้วยคำที่พิมพ์ (AND) หรือ token ของพื้นที่ (OR)
+// คำจาก Segment มีแต่ตัวอักษร/ตัวเลข จึงครอบด้วย "" ได้ปลอดภัย
+func (q searchQuery) ftsMatch() string {
+	parts := make([]string, len(q.groups))
+	for i, g := range q.groups {
+		words := make([]string, len(g.words))
+		for j, w := range g.words {
+			words[j] = `"` + w + `"*`
+		}
+		alts := []string{strings.Join(words, " AND ")}
+		for _, p := range g.places {
+			alts = append(alts, `"`+p.token()+`"`)
+		}
+		parts[i] = "(" + strings.Join(alts, " OR ") + ")"
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// likeScope กรอง search_documents ด้วย LIKE (ใช้เมื่อไม่มี FTS5) เงื่อนไขเดียวกับ ftsMatch แต่หาแบบ substring
+func (q searchQuery) likeScope(tx *gorm.DB) *gorm.DB {
+	for _, g := range q.groups {
+		var words []string
+		var args []any
+		for _, w := range g.words {
+			p := "%" + w + "%"
+			words = append(words, "(title_words LIKE ? OR keywords LIKE ?)")
+			args = append(args, p, p)
+		}
+		alts := []string{"(" + strings.Join(words, " AND ") + ")"}
+		for _, p := range g.places {
+			alts = append(alts, "keywords LIKE ?")
+			args = append(args, "% "+p.token()+" %")
+		}
+		tx = tx.Where("("+strings.Join(alts, " OR ")+")", args...)
+	}
+	return tx
+}
+
+// likeScore ให้คะแนนแบบ LIKE: group ที่ตรงในชื่อ x10, ตรงในรายละเอียด/พื้นที่ x1
+func (q searchQuery) likeScore(d entity.SearchDocument) float64 {
+	score := 0.0
+	for _, g := range q.groups {
+		inTitle, inBody := true, true
+		for _, w := range g.words {
+			inTitle = inTitle && strings.Contains(d.TitleWords, w)
+			inBody = inBody && strings.Contains(d.Keywords, w)
+		}
+		for _, p := range g.places {
+			inBody = inBody || strings.Contains(d.Keywords, " "+p.token()+" ")
+		}
+		switch {
+		case inTitle:
+			score += 10
+		case inBody:
+			score++
+		}
+	}
+	return score
+}
+
+// highlightText ใส่ <mark> รอบทุกคำที่ตรง (ไม่สนตัวพิมพ์เล็กใหญ่)
+// ผลลัพธ์เป็น HTML: ทุกช่วงข้อความ escape ก่อนใส่แท็ก เพราะ frontend แสดงค่านี้เป็น HTML ตรง ๆ
+func highlightText(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	type span struct{ from, to int }
+	var spans []span
+	for _, t := range terms {
+		lt := strings.ToLower(t)
+		for i := 0; ; {
+			j := strings.Index(lower[i:], lt)
+			if j < 0 || lt == "" {
+				break
+			}
+			spans = append(spans, span{i + j, i + j + len(lt)})
+			i += j + len(lt)
+		}
+	}
+	if len(spans) == 0 || len(lower) != len(text) {
+		return html.EscapeString(text)
+	}
+	sort.Slice(spans, func(a, b int) bool {
+		return spans[a].from < spans[b].from || (spans[a].from == spans[b].from && spans[a].to > spans[b].to)
+	})
+	var b strings.Builder
+	pos := 0
+	for _, s := range spans {
+		if s.from < pos {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:s.from]))
+		b.WriteString(markOpen + html.EscapeString(text[s.from:s.to]) + markClose)
+		pos = s.to
+	}
+	b.WriteString(html.EscapeString(text[pos:]))
+	return b.String()
+}
+
+// snippetText ตัดข้อความรอบคำแรกที่ตรง ประมาณ width ตัวอักษร ผลลัพธ์เป็น HTML ที่ escape แล้วเช่นเดียวกับ highlightText
+func snippetText(text string, terms []string, width int) string {
+	lower := strings.ToLower(text)
+	first := -1
+	for _, t := range terms {
+		if i := strings.Index(lower, strings.ToLower(t)); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 || len(lower) != len(text) {
+		first = 0
+	}
+	runes := []rune(text)
+	start := utf8.RuneCountInString(text[:first]) - width/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+	}
+	out := highlightText(string(runes[start:end]), terms)
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(runes) {
+		out += "…"
+	}
+	return out
+}
+
+// searchDocIDs คืน ID ของรายการประเภท docType ที่ตรงกับคำค้น (ใช้กรองใน endpoint ค้นหาเดิม)
+func searchDocIDs(db *gorm.DB, docType, q string) ([]uint, error) {
+	var ids []uint
+	query, err := parseSearch(db, q)
+	if err != nil || query.empty() {
+		return ids, err
+	}
+	if config.SearchFTS5() {
+		err := db.Raw(`SELECT d.doc_id FROM search_fts JOIN search_documents d ON d.id = search_fts.rowid
+			WHERE search_fts MATCH ? AND d.doc_type = ?`, query.ftsMatch(), docType).Scan(&ids).Error
+		return ids, err
+	}
+	err = query.likeScope(db.Model(&entity.SearchDocument{})).Where("doc_type = ?", docType).Pluck("doc_id", &ids).Error
+	return ids, err
+}
+
+// searchRow คือรายการที่ตรงพร้อมคะแนน ก่อนใส่ highlight
+type searchRow struct {
+	DocType string
+	DocID   uint
+	Title   string
+	Body    string
+	Score   float64
+}
+
+// searchFTS ค้นด้วย FTS5: bm25 ให้น้ำหนักชื่อมากกว่ารายละเอียด
+func searchFTS(db *gorm.DB, query searchQuery, docType string, limit, offset int) ([]searchRow, map[string]int64, error) {
+	match := query.ftsMatch()
+	facets := map[string]int64{}
+	var counts []struct {
+		DocType string
+		N       int64
+	}
+	if err := db.Raw(`SELECT d.doc_type, COUNT(*) AS n FROM search_fts JOIN search_documents d ON d.id = search_fts.rowid
+		WHERE search_fts MATCH ? GROUP BY d.doc_type`, match).Scan(&counts).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, r := range counts {
+		facets[r.DocType] = r.N
+	}
+
+	sql := `SELECT d.doc_type, d.doc_id, d.title, d.body, -bm25(search_fts, 10.0, 1.0) AS score
+		FROM search_fts JOIN search_documents d ON d.id = search_fts.rowid
+		WHERE search_fts MATCH ?`
+	args := []any{match}
+	if docType != "" {
+		sql += ` AND d.doc_type = ?`
+		args = append(args, docType)
+	}
+	sql += ` ORDER BY score DESC, d.id LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	var rows []searchRow
+	err := db.Raw(sql, args...).Scan(&rows).Error
+	return rows, facets, err
+}
+
+// searchLike ค้นด้วย LIKE เมื่อไม่มี FTS5 (คะแนนจาก likeScore) แบ่งหน้าใน Go
+func searchLike(db *gorm.DB, query searchQuery, docType string, limit, offset int) ([]searchRow, map[string]int64, error) {
+	var docs []entity.SearchDocument
+	if err := query.likeScope(db.Model(&entity.SearchDocument{})).Order("id").Find(&docs).Error; err != nil {
+		return nil, nil, err
+	}
+	facets := map[string]int64{}
+	var rows []searchRow
+	for _, d := range docs {
+		facets[d.DocType]++
+		if docType != "" && d.DocType != docType {
+			continue
+		}
+		rows = append(rows, searchRow{DocType: d.DocType, DocID: d.DocID, Title: d.Title, Body: d.Body, Score: query.likeScore(d)})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Score > rows[j].Score })
+	if offset >= len(rows) {
+		return nil, facets, nil
+	}
+	rows = rows[offset:]
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, facets, nil
+}
+
+// GET /search?q=&type=&limit=&offset= - ค้นหาแพ็คเกจ/ที่พัก/อีเวนต์พร้อมกัน
+// facets นับทุกประเภทที่ตรง (ไม่ขึ้นกับ type) เพื่อแสดงตัวกรอง
+func Search(c *gin.Context) {
+	if strings.TrimSpace(c.Query("q")) == "" {
+		apierr.Respond(c, apierr.Required("q"))
+		return
+	}
+	docType := strings.ToLower(c.Query("type"))
+	if docType != "" && docType != searchPackage && docType != searchAccommodation && docType != searchEvent {
+		apierr.Respond(c, apierr.BadRequest("type must be package, accommodation or event"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		apierr.Respond(c, apierr.BadRequest("limit must be between 1 and 100"))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		apierr.Respond(c, apierr.BadRequest("offset must not be negative"))
+		return
+	}
+
+	db := dbFor(c)
+	query, err := parseSearch(db, c.Query("q"))
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if query.empty() {
+		apierr.Respond(c, apierr.BadRequest("q must contain a word"))
+		return
+	}
+	engine := "fts5"
+	search := searchFTS
+	if !config.SearchFTS5() {
+		engine, search = "like", searchLike
+	}
+	rows, facets, err := search(db, query, docType, limit, offset)
+	if err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+
+	// highlight ทำใน Go ทั้งสองแบบ เพราะคอลัมน์ที่ index เป็นข้อความที่ตัดคำแล้ว ไม่ใช่ข้อความที่แสดง
+	terms := query.highlightTerms()
+	hits := make([]searchHit, len(rows))
+	for i, r := range rows {
+		hits[i] = searchHit{
+			Type:    r.DocType,
+			ID:      r.DocID,
+			Title:   highlightText(r.Title, terms),
+			Snippet: snippetText(r.Body, terms, 60),
+			Score:   r.Score,
+		}
+	}
+
+	total := int64(0)
+	for _, t := range searchTypes {
+		if docType == "" || docType == t {
+			total += facets[t]
+		}
+		if _, ok := facets[t]; !ok {
+			facets[t] = 0
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hits, "facets": facets, "total": total, "engine": engine, "places": query.matchedPlaces()})
+}
+
+// POST /search/reindex - สร้าง index ใหม่ทั้งหมด (เช่น หลังนำเข้าข้อมูลจังหวัดใหม่ ให้ตัดคำด้วยชื่อพื้นที่ชุดใหม่)
+func ReindexSearch(c *gin.Context) {
+	resetSearchLexicon()
+	count, err := RebuildSearchIndex(dbFor(c))
+	if err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Failed to rebuild search index"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"documents": count, "engine_fts5": config.SearchFTS5()}, "message": "Search index rebuilt successfully"})
+}
+
+// accommodationPackageIDs คือแพ็คเกจที่แสดงชื่อที่พักนี้ในข้อความค้นหา (ต้อง index ใหม่เมื่อที่พักเปลี่ยน)
+func accommodationPackageIDs(db *gorm.DB, accID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`SELECT package_id FROM accommodation_package WHERE accommodation_id = ?
+		UNION SELECT package_id FROM package_stays WHERE accommodation_id = ? AND deleted_at IS NULL`, accID, accID).Scan(&ids).Error
+	return ids, err
+}
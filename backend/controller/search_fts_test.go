@@ -0,0 +1,80 @@
+//go:build sqlite_fts5
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+)
+
+// รันด้วย go test -tags sqlite_fts5 (make test) เพื่อให้ /search ใช้ FTS5 จริง
+// รายการที่ตรงในชื่อต้องมาก่อนรายการที่ตรงแค่ในรายละเอียด แม้จะถูกเพิ่มทีหลัง
+func TestSearchFTS5RanksTitleAboveBody(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	config.ConnectionDB()
+	config.SetupDatabase()
+	if !config.SearchFTS5() {
+		t.Fatal("sqlite was built without FTS5")
+	}
+
+	db := config.DB()
+	docs := []entity.SearchDocument{
+		{DocType: searchAccommodation, DocID: 1, Title: "Mountain Lodge", Body: "river view · wifi",
+			TitleWords: "mountain lodge", Keywords: " river view wifi "},
+		{DocType: searchAccommodation, DocID: 2, Title: "City Hotel", Body: "pool",
+			TitleWords: "city hotel", Keywords: " pool "},
+		{DocType: searchPackage, DocID: 3, Title: "River Cruise", Body: "boat",
+			TitleWords: "river cruise", Keywords: " boat "},
+	}
+	if err := db.Create(&docs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/search", Search)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?q=river", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /search = %d %s", w.Code, w.Body)
+	}
+
+	var res struct {
+		Data   []searchHit      `json:"data"`
+		Facets map[string]int64 `json:"facets"`
+		Engine string           `json:"engine"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Engine != "fts5" {
+		t.Fatalf("engine = %q, want fts5", res.Engine)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("hits = %+v, want 2", res.Data)
+	}
+	if res.Data[0].ID != 3 || res.Data[1].ID != 1 {
+		t.Errorf("order = %d, %d, want 3 (title match), 1 (body match)", res.Data[0].ID, res.Data[1].ID)
+	}
+	if res.Data[0].Score <= res.Data[1].Score {
+		t.Errorf("scores = %v, %v, want title match higher", res.Data[0].Score, res.Data[1].Score)
+	}
+	if res.Facets[searchPackage] != 1 || res.Facets[searchAccommodation] != 1 {
+		t.Errorf("facets = %v", res.Facets)
+	}
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// seatTarget คือรายการที่มีที่นั่งจำกัด ระบุ PackageID หรือ EventID อย่างใดอย่างหนึ่ง
+type seatTarget struct {
+	PackageID *uint
+	EventID   *uint
+}
+
+func packageSeats(id uint) seatTarget { return seatTarget{PackageID: &id} }
+func eventSeats(id uint) seatTarget   { return seatTarget{EventID: &id} }
+
+// scope กรอง SeatHold / WaitlistEntry ของรายการนี้
+func (t seatTarget) scope(tx *gorm.DB) *gorm.DB {
+	if t.PackageID != nil {
+		return tx.Where("package_id = ?", *t.PackageID)
+	}
+	return tx.Where("event_id = ?", *t.EventID)
+}
+
+// key ใช้แยกรายการที่ซ้ำกันตอนวนประมวลผล waitlist
+func (t seatTarget) key() string {
+	if t.PackageID != nil {
+		return fmt.Sprintf("package:%d", *t.PackageID)
+	}
+	return fmt.Sprintf("event:%d", *t.EventID)
+}
+
+// seatTargetOf คืน seatTarget ของรายการในตะกร้า (ห้องพักไม่มีที่นั่ง)
+func seatTargetOf(itemType string, packageID, eventID *uint) (seatTarget, bool) {
+	switch {
+	case itemType == "package" && packageID != nil:
+		return packageSeats(*packageID), true
+	case itemType == "event" && eventID != nil:
+		return eventSeats(*eventID), true
+	}
+	return seatTarget{}, false
+}
+
+// seatUsage คือสรุปที่นั่ง Capacity = Package.People หรือ Event.Quota (0 = ไม่จำกัด)
+type seatUsage struct {
+	PackageID *uint  `json:"package_id,omitempty"`
+	EventID   *uint  `json:"event_id,omitempty"`
+	Name      string `json:"name"`
+	Capacity  uint   `json:"capacity"`
+	Held      uint   `json:"held"`
+	Reserved  uint   `json:"reserved"`
+	Confirmed uint   `json:"confirmed"`
+	Remaining *uint  `json:"remaining"` // nil = ไม่จำกัด
+	Waiting   int64  `json:"waiting"`   // จำนวนคิวที่รออยู่
+}
+
+func (u seatUsage) used() uint { return u.Held + u.Reserved + u.Confirmed }
+
+// fits บอกว่ายังมีที่ว่างพอสำหรับ seats ที่นั่ง
+func (u seatUsage) fits(seats uint) bool { return u.Remaining == nil || *u.Remaining >= seats }
+
+// errNoSeats คือที่นั่งไม่พอ (ตอบ 409)
+type errNoSeats struct{ remaining uint }
+
+func (e errNoSeats) Error() string {
+	return fmt.Sprintf("Not enough seats: %d remaining", e.remaining)
+}
+
+func (e errNoSeats) Problem() *apierr.Error {
+	return apierr.Conflict(e.Error()).With("remaining", e.remaining)
+}
+
+// loadSeatUsage นับที่นั่งที่ถูกใช้ ณ เวลา now (held ที่หมดเวลาแล้วไม่นับ แม้งานปล่อยที่นั่งยังไม่ได้รัน)
+func loadSeatUsage(tx *gorm.DB, t seatTarget, now time.Time) (seatUsage, error) {
+	u := seatUsage{PackageID: t.PackageID, EventID: t.EventID}
+	if t.PackageID != nil {
+		var pack entity.Package
+		if err := tx.First(&pack, *t.PackageID).Error; err != nil {
+			return u, errQuote{"Package not found"}
+		}
+		u.Name, u.Capacity = pack.Name, pack.People
+	} else {
+		var event entity.Event
+		if err := tx.First(&event, *t.EventID).Error; err != nil {
+			return u, errQuote{"Event not found"}
+		}
+		u.Name, u.Capacity = event.Event_Name, event.Quota
+	}
+
+	var rows []struct {
+		Status string
+		Seats  uint
+	}
+	if err := t.scope(tx.Model(&entity.SeatHold{})).
+		Select("status, COALESCE(SUM(seats), 0) AS seats").
+		Where("(status IN ? OR (status = ? AND expires_at > ?))", []string{entity.SeatReserved, entity.SeatConfirmed}, entity.SeatHeld, now).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return u, err
+	}
+	for _, r := range rows {
+		switch r.Status {
+		case entity.SeatHeld:
+			u.Held = r.Seats
+		case entity.SeatReserved:
+			u.Reserved = r.Seats
+		case entity.SeatConfirmed:
+			u.Confirmed = r.Seats
+		}
+	}
+	if u.Capacity > 0 {
+		remaining := uint(0)
+		if u.used() < u.Capacity {
+			remaining = u.Capacity - u.used()
+		}
+		u.Remaining = &remaining
+	}
+	if err := t.scope(tx.Model(&entity.WaitlistEntry{})).
+		Where("status = ?", entity.WaitlistWaiting).
+		Count(&u.Waiting).Error; err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+// holdSeats บันทึกที่นั่งก่อนแล้วค่อยนับ ทำให้ transaction ถือ write lock ก่อนตรวจ ไม่ขายเกินแม้มีคำขอพร้อมกัน
+// ถ้าเกินความจุคืน errNoSeats ผู้เรียกต้อง rollback
+func holdSeats(tx *gorm.DB, hold *entity.SeatHold, now time.Time) error {
+	if err := tx.Create(hold).Error; err != nil {
+		return err
+	}
+	u, err := loadSeatUsage(tx, seatTarget{PackageID: hold.PackageID, EventID: hold.EventID}, now)
+	if err != nil {
+		return err
+	}
+	if u.Capacity > 0 && u.used() > u.Capacity {
+		remaining := uint(0)
+		if before := u.used() - hold.Seats; before < u.Capacity {
+			remaining = u.Capacity - before
+		}
+		return errNoSeats{remaining: remaining}
+	}
+	return nil
+}
+
+// claimCartSeats ย้ายที่นั่งที่ตะกร้ากันไว้ไปเป็นของการจอง ถ้า hold หมดเวลาแล้วจะลองกันใหม่ตามที่นั่งที่เหลือ
+func claimCartSeats(tx *gorm.DB, ci entity.CartItems, memberID, reservationID uint, status string, now time.Time) error {
+	res := tx.Model(&entity.SeatHold{}).
+		Where("cart_item_id = ? AND status = ? AND expires_at > ?", ci.ID, entity.SeatHeld, now).
+		Updates(map[string]any{"status": status, "reservation_id": reservationID, "expires_at": nil})
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+
+	// hold หมดเวลา (หรือรายการเก่าที่ไม่มี hold) งานปล่อยที่นั่งอาจยังไม่ได้รัน
+	if err := tx.Model(&entity.SeatHold{}).
+		Where("cart_item_id = ? AND status = ?", ci.ID, entity.SeatHeld).
+		Update("status", entity.SeatExpired).Error; err != nil {
+		return err
+	}
+	t, _ := seatTargetOf(ci.ItemType, ci.PackageID, ci.EventID)
+	return holdSeats(tx, &entity.SeatHold{
+		PackageID:     t.PackageID,
+		EventID:       t.EventID,
+		MemberID:      memberID,
+		CartItemID:    &ci.ID,
+		ReservationID: &reservationID,
+		Seats:         ci.Adults + ci.Children,
+		Status:        status,
+	}, now)
+}
+
+// releaseSeats เปลี่ยนที่นั่งที่ตรงกับ query เป็น status แล้วเสนอที่ว่างให้คนใน waitlist คืนจำนวนก้อนที่ปล่อย
+func releaseSeats(tx *gorm.DB, query *gorm.DB, status string, now time.Time) (int, error) {
+	var holds []entity.SeatHold
+	if err := query.Find(&holds).Error; err != nil || len(holds) == 0 {
+		return 0, err
+	}
+	ids := make([]uint, 0, len(holds))
+	targets := map[string]seatTarget{}
+	for _, h := range holds {
+		ids = append(ids, h.ID)
+		t := seatTarget{PackageID: h.PackageID, EventID: h.EventID}
+		targets[t.key()] = t
+	}
+	if err := tx.Model(&entity.SeatHold{}).Where("id IN ?", ids).Update("status", status).Error; err != nil {
+		return 0, err
+	}
+	for _, t := range targets {
+		if err := offerWaitlistSeats(tx, t, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(holds), nil
+}
+
+// sweepSeatHolds ปิดข้อเสนอ waitlist ที่หมดเวลา เปลี่ยน held ที่หมดเวลาเป็น expired แล้วเสนอที่ว่างให้คิวถัดไป
+func sweepSeatHolds(db *gorm.DB, now time.Time) (int, error) {
+	var released int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.WaitlistEntry{}).
+			Where("status = ? AND offer_expires_at <= ?", entity.WaitlistOffered, now).
+			Update("status", entity.WaitlistExpired).Error; err != nil {
+			return err
+		}
+		var err error
+		released, err = releaseSeats(tx, tx.Where("status = ? AND expires_at <= ?", entity.SeatHeld, now), entity.SeatExpired, now)
+		return err
+	})
+	return released, err
+}
+
+// RunSeatHoldReleaser ปล่อยที่นั่งที่หมดเวลาทุก interval จนกว่า ctx ถูกยกเลิก (เรียกด้วย go จาก main)
+func RunSeatHoldReleaser(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := sweepSeatHolds(config.DB(), now)
+			if err != nil {
+				slog.Error("seat hold releaser", "error", err)
+			} else if n > 0 {
+				slog.Info("seat hold releaser: released expired holds", "count", n)
+			}
+		}
+	}
+}
+
+// findSeats ตอบสรุปที่นั่งของแพ็คเกจหรืออีเวนต์ตาม :id
+func findSeats(c *gin.Context, label string, target func(uint) seatTarget) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid "+label+" ID format"))
+		return
+	}
+	u, err := loadSeatUsage(dbFor(c), target(uint(id)), time.Now())
+	if err != nil {
+		if qe, ok := err.(errQuote); ok {
+			apierr.Respond(c, apierr.NotFound(qe.msg))
+		} else {
+			apierr.Respond(c, apierr.Internal(err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": u, "hold_ttl_seconds": int(config.SeatHoldTTL().Seconds())})
+}
+
+// GET /package/:id/seats - ที่นั่งทั้งหมด/ถูกกัน/จองแล้ว/ที่เหลือ
+func FindPackageSeats(c *gin.Context) { findSeats(c, "package", packageSeats) }
+
+// GET /event/:id/seats
+func FindEventSeats(c *gin.Context) { findSeats(c, "event", eventSeats) }
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// seatsInUse คือที่นั่งที่นับเป็นใช้แล้วของแพ็คเกจ ณ เวลา now
+func seatsInUse(t *testing.T, db *gorm.DB, packageID uint, now time.Time) uint {
+	t.Helper()
+	u, err := loadSeatUsage(db, packageSeats(packageID), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.used()
+}
+
+// กันที่นั่งทีละก้อนจนเต็ม ก้อนที่เกิน Package.People ถูกปฏิเสธและไม่เหลือค้างในฐานข้อมูล
+// hold ที่หมดเวลาแล้วไม่นับ
+func TestHoldSeatsNeverExceedsPackagePeople(t *testing.T) {
+	db := openTestDB(t)
+	member := testMember(t, db, 1)
+	pack := entity.Package{Name: "Doi Inthanon", People: 5}
+	mustCreate(t, db, &pack)
+	now := time.Now()
+
+	hold := func(seats uint, expires time.Time) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			return holdSeats(tx, &entity.SeatHold{
+				PackageID: &pack.ID, MemberID: member.ID, Seats: seats, Status: entity.SeatHeld, ExpiresAt: &expires,
+			}, now)
+		})
+	}
+	later := now.Add(time.Hour)
+
+	steps := []struct {
+		seats     uint
+		remaining uint // ที่เหลือใน errNoSeats (ใช้เมื่อ fails)
+		fails     bool
+	}{
+		{3, 0, false},
+		{3, 2, true},
+		{2, 0, false},
+		{1, 0, true},
+	}
+	for i, s := range steps {
+		err := hold(s.seats, later)
+		var noSeats errNoSeats
+		switch {
+		case s.fails && !errors.As(err, &noSeats):
+			t.Fatalf("step %d: hold %d = %v, want errNoSeats", i, s.seats, err)
+		case s.fails && noSeats.remaining != s.remaining:
+			t.Errorf("step %d: remaining = %d, want %d", i, noSeats.remaining, s.remaining)
+		case !s.fails && err != nil:
+			t.Fatalf("step %d: hold %d = %v", i, s.seats, err)
+		}
+		if used := seatsInUse(t, db, pack.ID, now); used > pack.People {
+			t.Fatalf("step %d: %d seats in use, capacity %d", i, used, pack.People)
+		}
+	}
+	if used := seatsInUse(t, db, pack.ID, now); used != 5 {
+		t.Errorf("seats in use = %d, want 5", used)
+	}
+
+	// ก้อนแรก (3 ที่) หมดเวลา กันใหม่ได้ 3 แต่ 4 ไม่ได้
+	if err := db.Model(&entity.SeatHold{}).Where("seats = ?", 3).
+		Update("expires_at", now.Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := hold(4, later); err == nil {
+		t.Error("hold 4 after expiry succeeded, want errNoSeats")
+	}
+	if err := hold(3, later); err != nil {
+		t.Errorf("hold 3 after expiry = %v", err)
+	}
+	if used := seatsInUse(t, db, pack.ID, now); used != 5 {
+		t.Errorf("seats in use = %d, want 5", used)
+	}
+}
+
+// checkout ย้าย hold ที่ยังไม่หมดเวลาไปเป็นของการจอง ถ้า hold หมดเวลาแล้วต้องกันใหม่ตามที่เหลือ ไม่ขายเกิน
+func TestClaimCartSeatsRespectsPackagePeople(t *testing.T) {
+	db := openTestDB(t)
+	member := testMember(t, db, 1)
+	pack := entity.Package{Name: "Phi Phi", People: 4}
+	mustCreate(t, db, &pack)
+	cart := entity.Cart{MemberID: member.ID}
+	mustCreate(t, db, &cart)
+	now := time.Now()
+
+	// รายการในตะกร้าพร้อม hold ที่หมดเวลา expires
+	cartItem := func(adults uint, expires time.Time) entity.CartItems {
+		ci := entity.CartItems{ItemType: "package", PackageID: &pack.ID, Adults: adults, CartID: cart.ID}
+		mustCreate(t, db, &ci)
+		mustCreate(t, db, &entity.SeatHold{
+			PackageID: &pack.ID, MemberID: member.ID, CartItemID: &ci.ID, Seats: adults,
+			Status: entity.SeatHeld, ExpiresAt: &expires,
+		})
+		return ci
+	}
+	claim := func(ci entity.CartItems) error {
+		r := entity.Reservation{Status: "pending", MemberID: member.ID}
+		mustCreate(t, db, &r)
+		return db.Transaction(func(tx *gorm.DB) error {
+			return claimCartSeats(tx, ci, member.ID, r.ID, entity.SeatReserved, now)
+		})
+	}
+
+	fresh := cartItem(3, now.Add(time.Hour))
+	stale := cartItem(2, now.Add(-time.Minute))
+	if err := claim(fresh); err != nil {
+		t.Fatalf("claim live hold: %v", err)
+	}
+	// เหลือ 1 ที่ hold ที่หมดเวลาขอ 2 ต้องไม่ผ่าน
+	var noSeats errNoSeats
+	if err := claim(stale); !errors.As(err, &noSeats) {
+		t.Fatalf("claim expired hold = %v, want errNoSeats", err)
+	}
+	if used := seatsInUse(t, db, pack.ID, now); used != 3 {
+		t.Errorf("seats in use = %d, want 3", used)
+	}
+
+	// hold ที่หมดเวลาแต่ยังมีที่พอ กันใหม่ได้ และ hold เดิมเปลี่ยนเป็น expired
+	single := cartItem(1, now.Add(-time.Minute))
+	if err := claim(single); err != nil {
+		t.Fatalf("claim expired hold with room left: %v", err)
+	}
+	var statuses []string
+	if err := db.Model(&entity.SeatHold{}).Where("cart_item_id = ?", single.ID).Order("id").
+		Pluck("status", &statuses).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0] != entity.SeatExpired || statuses[1] != entity.SeatReserved {
+		t.Errorf("holds of re-claimed item = %v, want [expired reserved]", statuses)
+	}
+	if used := seatsInUse(t, db, pack.ID, now); used != pack.People {
+		t.Errorf("seats in use = %d, want %d", used, pack.People)
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+// openTestDB เปิดฐานข้อมูลใหม่ใน t.TempDir แบบเดียวกับ search_fts_test (config เปิดไฟล์ตาม working directory)
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	config.ConnectionDB()
+	config.SetupDatabase()
+	gin.SetMode(gin.TestMode)
+	return config.DB()
+}
+
+// mustCreate สร้างแถวที่เทสต์ต้องใช้ ล้มทันทีถ้าสร้างไม่ได้
+func mustCreate(t *testing.T, db *gorm.DB, value any) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+// testMember สร้างสมาชิก 1 คน (อีเมลไม่ซ้ำตาม n)
+func testMember(t *testing.T, db *gorm.DB, n int) entity.Member {
+	t.Helper()
+	m := entity.Member{
+		Username: fmt.Sprintf("member%d", n), Password: "x", Email: fmt.Sprintf("member%d@example.com", n),
+		First_Name: "Test", Last_Name: "Member", BirthDay: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Tel: "0800000000",
+	}
+	mustCreate(t, db, &m)
+	return m
+}
+
+// doJSON ส่ง request ไปที่ r แล้วคืน status กับ body
+func doJSON(t *testing.T, r http.Handler, method, path string, body any) (int, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code, w.Body.Bytes()
+}
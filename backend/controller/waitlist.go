@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/config"
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/gorm"
+)
+
+type WaitlistRequest struct {
+	MemberID  *uint  `json:"member_id"`
+	ItemType  string `json:"item_type"` // package|event
+	PackageID *uint  `json:"package_id"`
+	EventID   *uint  `json:"event_id"`
+	Adults    uint   `json:"adults"`
+	Children  uint   `json:"children"`
+}
+
+type ClaimWaitlistRequest struct {
+	MemberID *uint `json:"member_id"`
+}
+
+// waitlistView คือคิวพร้อมลำดับปัจจุบัน (เฉพาะที่ยังรออยู่)
+type waitlistView struct {
+	entity.WaitlistEntry
+	Position *int64 `json:"position"`
+}
+
+// waitlistPosition คือลำดับของคิวนี้ในบรรดาคิวที่ยังรอของรายการเดียวกัน (เริ่มที่ 1)
+func waitlistPosition(tx *gorm.DB, e entity.WaitlistEntry) (*int64, error) {
+	if e.Status != entity.WaitlistWaiting {
+		return nil, nil
+	}
+	var pos int64
+	err := seatTarget{PackageID: e.PackageID, EventID: e.EventID}.scope(tx.Model(&entity.WaitlistEntry{})).
+		Where("status = ? AND id <= ?", entity.WaitlistWaiting, e.ID).
+		Count(&pos).Error
+	return &pos, err
+}
+
+// offerWaitlistSeats เสนอที่นั่งที่ว่างให้คิวหัวแถวทีละคนจนกว่าที่จะไม่พอ
+// คิวหัวแถวที่ขอมากกว่าที่ว่างจะรอต่อ ไม่ข้ามไปให้คนหลัง (มาก่อนได้ก่อน)
+func offerWaitlistSeats(tx *gorm.DB, t seatTarget, now time.Time) error {
+	for {
+		var head entity.WaitlistEntry
+		err := t.scope(tx).Where("status = ?", entity.WaitlistWaiting).Order("id").First(&head).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		u, err := loadSeatUsage(tx, t, now)
+		if err != nil {
+			return err
+		}
+		if !u.fits(head.Seats) {
+			return nil
+		}
+
+		expiresAt := now.Add(config.WaitlistOfferTTL())
+		hold := entity.SeatHold{
+			PackageID: head.PackageID,
+			EventID:   head.EventID,
+			MemberID:  head.MemberID,
+			Seats:     head.Seats,
+			Status:    entity.SeatHeld,
+			ExpiresAt: &expiresAt,
+		}
+		if err := tx.Create(&hold).Error; err != nil {
+			return err
+		}
+		note := entity.Notification{
+			MemberID: head.MemberID,
+			Message: fmt.Sprintf("%d seat(s) for %s are now available. Claim waitlist #%d before %s.",
+				head.Seats, u.Name, head.ID, expiresAt.Format("2006-01-02 15:04")),
+		}
+		if err := tx.Create(&note).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&head).Updates(map[string]any{
+			"status":           entity.WaitlistOffered,
+			"offered_at":       now,
+			"offer_expires_at": expiresAt,
+			"seat_hold_id":     hold.ID,
+			"notification_id":  note.ID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+}
+
+// GET /waitlist?member_id=&package_id=&event_id=&status= - สมาชิกดูคิวของตัวเอง แอดมินดูคิวของแพ็คเกจ/อีเวนต์
+func FindWaitlist(c *gin.Context) {
+	db := dbFor(c)
+	q := db.Model(&entity.WaitlistEntry{}).Order("id")
+	for _, key := range []string{"member_id", "package_id", "event_id"} {
+		if v := c.Query(key); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				apierr.Respond(c, apierr.BadRequest("Invalid "+key))
+				return
+			}
+			q = q.Where(key+" = ?", id)
+		}
+	}
+	if s := c.Query("status"); s != "" {
+		q = q.Where("status = ?", s)
+	}
+
+	var entries []entity.WaitlistEntry
+	if err := q.Find(&entries).Error; err != nil {
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	out := make([]waitlistView, 0, len(entries))
+	for _, e := range entries {
+		pos, err := waitlistPosition(db, e)
+		if err != nil {
+			apierr.Respond(c, apierr.Internal(err))
+			return
+		}
+		out = append(out, waitlistView{WaitlistEntry: e, Position: pos})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// POST /waitlist - เข้าคิวเมื่อที่นั่งไม่พอ (หรือมีคิวรออยู่แล้ว)
+func JoinWaitlist(c *gin.Context) {
+	var req WaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+	t, ok := seatTargetOf(strings.ToLower(strings.TrimSpace(req.ItemType)), req.PackageID, req.EventID)
+	if !ok {
+		apierr.Respond(c, apierr.BadRequest("item_type must be package (with package_id) or event (with event_id)"))
+		return
+	}
+	seats := req.Adults + req.Children
+	if seats == 0 {
+		apierr.Respond(c, apierr.BadRequest("adults or children is required"))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if !memberExists(tx, req.MemberID) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.BadRequest("Member not found"))
+		return
+	}
+	u, err := loadSeatUsage(tx, t, time.Now())
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, err)
+		return
+	}
+	if u.Waiting == 0 && u.fits(seats) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Seats are available; add the item to the cart instead").With("remaining", u.Remaining))
+		return
+	}
+	var dup int64
+	t.scope(tx.Model(&entity.WaitlistEntry{})).
+		Where("member_id = ? AND status IN ?", *req.MemberID, []string{entity.WaitlistWaiting, entity.WaitlistOffered}).
+		Count(&dup)
+	if dup > 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Member is already on this waitlist"))
+		return
+	}
+
+	entry := entity.WaitlistEntry{
+		PackageID: t.PackageID,
+		EventID:   t.EventID,
+		MemberID:  *req.MemberID,
+		Adults:    req.Adults,
+		Children:  req.Children,
+		Seats:     seats,
+		Status:    entity.WaitlistWaiting,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to join waitlist"))
+		return
+	}
+	pos, err := waitlistPosition(tx, entry)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": waitlistView{WaitlistEntry: entry, Position: pos}, "message": "Joined waitlist successfully"})
+}
+
+// POST /waitlist/:id/claim - รับข้อเสนอ ย้ายที่นั่งที่กันไว้เข้าตะกร้า (แล้ว checkout ตามปกติ)
+func ClaimWaitlistOffer(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid waitlist ID format"))
+		return
+	}
+	var req ClaimWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Respond(c, apierr.Bind(err))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var entry entity.WaitlistEntry
+	if err := tx.First(&entry, id).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.NotFound("Waitlist entry not found"))
+		return
+	}
+	if req.MemberID == nil || *req.MemberID != entry.MemberID {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Forbidden("Waitlist entry belongs to another member"))
+		return
+	}
+	now := time.Now()
+	if entry.Status != entity.WaitlistOffered || entry.SeatHoldID == nil || !entry.OfferExpiresAt.After(now) {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Waitlist entry has no open offer"))
+		return
+	}
+
+	itemType := "package"
+	if entry.EventID != nil {
+		itemType = "event"
+	}
+	quoteReq := QuoteRequest{
+		ItemType:  itemType,
+		PackageID: entry.PackageID,
+		EventID:   entry.EventID,
+		Adults:    entry.Adults,
+		Children:  entry.Children,
+	}
+	item, err := quoteItem(tx, quoteReq, now)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, err)
+		return
+	}
+	cart, err := memberCart(tx, entry.MemberID)
+	if err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to load cart"))
+		return
+	}
+	ci := entity.CartItems{
+		ItemType:  item.ItemType,
+		Added_At:  now,
+		Quatity:   quoteQuantity(quoteReq, item),
+		EventID:   entry.EventID,
+		PackageID: entry.PackageID,
+		Adults:    entry.Adults,
+		Children:  entry.Children,
+		Total:     item.Quote.Total,
+		CartID:    cart.ID,
+	}
+	if err := tx.Create(&ci).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Wrap(err, "Failed to add cart item"))
+		return
+	}
+
+	// ที่นั่งย้ายจากข้อเสนอไปเป็นของตะกร้า นับเวลาใหม่ตาม SEAT_HOLD_TTL
+	expiresAt := now.Add(config.SeatHoldTTL())
+	res := tx.Model(&entity.SeatHold{}).
+		Where("id = ? AND status = ?", *entry.SeatHoldID, entity.SeatHeld).
+		Updates(map[string]any{"cart_item_id": ci.ID, "expires_at": expiresAt})
+	if res.Error != nil || res.RowsAffected == 0 {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Offered seats are no longer held"))
+		return
+	}
+	if err := tx.Model(&entry).Update("status", entity.WaitlistClaimed).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"entry": entry, "item": ci, "quote": item.Quote}, "message": "Waitlist offer claimed successfully"})
+}
+
+// DELETE /waitlist/:id?member_id= - ออกจากคิว (เฉพาะเจ้าของคิว) ถ้ามีข้อเสนออยู่จะส่งต่อให้คิวถัดไป
+func LeaveWaitlist(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		apierr.Respond(c, apierr.BadRequest("Invalid waitlist ID format"))
+		return
+	}
+	memberID, err := strconv.Atoi(c.Query("member_id"))
+	if err != nil {
+		apierr.Respond(c, apierr.Required("member_id"))
+		return
+	}
+
+	db := dbFor(c)
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var entry entity.WaitlistEntry
+	if err := tx.First(&entry, id).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.NotFound("Waitlist entry not found"))
+		return
+	}
+	if uint(memberID) != entry.MemberID {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Forbidden("Waitlist entry belongs to another member"))
+		return
+	}
+	if entry.Status != entity.WaitlistWaiting && entry.Status != entity.WaitlistOffered {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Conflict("Waitlist entry is already "+entry.Status))
+		return
+	}
+	if err := tx.Model(&entry).Update("status", entity.WaitlistCancelled).Error; err != nil {
+		tx.Rollback()
+		apierr.Respond(c, apierr.Internal(err))
+		return
+	}
+	if entry.SeatHoldID != nil {
+		query := tx.Where("id = ? AND status = ?", *entry.SeatHoldID, entity.SeatHeld)
+		if _, err := releaseSeats(tx, query, entity.SeatReleased, time.Now()); err != nil {
+			tx.Rollback()
+			apierr.Respond(c, apierr.Wrap(err, "Failed to release seats"))
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		apierr.Respond(c, apierr.Wrap(err, "Commit failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left waitlist successfully"})
+}
@@ -0,0 +1,25 @@
+// Package dataset เก็บข้อมูลอ้างอิงที่ฝังมากับ binary (go:embed) ให้นำเข้าได้โดยไม่ต้องต่อเน็ต
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"io"
+)
+
+// thailand-geography.json.gz คือจังหวัด/อำเภอ/ตำบลจาก thailand-geography-json รวมเป็นไฟล์เดียว
+// {"provinces": [...], "districts": [...], "subdistricts": [...]} (77 / 928 / 7436 รายการ)
+//
+//go:embed thailand-geography.json.gz
+var thailandGeography []byte
+
+// ThailandGeography คืน JSON ของชุดข้อมูลภูมิศาสตร์ที่ฝังมา (คลาย gzip แล้ว)
+func ThailandGeography() ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(thailandGeography))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
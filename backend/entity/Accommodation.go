@@ -9,11 +9,11 @@ type Accommodation struct {
 	Type   string `json:"type"`
 	Status string `json:"status"`
 
-	ProvinceID   *uint    `json:"province_id"`
-	Province     Province `gorm:"foreignKey:ProvinceID"`
-	DistrictID   *uint    `json:"district_id"`
-	District     District `gorm:"foreignKey:DistrictID"`
-	SubdistrictID *uint   `json:"subdistrict_id"`
+	ProvinceID    *uint       `json:"province_id"`
+	Province      Province    `gorm:"foreignKey:ProvinceID"`
+	DistrictID    *uint       `json:"district_id"`
+	District      District    `gorm:"foreignKey:DistrictID"`
+	SubdistrictID *uint       `json:"subdistrict_id"`
 	Subdistrict   Subdistrict `gorm:"foreignKey:SubdistrictID"`
 
 	AdminID *uint `json:"admin_id"`
@@ -22,8 +22,12 @@ type Accommodation struct {
 	LocationID *uint    `json:"location_id"`
 	Location   Location `gorm:"foreignKey:LocationID;references:ID"`
 
-	Rooms        []Room       `gorm:"foreignKey:AccommodationID"`
-	Facilities   []Facility   `gorm:"many2many:accommodation_facility"`
-	Packages     []Package    `gorm:"many2many:accommodation_package"`
-	Pictures []Picture `gorm:"polymorphic:Owner;polymorphicValue:accommodation;constraint:OnDelete:CASCADE;"`
+	// พิกัดของที่พัก ถ้าไม่มีใช้พิกัดของ Location แทน
+	Latitude  *float64 `gorm:"index:idx_accommodation_geo" json:"latitude"`
+	Longitude *float64 `gorm:"index:idx_accommodation_geo" json:"longitude"`
+
+	Rooms      []Room     `gorm:"foreignKey:AccommodationID"`
+	Facilities []Facility `gorm:"many2many:accommodation_facility"`
+	Packages   []Package  `gorm:"many2many:accommodation_package"`
+	Pictures   []Picture  `gorm:"polymorphic:Owner;polymorphicValue:accommodation;constraint:OnDelete:CASCADE;"`
 }
@@ -2,23 +2,20 @@ package entity
 
 import (
 	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type BookingDetail struct {
 	gorm.Model
 
-	GuestCountPerRoom uint `json:"guest_count_per_room"`
-	NumberOfRoom uint `json:"number_of_room"`
-
-
-
+	GuestCountPerRoom uint        `json:"guest_count_per_room"`
+	NumberOfRoom      uint        `json:"number_of_room"`
+	Price             money.Money `json:"price"`
 
-	BookingID  *uint
-	Booking Booking `gorm:"foreignKey:BookingID;references:ID"`
+	BookingID *uint
+	Booking   Booking `gorm:"foreignKey:BookingID;references:ID"`
 
 	RoomID *uint
 	Room   Room `gorm:"foreignKey:RoomID;references:ID"`
-
-
-
-}
\ No newline at end of file
+}
@@ -1,23 +1,14 @@
 package entity
 
 import (
-	
-
 	"gorm.io/gorm"
 )
 
 type BookingItem struct {
+	gorm.Model
 
-   gorm.Model
-
-   BookingID     	uint      	`json:"book_id"`
-   Booking     *Booking      `gorm:"foreignKey:BookingID" json:"bookings"`
-
-   ReviewBooking []ReviewBooking `gorm:"foreignKey:BookingItemID"`
-
-
-   
+	BookingID uint     `json:"book_id"`
+	Booking   *Booking `gorm:"foreignKey:BookingID" json:"bookings"`
 
-   
- 
-}
\ No newline at end of file
+	ReviewBooking []ReviewBooking `gorm:"foreignKey:BookingItemID"`
+}
@@ -1,21 +1,23 @@
 package entity
+
 import (
 	"gorm.io/gorm"
-	"time")
+	"time"
+)
 
 type Card struct {
 	gorm.Model
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
-    CardHolderName string `gorm:"not null" json:"card_holder_name"`
+	CardHolderName string `gorm:"not null" json:"card_holder_name"`
 
-	PaymentTypeID uint `gorm:"not null" json:"payment_type_id"`
-	PaymentType *PaymentType `gorm:"foreignKey:PaymentTypeID"`
+	PaymentTypeID uint         `gorm:"not null" json:"payment_type_id"`
+	PaymentType   *PaymentType `gorm:"foreignKey:PaymentTypeID"`
 
-	Last3Digits string `gorm:"not null" json:"last_3_digits"`
-	ExpiryDate time.Time `gorm:"not null" json:"expiry_date"`
-	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	Last3Digits string    `gorm:"not null" json:"last_3_digits"`
+	ExpiryDate  time.Time `gorm:"not null" json:"expiry_date"`
+	CreatedAt   time.Time `gorm:"not null" json:"created_at"`
 
 	Paymentdetail []Paymentdetail `gorm:"foreignKey:CardID"`
-}
\ No newline at end of file
+}
@@ -4,27 +4,24 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type Cart struct {
+	gorm.Model
 
-   gorm.Model
-
-
-   Created_At     	time.Time 	`json:"added_at"`
-   Quatity        	int        	`json:"quatity"`
-   PricePerUnit   	float64    	`json:"price_per_unit"`
-
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member Member `gorm:"foreignKey:MemberID"`
-
-   CartItems []CartItems `gorm:"foreignKey:CartID"`
+	Created_At   time.Time   `json:"added_at"`
+	Quatity      int         `json:"quatity"`
+	PricePerUnit money.Money `json:"price_per_unit"`
 
-   WishListID *uint `json:"wishList_id"`
-   WishList WishList `gorm:"foreignKey:WishListID"`
+	MemberID uint   `gorm:"not null" json:"member_id"`
+	Member   Member `gorm:"foreignKey:MemberID"`
 
-   Item []Item `gorm:"foriegnKey:CartID"`
+	CartItems []CartItems `gorm:"foreignKey:CartID"`
 
+	WishListID *uint    `json:"wishList_id"`
+	WishList   WishList `gorm:"foreignKey:WishListID"`
 
- 
-}
\ No newline at end of file
+	Item []Item `gorm:"foriegnKey:CartID"`
+}
@@ -4,28 +4,36 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type CartItems struct {
+	gorm.Model
 
-   gorm.Model
+	ItemType     string      `json:"item_type"`
+	Added_At     time.Time   `json:"added_at"`
+	Quatity      int         `json:"quatity"`
+	PricePerUnit money.Money `json:"price_per_unit"`
+	Items        string      `json:"items"`
 
+	EventID *uint  `json:"event_id"`
+	Event   *Event `gorm:"foreignKey:EventID" json:"event"`
 
-   ItemType       string    `json:"item_type"`
-   Added_At       time.Time `json:"added_at"`
-   Quatity        int        `json:"quatity"`
-   PricePerUnit   float64    `json:"price_per_unit"`
-   Items      	string 		`json:"items"`
+	PackageID *uint    `json:"package_id"`
+	Package   *Package `gorm:"foreignKey:PackageID" json:"package"`
 
-   EventID    uint        `json:"event_id"`
-   Event       *Event      `gorm:"foreignKey:EventID" json:"event"`
+	RoomID *uint `json:"room_id"`
+	Room   *Room `gorm:"foreignKey:RoomID" json:"room"`
 
-   PackageID    uint        `json:"package_id"`
-   Package       *Package     `gorm:"foreignKey:PackageID" json:"package"`
+	// รายละเอียดที่ใช้ตีราคา (pricing) และยอดรวมของรายการ
+	CheckIn  time.Time   `json:"check_in"`
+	CheckOut time.Time   `json:"check_out"`
+	Adults   uint        `json:"adults"`
+	Children uint        `json:"children"`
+	Rooms    uint        `json:"rooms"`
+	Total    money.Money `json:"total"`
 
-   CartID uint `json:"cart_id"`
+	CartID uint `json:"cart_id"`
 	Cart   Cart
-
-   
-
-}
\ No newline at end of file
+}
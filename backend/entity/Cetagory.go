@@ -1,20 +1,13 @@
 package entity
 
 import (
-	
-
 	"gorm.io/gorm"
 )
 
 type Cetagory struct {
+	gorm.Model
 
-   gorm.Model
-
-
-   Cetagory_Name    string	 	`json:"cetagory_name"`
-
-   Review []Review `gorm:"foreignKey:CetagoryID"`
-
+	Cetagory_Name string `json:"cetagory_name"`
 
- 
-}
\ No newline at end of file
+	Review []Review `gorm:"foreignKey:CetagoryID"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CommissionRule กำหนดส่วนแบ่งของแพลตฟอร์มตามประเภทไกด์ หน่วยเป็น basis point (2000 = 20%)
+type CommissionRule struct {
+	gorm.Model
+
+	GuideTypeID uint      `gorm:"not null;uniqueIndex" json:"guide_type_id"`
+	GuideType   GuideType `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	RateBP uint `gorm:"not null" json:"rate_bp"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Currency คือสกุลเงินที่ใช้แสดงราคา (ราคาจริงเก็บเป็น THB เสมอ)
+type Currency struct {
+	gorm.Model
+
+	Code     string `gorm:"uniqueIndex;size:3;not null" json:"code"` // ISO 4217 เช่น USD
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Exponent uint   `gorm:"not null" json:"exponent"` // จำนวนหลักทศนิยม (JPY = 0)
+
+	Rates []ExchangeRate `gorm:"foreignKey:CurrencyCode;references:Code" json:"-"`
+}
+
+// ExchangeRate คืออัตรา 1 THB = Rate หน่วยของสกุลนั้น เก็บทุกครั้งที่แก้ (ใช้แถวล่าสุด)
+type ExchangeRate struct {
+	gorm.Model
+
+	CurrencyCode string    `gorm:"index;size:3;not null" json:"currency_code"`
+	RateMicros   int64     `gorm:"not null" json:"rate_micros"` // อัตรา x 1,000,000 เช่น 0.0275 = 27500
+	EffectiveAt  time.Time `gorm:"index;not null" json:"effective_at"`
+	Source       string    `json:"source"` // manual|csv
+}
@@ -1,20 +1,26 @@
 package entity
 
-import(
+import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
-type District struct{
-    gorm.Model
+type District struct {
+	gorm.Model
 	DistrictCode string `gorm:"size:4;not null;uniqueIndex" json:"districtCode"` // เปลี่ยนเป็น size:4
-    NameTh      string `json:"districtNameTh"`
-    NameEn      string `json:"districtNameEn"`
+	NameTh       string `json:"districtNameTh"`
+	NameEn       string `json:"districtNameEn"`
+
+	// อำเภอที่ถูกแยก/ยุบจะมี RetiredAt (ดู Province)
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	RetiredAt *time.Time `gorm:"index" json:"retired_at,omitempty"`
 
 	ProvinceID uint     `gorm:"not null;index" json:"province_id"`
-    Province   Province `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Province   Province `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
 
-    Subdistricts   []Subdistrict   `gorm:"foreignKey:DistrictID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
-    Accommodations []Accommodation `gorm:"foreignKey:DistrictID"`
-    Events         []Event         `gorm:"foreignKey:DistrictID"`
-    Packages       []Package       `gorm:"foreignKey:DistrictID"`
-}
\ No newline at end of file
+	Subdistricts   []Subdistrict   `gorm:"foreignKey:DistrictID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Accommodations []Accommodation `gorm:"foreignKey:DistrictID"`
+	Events         []Event         `gorm:"foreignKey:DistrictID"`
+	Packages       []Package       `gorm:"foreignKey:DistrictID"`
+}
@@ -7,11 +7,9 @@ import (
 type DocumentPath struct {
 	gorm.Model
 	DocumentPath string `gorm:"not null"`
-	
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
-
-	GuideApplication []GuideApplication `gorm:"foreignKey:DocumentPathID"`
 
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
-}
\ No newline at end of file
+	GuideApplication []GuideApplication `gorm:"foreignKey:DocumentPathID"`
+}
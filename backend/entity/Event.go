@@ -1,38 +1,44 @@
 package entity
 
 import (
-	"time"
 	"gorm.io/gorm"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type Event struct {
-    gorm.Model
-    Event_Name string    `json:"event_name"`
-    Added_At   time.Time `json:"added_at"`
-    Price      float64   `json:"price"`
-    Host       string    `json:"host"`
-    Status     string    `json:"status"`
+	gorm.Model
+	Event_Name   string        `json:"event_name"`
+	Added_At     time.Time     `json:"added_at"`
+	Price        float64       `json:"price"`
+	DisplayPrice *money.Amount `gorm:"-" json:"display_price,omitempty"` // ราคาในสกุลที่ขอผ่าน ?currency=
+	Host         string        `json:"host"`
+	Status       string        `json:"status"`
+	Quota        uint          `json:"quota"` // จำนวนที่นั่ง 0 = ไม่จำกัด
 
-    // ผู้ดูแล
-    AdminID *uint `json:"admin_id"`
-    Admin   Admin `gorm:"foreignKey:AdminID;references:ID"`
+	// ผู้ดูแล
+	AdminID *uint `json:"admin_id"`
+	Admin   Admin `gorm:"foreignKey:AdminID;references:ID"`
 
-    EventTypeID     uint      		`json:"event_type_id"`
-    EventType     	*EventType      `gorm:"foreignKey:EventTypeID" json:"event_type"`
+	EventTypeID uint       `json:"event_type_id"`
+	EventType   *EventType `gorm:"foreignKey:EventTypeID" json:"event_type"`
 
-    LocationID *uint    `json:"location_id"`
-   Location   Location `gorm:"foreignKey:LocationID;references:ID"`
-    ProvinceID    *uint      `json:"province_id"`
-    Province      Province   `gorm:"foreignKey:ProvinceID;references:ID"`
-    DistrictID    *uint      `json:"district_id"`
-    District      District   `gorm:"foreignKey:DistrictID;references:ID"`
-    SubdistrictID *uint      `json:"subdistrict_id"`
-    Subdistrict   Subdistrict`gorm:"foreignKey:SubdistrictID;references:ID"`
-    ReservationID *uint
+	LocationID    *uint       `json:"location_id"`
+	Location      Location    `gorm:"foreignKey:LocationID;references:ID"`
+	Latitude      *float64    `gorm:"index:idx_event_geo" json:"latitude"` // พิกัดสถานที่จัดงาน ถ้าไม่มีใช้ของ Location
+	Longitude     *float64    `gorm:"index:idx_event_geo" json:"longitude"`
+	ProvinceID    *uint       `json:"province_id"`
+	Province      Province    `gorm:"foreignKey:ProvinceID;references:ID"`
+	DistrictID    *uint       `json:"district_id"`
+	District      District    `gorm:"foreignKey:DistrictID;references:ID"`
+	SubdistrictID *uint       `json:"subdistrict_id"`
+	Subdistrict   Subdistrict `gorm:"foreignKey:SubdistrictID;references:ID"`
+	ReservationID *uint
 	Reservation   Reservation `gorm:"foriegnKey:RerservationID"`
 
-    // ความสัมพันธ์อื่น
-    Packages []Package `gorm:"many2many:event_package"`
-    Item []Item `gorm:"foreignKey:EventID"`
+	// ความสัมพันธ์อื่น
+	Packages []Package   `gorm:"many2many:event_package"`
+	Item     []Item      `gorm:"foreignKey:EventID"`
 	CartItem []CartItems `gorm:"foreignKey:EventID"`
 }
@@ -12,4 +12,4 @@ type EventPackage struct {
 
 	PackageID *uint
 	Package   Package `gorm:"foriegnKey:PackageID"`
-}
\ No newline at end of file
+}
@@ -1,20 +1,17 @@
 package entity
 
 import (
-
 	"gorm.io/gorm"
 )
 
 type EventType struct {
+	gorm.Model
 
-   gorm.Model
-
-   Type_Name      string    `json:"type_name"`
-
-   AdminID       *uint      `json:"admin_id"`
-   Admin         Admin     `gorm:"foreignKey:AdminID"`
+	Type_Name string `json:"type_name"`
 
-	Event []Event `gorm:"foreignKey:EventTypeID"`
-   Reservation []Reservation  `gorm:"foriegnKey:EventTypeID"`
+	AdminID *uint `json:"admin_id"`
+	Admin   Admin `gorm:"foreignKey:AdminID"`
 
-}
\ No newline at end of file
+	Event       []Event       `gorm:"foreignKey:EventTypeID"`
+	Reservation []Reservation `gorm:"foriegnKey:EventTypeID"`
+}
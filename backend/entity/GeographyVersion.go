@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// การเปลี่ยนแปลงของจังหวัด/อำเภอ/ตำบลในแต่ละเวอร์ชัน
+const (
+	GeographyAdded    = "added"
+	GeographyChanged  = "changed"
+	GeographyRetired  = "retired"
+	GeographyRestored = "restored"
+)
+
+// GeographyVersion คือชุดข้อมูลภูมิศาสตร์ที่นำเข้าแล้ว 1 ครั้ง มีผลตั้งแต่ EffectiveDate
+type GeographyVersion struct {
+	gorm.Model
+
+	Version       string    `gorm:"not null;uniqueIndex" json:"version"`
+	EffectiveDate time.Time `gorm:"index" json:"effective_date"`
+	Source        string    `json:"source"`   // embedded | remote | upload
+	Checksum      string    `json:"checksum"` // sha256 ของข้อมูลหลัง normalize
+	Note          string    `json:"note"`
+
+	Added      int `json:"added"`
+	Changed    int `json:"changed"`
+	Retired    int `json:"retired"`
+	Restored   int `json:"restored"`
+	Unchanged  int `json:"unchanged"`
+	Dependents int `json:"dependents"` // จำนวนแถว (ที่พัก/แพ็คเกจ/อีเวนต์/...) ที่อ้างรายการที่เปลี่ยน
+
+	JobID *uint `json:"job_id"`
+
+	Changes []GeographyChange `gorm:"foreignKey:GeographyVersionID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// GeographyChange คือรายการที่เปลี่ยนใน 1 เวอร์ชัน
+type GeographyChange struct {
+	gorm.Model
+
+	GeographyVersionID uint   `gorm:"not null;index" json:"geography_version_id"`
+	Level              string `gorm:"not null;index:idx_geography_change_code" json:"level"`
+	Code               string `gorm:"not null;index:idx_geography_change_code" json:"code"`
+	NameTh             string `json:"name_th"`
+	Action             string `gorm:"not null;index" json:"action"`
+
+	// Fields = ฟิลด์ -> [ค่าเดิม, ค่าใหม่], Dependents = ตาราง -> ID ของแถวที่อ้างรายการนี้
+	Fields     json.RawMessage `gorm:"type:text" json:"fields,omitempty"`
+	Dependents json.RawMessage `gorm:"type:text" json:"dependents,omitempty"`
+}
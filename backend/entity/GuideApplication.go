@@ -8,23 +8,23 @@ import (
 
 type GuideApplication struct {
 	gorm.Model
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
-	FirstName     string      `gorm:"not null" json:"first_name"`
-	LastName      string      `gorm:"not null" json:"last_name"`
-	Age           int         `gorm:"not null" json:"age"`
-	Sex           string      `gorm:"not null" json:"sex"`
-	Phone         string      `gorm:"not null" json:"phone"`
-	Email         string      `gorm:"not null" json:"email"`
+	FirstName string `gorm:"not null" json:"first_name"`
+	LastName  string `gorm:"not null" json:"last_name"`
+	Age       int    `gorm:"not null" json:"age"`
+	Sex       string `gorm:"not null" json:"sex"`
+	Phone     string `gorm:"not null" json:"phone"`
+	Email     string `gorm:"not null" json:"email"`
 
-	LanguageID *uint `gorm:"not null" json:"language_id"`
-	Language Language `gorm:"foreignKey:LanguageID"`
+	LanguageID *uint    `gorm:"not null" json:"language_id"`
+	Language   Language `gorm:"foreignKey:LanguageID"`
 
-	ServiceAreaID *uint        `gorm:"not null;index" json:"service_area_id"`
-	ServiceArea   ServiceArea `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
-    DocumentPathID *uint
-    DocumentPath DocumentPath `gorm:"foreignKey:DocumentPathID"`
+	ServiceAreaID  *uint       `gorm:"not null;index" json:"service_area_id"`
+	ServiceArea    ServiceArea `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	DocumentPathID *uint
+	DocumentPath   DocumentPath `gorm:"foreignKey:DocumentPathID"`
 
-	SubmittedAt   time.Time   `gorm:"autoCreateTime" json:"submitted_at"` // เซ็ตให้อัตโนมัติ
+	SubmittedAt time.Time `gorm:"autoCreateTime" json:"submitted_at"` // เซ็ตให้อัตโนมัติ
 }
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GuideAvailability คือช่วงวันที่ไกด์ไม่ว่าง (blocked / leave) ทั้งสองวันนับรวม
+type GuideAvailability struct {
+	gorm.Model
+
+	GuideID uint  `gorm:"not null;index" json:"guide_id"`
+	Guide   Guide `gorm:"foreignKey:GuideID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	StartDate time.Time `gorm:"not null;index" json:"start_date"`
+	EndDate   time.Time `gorm:"not null;index" json:"end_date"`
+	Kind      string    `gorm:"not null;default:blocked" json:"kind"` // blocked|leave
+	Note      string    `json:"note"`
+}
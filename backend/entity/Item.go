@@ -8,9 +8,9 @@ import (
 
 type Item struct {
 	gorm.Model
-	Type       string       `json:"type"`
-	Price      string       `json:"price"`
-	DateTime   time.Time    `json:"date_time"`
+	Type     string    `json:"type"`
+	Price    string    `json:"price"`
+	DateTime time.Time `json:"date_time"`
 
 	LocationID *uint
 	Location   Location `gorm:"foriegnKey:LocationID"`
@@ -28,4 +28,4 @@ type Item struct {
 	WishList   WishList `gorm:"foriegnKey:WishListID"`
 
 	ItemLocation []ItemLocation `gorm:"foreignKey:ItemID"`
-}
\ No newline at end of file
+}
@@ -8,8 +8,8 @@ type ItemLocation struct {
 	gorm.Model
 
 	ItemID *uint
-	Item   Item`gorm:"foriegnKey:ItemID"`
+	Item   Item `gorm:"foriegnKey:ItemID"`
 
 	LocationID *uint
 	Location   Location `gorm:"foriegnKey:LocationID"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ItineraryDay คือวันหนึ่งในกำหนดการของแพ็คเกจ เรียงตาม DayNumber (เริ่มที่ 1)
+type ItineraryDay struct {
+	gorm.Model
+
+	PackageID uint    `gorm:"not null;uniqueIndex:uniq_package_day" json:"package_id"`
+	Package   Package `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	DayNumber   uint      `gorm:"not null;uniqueIndex:uniq_package_day" json:"day_number"`
+	Date        time.Time `gorm:"not null" json:"date"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+
+	Activities []ItineraryActivity `gorm:"foreignKey:ItineraryDayID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"activities"`
+}
+
+// ItineraryActivity คือกิจกรรมตามเวลาในวันนั้น อ้างถึง Event, Location หรือคืนที่พัก (PackageStay) อย่างใดอย่างหนึ่ง
+type ItineraryActivity struct {
+	gorm.Model
+
+	ItineraryDayID uint `gorm:"not null;index" json:"itinerary_day_id"`
+
+	Seq         uint   `gorm:"not null" json:"seq"`
+	StartTime   string `gorm:"size:5" json:"start_time"` // "HH:MM"
+	EndTime     string `gorm:"size:5" json:"end_time"`   // "HH:MM"
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	EventID *uint  `gorm:"index" json:"event_id"`
+	Event   *Event `gorm:"foreignKey:EventID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+
+	LocationID *uint     `gorm:"index" json:"location_id"`
+	Location   *Location `gorm:"foreignKey:LocationID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+
+	PackageStayID *uint        `gorm:"index" json:"package_stay_id"`
+	PackageStay   *PackageStay `gorm:"foreignKey:PackageStayID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะของ Job
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job คืองานเบื้องหลังที่ใช้เวลานาน (นำเข้าข้อมูล, สร้างรายงาน, ลบจำนวนมาก) ทำโดย worker ใน package jobs
+// งานที่ค้างสถานะ running ตอนเปิดระบบจะถูกนำกลับเข้าคิวแล้วทำใหม่ ตัวงานจึงต้องทำซ้ำได้
+type Job struct {
+	gorm.Model
+
+	Kind            string     `gorm:"not null;index" json:"kind"`
+	Status          string     `gorm:"not null;index;default:queued" json:"status"`
+	Payload         string     `json:"-"`                                  // JSON ที่ตัวงานใช้
+	Result          string     `json:"-"`                                  // JSON ผลลัพธ์เมื่อสำเร็จ
+	Progress        int        `gorm:"not null;default:0" json:"progress"` // 0-100
+	Message         string     `json:"message"`
+	Error           string     `json:"error,omitempty"` // ข้อผิดพลาดล่าสุดที่แสดงผู้ใช้ได้ (ยังมีได้ถ้ากำลังรอลองใหม่) สาเหตุจริงอยู่ใน log
+	Attempts        int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts     int        `gorm:"not null;default:1" json:"max_attempts"`
+	CancelRequested bool       `gorm:"not null;default:false" json:"cancel_requested"`
+	RunAfter        time.Time  `gorm:"index" json:"run_after"` // ลองใหม่ได้หลังเวลานี้
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	TraceParent     string     `json:"-"` // W3C traceparent ของ request ที่สั่งงาน ให้ span ของงานต่อ trace เดิม
+}
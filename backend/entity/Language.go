@@ -1,18 +1,14 @@
 package entity
 
 import (
-	
-
 	"gorm.io/gorm"
 )
 
 type Language struct {
 	gorm.Model
 
-	Name  string    `json:"name"`
-	Description  string    `json:"description"`
-	Guide []Guide `gorm:"many2many:guide_language"`
+	Name             string             `json:"name"`
+	Description      string             `json:"description"`
+	Guide            []Guide            `gorm:"many2many:guide_language"`
 	GuideApplication []GuideApplication `gorm:"foreignKey:LanguageID"`
-
-
-}
\ No newline at end of file
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+// บัญชีในสมุดรายวัน
+const (
+	AccountCash               = "cash"
+	AccountPlatformCommission = "platform_commission"
+	AccountGuidePayable       = "guide_payable"
+)
+
+// LedgerEntry คือรายการบัญชีคู่ 1 ขา รายการที่มี TxRef เดียวกันต้องมียอด Debit = Credit
+type LedgerEntry struct {
+	gorm.Model
+
+	TxRef   string      `gorm:"not null;index" json:"tx_ref"`
+	Account string      `gorm:"not null;index" json:"account"`
+	Debit   money.Money `gorm:"not null;default:0" json:"debit"`
+	Credit  money.Money `gorm:"not null;default:0" json:"credit"`
+	Memo    string      `json:"memo"`
+
+	ReservationID *uint        `gorm:"index" json:"reservation_id"`
+	Reservation   *Reservation `gorm:"foreignKey:ReservationID" json:"-"`
+
+	PaymentdetailID *uint          `gorm:"index" json:"payment_detail_id"`
+	Paymentdetail   *Paymentdetail `gorm:"foreignKey:PaymentdetailID" json:"-"`
+
+	PackageID *uint    `json:"package_id"`
+	Package   *Package `gorm:"foreignKey:PackageID" json:"-"`
+
+	GuideID *uint  `gorm:"index" json:"guide_id"`
+	Guide   *Guide `gorm:"foreignKey:GuideID" json:"-"`
+
+	PayoutBatchID *uint        `gorm:"index" json:"payout_batch_id"`
+	PayoutBatch   *PayoutBatch `gorm:"foreignKey:PayoutBatchID" json:"-"`
+}
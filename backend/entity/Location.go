@@ -1,21 +1,23 @@
 package entity
 
 import (
-	
-
 	"gorm.io/gorm"
 )
 
 type Location struct {
 	gorm.Model
 
-	Name     		string     `json:"name"`
-	ProvinceID   *uint    `json:"province_id"`
-	Province     Province `gorm:"foreignKey:ProvinceID"`
+	Name       string   `json:"name"`
+	ProvinceID *uint    `json:"province_id"`
+	Province   Province `gorm:"foreignKey:ProvinceID"`
+
+	// พิกัด WGS84 (องศาทศนิยม) nil = ยังไม่ได้ปักหมุด
+	Latitude  *float64 `gorm:"index:idx_location_geo" json:"latitude"`
+	Longitude *float64 `gorm:"index:idx_location_geo" json:"longitude"`
 
-	Event []Event `gorm:"foreignKey:LocationID"`
-	Package []Package `gorm:"foreignKey:LocationID"`
-	Item []Item `gorm:"foreignKey:LocationID"`
-	Accommodation []Accommodation  `gorm:"foreignKey:LocationID"`
-	ItemLocation []ItemLocation `gorm:"foreignKey:LocationID"`
-}
\ No newline at end of file
+	Event         []Event         `gorm:"foreignKey:LocationID"`
+	Package       []Package       `gorm:"foreignKey:LocationID"`
+	Item          []Item          `gorm:"foreignKey:LocationID"`
+	Accommodation []Accommodation `gorm:"foreignKey:LocationID"`
+	ItemLocation  []ItemLocation  `gorm:"foreignKey:LocationID"`
+}
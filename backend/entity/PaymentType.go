@@ -4,10 +4,10 @@ import "gorm.io/gorm"
 
 type PaymentType struct {
 	gorm.Model
-    PaymentMethod string `gorm:"not null" json:"payment_method"`
-	Brand string `gorm:"not null" json:"brand"`
-	Description string `gorm:"not null" json:"description"`
-	
+	PaymentMethod string `gorm:"not null" json:"payment_method"`
+	Brand         string `gorm:"not null" json:"brand"`
+	Description   string `gorm:"not null" json:"description"`
+
 	Paymentdetail []Paymentdetail `gorm:"foreignKey:PaymentTypeID"`
-	Card []Card `gorm:"foreignKey:PaymentTypeID"`
-}
\ No newline at end of file
+	Card          []Card          `gorm:"foreignKey:PaymentTypeID"`
+}
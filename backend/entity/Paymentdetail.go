@@ -1,25 +1,27 @@
 package entity
+
 import (
 	"gorm.io/gorm"
 	"time"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type Paymentdetail struct {
 	gorm.Model
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
-	
-	CardID uint `gorm:"not null" json:"card_id"`
-	Card *Card `gorm:"foreignKey:CardID"`
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
-	PaymentTypeID uint `gorm:"not null" json:"payment_type_id"`
-	PaymentType *PaymentType `gorm:"foreignKey:PaymentTypeID"`
+	CardID uint  `gorm:"not null" json:"card_id"`
+	Card   *Card `gorm:"foreignKey:CardID"`
 
-    Amount_id int `gorm:"not null" json:"amount_id"`
-	Payment_date time.Time `gorm:"not null" json:"payment_date"`
-	PatmentNumber string `gorm:"not null" json:"payment_number"`
-	Status string `gorm:"not null" json:"payment_status"`
+	PaymentTypeID uint         `gorm:"not null" json:"payment_type_id"`
+	PaymentType   *PaymentType `gorm:"foreignKey:PaymentTypeID"`
 
-	Receipt []Receipt `gorm:"foreignKey:PaymentdetailID"`
+	Amount_id     money.Money `gorm:"not null" json:"amount_id"`
+	Payment_date  time.Time   `gorm:"not null" json:"payment_date"`
+	PatmentNumber string      `gorm:"not null" json:"payment_number"`
+	Status        string      `gorm:"not null" json:"payment_status"`
 
-}
\ No newline at end of file
+	Receipt []Receipt `gorm:"foreignKey:PaymentdetailID"`
+}
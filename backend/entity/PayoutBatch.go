@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+// สถานะรอบจ่ายเงินไกด์: draft -> approved -> paid (ยกเลิกได้ก่อน paid)
+const (
+	PayoutDraft     = "draft"
+	PayoutApproved  = "approved"
+	PayoutPaid      = "paid"
+	PayoutCancelled = "cancelled"
+)
+
+type PayoutBatch struct {
+	gorm.Model
+
+	Status    string      `gorm:"not null;default:draft;index" json:"status"`
+	PeriodEnd time.Time   `gorm:"not null" json:"period_end"`
+	Total     money.Money `gorm:"not null;default:0" json:"total"`
+	Note      string      `json:"note"`
+	PaidAt    *time.Time  `json:"paid_at"`
+
+	Lines []PayoutLine `gorm:"foreignKey:PayoutBatchID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"lines"`
+}
+
+// PayoutLine คือยอดที่ต้องจ่ายให้ไกด์ 1 คนในรอบนั้น
+type PayoutLine struct {
+	gorm.Model
+
+	PayoutBatchID uint `gorm:"not null;index" json:"payout_batch_id"`
+
+	GuideID uint   `gorm:"not null;index" json:"guide_id"`
+	Guide   *Guide `gorm:"foreignKey:GuideID" json:"-"`
+
+	Amount money.Money `gorm:"not null" json:"amount"`
+}
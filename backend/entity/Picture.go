@@ -2,11 +2,11 @@ package entity
 
 import "gorm.io/gorm"
 
-type Picture struct{
+type Picture struct {
 	gorm.Model
-	Url		string `json:"picture_url" gorm:"not null"` 
+	Url string `json:"picture_url" gorm:"not null"`
 
 	// polymorphic fields (GORM จะใช้เอง)
 	OwnerID   uint   `json:"owner_id" gorm:"index"`
-    OwnerType string `json:"owner_type" gorm:"index"`
-}
\ No newline at end of file
+	OwnerType string `json:"owner_type" gorm:"index"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ระดับพื้นที่ของ PlaceAlias
+const (
+	PlaceProvince    = "province"
+	PlaceDistrict    = "district"
+	PlaceSubdistrict = "subdistrict"
+)
+
+// PlaceAlias คือชื่อเรียกอื่นของจังหวัด/อำเภอ/ตำบลที่ NameTh/NameEn ไม่ครอบคลุม (เช่น Korat = นครราชสีมา, กทม)
+// อ้างด้วยรหัสพื้นที่แทน ID เพื่อให้ใช้ต่อได้หลังนำเข้าข้อมูลภูมิศาสตร์ใหม่
+type PlaceAlias struct {
+	gorm.Model
+
+	Alias string `gorm:"not null;uniqueIndex:idx_place_alias" json:"alias"`
+	Level string `gorm:"not null;uniqueIndex:idx_place_alias" json:"level"` // province|district|subdistrict
+	Code  string `gorm:"not null;uniqueIndex:idx_place_alias" json:"code"`  // ProvinceCode/DistrictCode/SubdistrictCode
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PricingRule คือกฎราคาที่ใช้กับห้องพัก/แพ็คเกจ/อีเวนต์ (ดู package pricing)
+type PricingRule struct {
+	gorm.Model
+
+	Name        string `gorm:"not null" json:"name"`
+	Scope       string `gorm:"not null;index" json:"scope"`            // room|package|event
+	TargetID    *uint  `gorm:"index" json:"target_id"`                 // nil = ใช้กับทุกรายการใน scope
+	Kind        string `gorm:"not null" json:"kind"`                   // season|weekend|child|early_bird|last_minute|group
+	Percent     int    `gorm:"not null" json:"percent"`                // +30 = บวกเพิ่ม 30%, -10 = ลด 10%, child 50 = จ่ายครึ่งราคา
+	Days        int    `gorm:"not null;default:0" json:"days"`         // early_bird / last_minute
+	MinQuantity int    `gorm:"not null;default:0" json:"min_quantity"` // group: จำนวนคน (per_person) หรือห้อง (per_room) ขั้นต่ำ
+	Active      bool   `gorm:"not null;default:true" json:"active"`
+
+	StartDate *time.Time `json:"start_date"` // season
+	EndDate   *time.Time `json:"end_date"`   // season
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+const (
+	PromotionPercent = "percent" // Value = เปอร์เซ็นต์ที่ลด (1-100)
+	PromotionFixed   = "fixed"   // Value = จำนวนเงินที่ลด (หน่วยย่อย เช่น สตางค์)
+)
+
+// ขอบเขตของโค้ด ถ้าไม่มี scope เลย = ใช้ได้กับทุกรายการ
+const (
+	PromotionScopePackage           = "package"
+	PromotionScopeEvent             = "event"
+	PromotionScopeProvince          = "province"
+	PromotionScopeAccommodationType = "accommodation_type"
+)
+
+// Promotion คือโค้ดส่วนลดที่ใช้ตอน checkout
+type Promotion struct {
+	gorm.Model
+
+	Code     string      `gorm:"uniqueIndex;not null" json:"code"`
+	Name     string      `json:"name"`
+	Kind     string      `gorm:"not null" json:"kind"` // percent|fixed
+	Value    int64       `gorm:"not null" json:"value"`
+	MinSpend money.Money `gorm:"not null;default:0" json:"min_spend"` // ยอดขั้นต่ำของรายการที่เข้าเงื่อนไข
+	Active   bool        `gorm:"not null;default:true" json:"active"`
+
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+
+	UsageLimit     *uint `json:"usage_limit"`      // nil = ไม่จำกัด
+	PerMemberLimit *uint `json:"per_member_limit"` // nil = ไม่จำกัด
+	UsedCount      uint  `gorm:"not null;default:0" json:"used_count"`
+
+	Scopes      []PromotionScope      `gorm:"foreignKey:PromotionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"scopes"`
+	Redemptions []PromotionRedemption `gorm:"foreignKey:PromotionID" json:"-"`
+}
+
+// PromotionScope จำกัดโค้ดให้ใช้กับแพ็คเกจ/อีเวนต์/จังหวัด (TargetID) หรือประเภทที่พัก (TargetValue)
+type PromotionScope struct {
+	gorm.Model
+
+	PromotionID uint   `gorm:"not null;index" json:"promotion_id"`
+	Kind        string `gorm:"not null" json:"kind"`
+	TargetID    *uint  `json:"target_id"`
+	TargetValue string `json:"target_value"`
+}
+
+// PromotionRedemption คือการใช้โค้ด 1 ครั้งต่อ 1 การจอง
+type PromotionRedemption struct {
+	gorm.Model
+
+	PromotionID uint       `gorm:"not null;index" json:"promotion_id"`
+	Promotion   *Promotion `gorm:"foreignKey:PromotionID" json:"promotion,omitempty"`
+
+	MemberID uint `gorm:"not null;index" json:"member_id"`
+
+	ReservationID uint `gorm:"not null;uniqueIndex" json:"reservation_id"`
+
+	Code   string      `gorm:"not null" json:"code"`
+	Amount money.Money `gorm:"not null" json:"amount"`
+}
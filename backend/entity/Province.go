@@ -1,18 +1,24 @@
 package entity
 
-import(
+import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
-type Province struct{
-    gorm.Model
-    ProvinceCode string `gorm:"size:2;not null;uniqueIndex" json:"provinceCode"`
-    NameTh string `json:"provinceNameTh"`
-    NameEn string `json:"provinceNameEn"`
+type Province struct {
+	gorm.Model
+	ProvinceCode string `gorm:"size:2;not null;uniqueIndex" json:"provinceCode"`
+	NameTh       string `json:"provinceNameTh"`
+	NameEn       string `json:"provinceNameEn"`
+
+	// ช่วงที่ใช้ได้ตามชุดข้อมูล (GeographyVersion) รายการที่เลิกใช้ไม่ถูกลบเพราะมีที่พัก/แพ็คเกจอ้างอยู่
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	RetiredAt *time.Time `gorm:"index" json:"retired_at,omitempty"`
 
-    Districts      []District      `gorm:"foreignKey:ProvinceID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
-    Location       []Location      `gorm:"foreignKey:ProvinceID"`
-    Accommodations []Accommodation `gorm:"foreignKey:ProvinceID"`
-    Events         []Event         `gorm:"foreignKey:ProvinceID"`  
-    Packages       []Package       `gorm:"foreignKey:ProvinceID"`
-}
\ No newline at end of file
+	Districts      []District      `gorm:"foreignKey:ProvinceID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Location       []Location      `gorm:"foreignKey:ProvinceID"`
+	Accommodations []Accommodation `gorm:"foreignKey:ProvinceID"`
+	Events         []Event         `gorm:"foreignKey:ProvinceID"`
+	Packages       []Package       `gorm:"foreignKey:ProvinceID"`
+}
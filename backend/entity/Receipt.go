@@ -3,17 +3,20 @@ package entity
 import (
 	"gorm.io/gorm"
 	"time"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type Receipt struct {
 	gorm.Model
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
-	PaymentdetailID uint `gorm:"not null" json:"payment_detail_id"`
-	Paymentdetail *Paymentdetail `gorm:"foreignKey:PaymentdetailID"`
+	PaymentdetailID uint           `gorm:"not null" json:"payment_detail_id"`
+	Paymentdetail   *Paymentdetail `gorm:"foreignKey:PaymentdetailID"`
 
-	Issued_date time.Time `gorm:"not null" json:"issued_date"`
+	Issued_date time.Time   `gorm:"not null" json:"issued_date"`
+	Amount      money.Money `gorm:"not null;default:0" json:"amount"` // หน่วยย่อย (สตางค์)
 
 	ReservationHistory []ReservationHistory `gorm:"foriegnKey:ReservationHistoryID"`
-}
\ No newline at end of file
+}
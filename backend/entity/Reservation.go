@@ -4,22 +4,27 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
 )
 
 type Reservation struct {
 	gorm.Model
-	Status     string      `json:"status"`
-	DateTime   time.Time   `json:"date_time"`
+	Status   string      `json:"status"`
+	DateTime time.Time   `json:"date_time"`
+	Total    money.Money `json:"total"`    // ยอดสุทธิหลังหักส่วนลด
+	Discount money.Money `json:"discount"` // ส่วนลดจากโค้ดโปรโมชัน
 
 	EventTypeID *uint
 	EventType   EventType `gorm:"foreignKey:EventTypeID"`
 
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
 	Event []Event `gorm:"foreignKey:ReservationID"`
 
 	Package []Package `gorm:"foreignKey:ReservationID"`
 
 	ReservationHistory []ReservationHistory `gorm:"foreignKey:ReservationID"`
-}
\ No newline at end of file
+	Items              []ReservationItem    `gorm:"foreignKey:ReservationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items"`
+}
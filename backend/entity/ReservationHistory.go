@@ -8,15 +8,12 @@ import (
 
 type ReservationHistory struct {
 	gorm.Model
-	Status     string      `json:"status"`
-	DateTime   time.Time   `json:"date_time"`
+	Status   string    `json:"status"`
+	DateTime time.Time `json:"date_time"`
 
 	ReservationID *uint
 	Reservation   Reservation `gorm:"foriegnKey:ReservationID"`
 
 	ReceiptID *uint
 	Receipt   Receipt `gorm:"foriegnKey:ReservationHistoryID"`
-
-
-
-}
\ No newline at end of file
+}
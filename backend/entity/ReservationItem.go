@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+// ReservationItem คือรายการที่ถูก checkout จากตะกร้า พร้อมราคาที่ตีไว้ตอนนั้น
+type ReservationItem struct {
+	gorm.Model
+
+	ReservationID uint `gorm:"not null;index" json:"reservation_id"`
+
+	ItemType string `gorm:"not null" json:"item_type"` // package|event|room
+
+	PackageID *uint    `gorm:"index" json:"package_id"`
+	Package   *Package `gorm:"foreignKey:PackageID" json:"package,omitempty"`
+
+	EventID *uint  `gorm:"index" json:"event_id"`
+	Event   *Event `gorm:"foreignKey:EventID" json:"event,omitempty"`
+
+	RoomID *uint `gorm:"index" json:"room_id"`
+	Room   *Room `gorm:"foreignKey:RoomID" json:"room,omitempty"`
+
+	BookingID *uint    `gorm:"index" json:"booking_id"`
+	Booking   *Booking `gorm:"foreignKey:BookingID" json:"-"`
+
+	CheckIn  time.Time   `json:"check_in"`
+	CheckOut time.Time   `json:"check_out"`
+	Adults   uint        `json:"adults"`
+	Children uint        `json:"children"`
+	Rooms    uint        `json:"rooms"`
+	Discount money.Money `json:"discount"` // ส่วนลดโค้ดที่แบ่งมาให้รายการนี้
+	Total    money.Money `json:"total"`    // ยอดสุทธิหลังหักส่วนลด
+}
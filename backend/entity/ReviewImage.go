@@ -7,17 +7,11 @@ import (
 )
 
 type ReviewImage struct {
+	gorm.Model
 
-   gorm.Model
+	Image_path string    `json:"review_path"`
+	Upload_At  time.Time `json:"upload_at"`
 
-
-   Image_path     	string  	`json:"review_path"`
-   Upload_At        time.Time   `json:"upload_at"`
-   
-
-   ReviewID      	uint      	`json:"review_id"`
-   Review     		*Review     `gorm:"foreignKey:ReviewID " json:"review"`
-
-   
- 
-}
\ No newline at end of file
+	ReviewID uint    `json:"review_id"`
+	Review   *Review `gorm:"foreignKey:ReviewID " json:"review"`
+}
@@ -1,22 +1,28 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
 
 type Room struct {
 	gorm.Model
 
-	Name   string `json:"name"`
-	Type   string `json:"type"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
 	BedType string `json:"bed_type"`
 
-	People uint   `json:"people"` // ให้ตรงกับ frontend/services
-	Price  uint   `json:"price"`
-	Status string `json:"status"`
+	People       uint          `json:"people"` // ให้ตรงกับ frontend/services
+	Price        uint          `json:"price"`
+	PriceUnit    string        `gorm:"not null;default:per_room" json:"price_unit"` // per_room|per_person ต่อคืน
+	Status       string        `json:"status"`
+	DisplayPrice *money.Amount `gorm:"-" json:"display_price,omitempty"` // ราคาในสกุลที่ขอผ่าน ?currency=
 
 	AccommodationID *uint         `json:"accommodation_id"`
 	Accommodation   Accommodation `gorm:"foreignKey:AccommodationID;references:ID"`
 
 	// relations อื่น ๆ ค่อยตามทีหลังได้
 	Facilities []Facility `gorm:"many2many:room_facility"`
-	Pictures []Picture `gorm:"polymorphic:Owner;polymorphicValue:room;constraint:OnDelete:CASCADE;"`
+	Pictures   []Picture  `gorm:"polymorphic:Owner;polymorphicValue:room;constraint:OnDelete:CASCADE;"`
 }
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// SearchDocument คือข้อความที่ใช้ค้นหาของแพ็คเกจ/ที่พัก/อีเวนต์ 1 รายการ
+// ตาราง FTS5 (search_fts) อ่านจากตารางนี้ผ่าน trigger ห้ามแก้ search_fts ตรงๆ
+// ลบจริง (ไม่มี soft delete) เพื่อให้ trigger ลบออกจาก FTS ด้วย
+type SearchDocument struct {
+	ID      uint   `gorm:"primarykey" json:"id"`
+	DocType string `gorm:"not null;uniqueIndex:idx_search_doc" json:"doc_type"` // package|accommodation|event
+	DocID   uint   `gorm:"not null;uniqueIndex:idx_search_doc" json:"doc_id"`
+	Title   string `gorm:"not null" json:"title"`
+	Body    string `gorm:"not null" json:"body"` // ชื่อสถานที่ไทย/อังกฤษ, สิ่งอำนวยความสะดวก, ผู้จัดงาน
+	// TitleWords/Keywords คือ Title/Body ที่ตัดคำไทยแล้ว (คั่นด้วยช่องว่าง) ใช้ค้นหาจริง
+	// Keywords มี token ของพื้นที่ (เช่น geop50) ให้คำค้นที่เป็นชื่อเรียกอื่นของพื้นที่นั้นเจอด้วย
+	TitleWords string    `gorm:"not null;default:''" json:"-"`
+	Keywords   string    `gorm:"not null;default:''" json:"-"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะที่นั่ง held/reserved/confirmed นับเป็นที่นั่งที่ถูกใช้ (held เฉพาะที่ยังไม่หมดเวลา)
+const (
+	SeatHeld      = "held"      // อยู่ในตะกร้าหรือเสนอให้คนใน waitlist กันไว้จนถึง ExpiresAt
+	SeatReserved  = "reserved"  // checkout แล้วแต่ยังไม่จ่าย
+	SeatConfirmed = "confirmed" // จ่ายแล้ว
+	SeatReleased  = "released"  // ลบออกจากตะกร้า, ยกเลิกข้อเสนอ หรือยกเลิกการจอง
+	SeatExpired   = "expired"   // หมดเวลากันที่นั่ง
+)
+
+// SeatHold คือที่นั่งของแพ็คเกจหรืออีเวนต์ที่ถูกกันไว้ 1 ก้อน (1 รายการในตะกร้า, 1 ข้อเสนอจาก waitlist หรือ 1 รายการจอง)
+// ต้องมี PackageID หรือ EventID อย่างใดอย่างหนึ่ง
+type SeatHold struct {
+	gorm.Model
+
+	PackageID *uint    `gorm:"index" json:"package_id"`
+	Package   *Package `gorm:"foreignKey:PackageID" json:"package,omitempty"`
+	EventID   *uint    `gorm:"index" json:"event_id"`
+	Event     *Event   `gorm:"foreignKey:EventID" json:"event,omitempty"`
+
+	MemberID      uint  `gorm:"not null;index" json:"member_id"`
+	CartItemID    *uint `gorm:"index" json:"cart_item_id"`
+	ReservationID *uint `gorm:"index" json:"reservation_id"`
+
+	Seats     uint       `gorm:"not null" json:"seats"`
+	Status    string     `gorm:"not null;index" json:"status"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"` // เฉพาะ held
+}
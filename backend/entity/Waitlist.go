@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WaitlistWaiting   = "waiting"   // รอคิว
+	WaitlistOffered   = "offered"   // ได้รับข้อเสนอ กันที่นั่งไว้จนถึง OfferExpiresAt
+	WaitlistClaimed   = "claimed"   // รับข้อเสนอแล้ว ที่นั่งย้ายไปอยู่ในตะกร้า
+	WaitlistExpired   = "expired"   // ไม่รับข้อเสนอภายในเวลา
+	WaitlistCancelled = "cancelled" // สมาชิกออกจากคิวเอง
+)
+
+// WaitlistEntry คือคิวรอที่นั่งของแพ็คเกจหรืออีเวนต์ที่เต็ม เรียงตาม ID (มาก่อนได้ก่อน)
+type WaitlistEntry struct {
+	gorm.Model
+
+	PackageID *uint    `gorm:"index" json:"package_id"`
+	Package   *Package `gorm:"foreignKey:PackageID" json:"package,omitempty"`
+	EventID   *uint    `gorm:"index" json:"event_id"`
+	Event     *Event   `gorm:"foreignKey:EventID" json:"event,omitempty"`
+
+	MemberID uint    `gorm:"not null;index" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID" json:"member,omitempty"`
+
+	Adults   uint   `gorm:"not null" json:"adults"`
+	Children uint   `gorm:"not null;default:0" json:"children"`
+	Seats    uint   `gorm:"not null" json:"seats"`
+	Status   string `gorm:"not null;index" json:"status"`
+
+	OfferedAt      *time.Time `json:"offered_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	SeatHoldID     *uint      `json:"seat_hold_id"`
+	NotificationID *uint      `json:"notification_id"`
+}
@@ -7,10 +7,9 @@ import (
 type WishList struct {
 	gorm.Model
 
-	MemberID uint `gorm:"not null" json:"member_id"`
-	Member *Member `gorm:"foreignKey:MemberID"`
+	MemberID uint    `gorm:"not null" json:"member_id"`
+	Member   *Member `gorm:"foreignKey:MemberID"`
 
 	Item []Item `gorm:"foreignKey:WishListID"`
 	Cart []Cart `gorm:"foreignKey:WishListID"`
-
-}
\ No newline at end of file
+}
@@ -0,0 +1,63 @@
+// Package geo คำนวณระยะทางบนผิวโลก (haversine) และกรอบสี่เหลี่ยมรอบจุด สำหรับค้นหาสถานที่ใกล้เคียง
+// ใช้กรอบกรองใน SQL ก่อน (ใช้ index ได้) แล้วค่อยคำนวณระยะจริงใน Go
+package geo
+
+import "math"
+
+// EarthRadiusKm คือรัศมีเฉลี่ยของโลก (IUGG)
+const EarthRadiusKm = 6371.0088
+
+// Point คือพิกัดเป็นองศาทศนิยม (WGS84)
+type Point struct {
+	Lat float64 `json:"latitude"`
+	Lng float64 `json:"longitude"`
+}
+
+// Valid บอกว่าพิกัดอยู่ในช่วงที่ถูกต้อง
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180 &&
+		!math.IsNaN(p.Lat) && !math.IsNaN(p.Lng)
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// Distance คือระยะทางตามผิวโลกเป็นกิโลเมตร (สูตร haversine)
+func Distance(a, b Point) float64 {
+	dLat := radians(b.Lat - a.Lat)
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(a.Lat))*math.Cos(radians(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Box คือกรอบละติจูด/ลองจิจูด
+type Box struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// BoundingBox คือกรอบที่ครอบวงกลมรัศมี radiusKm รอบ center ทุกจุดในวงกลมอยู่ในกรอบ (แต่ไม่กลับกัน)
+// ถ้าวงกลมคร่อมขั้วโลกหรือเส้น 180 องศา ใช้ลองจิจูดทั้งหมด
+func BoundingBox(center Point, radiusKm float64) Box {
+	dLat := degrees(radiusKm / EarthRadiusKm)
+	b := Box{
+		MinLat: math.Max(-90, center.Lat-dLat),
+		MaxLat: math.Min(90, center.Lat+dLat),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+	if b.MinLat == -90 || b.MaxLat == 90 {
+		return b
+	}
+	dLng := degrees(math.Asin(math.Min(1, math.Sin(radiusKm/EarthRadiusKm)/math.Cos(radians(center.Lat)))))
+	if center.Lng-dLng >= -180 && center.Lng+dLng <= 180 {
+		b.MinLng, b.MaxLng = center.Lng-dLng, center.Lng+dLng
+	}
+	return b
+}
+
+// Contains บอกว่าจุดอยู่ในกรอบ
+func (b Box) Contains(p Point) bool {
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lng >= b.MinLng && p.Lng <= b.MaxLng
+}
@@ -0,0 +1,452 @@
+// Package jobs ทำงานเบื้องหลังที่ใช้เวลานานจากตาราง jobs: worker ดึงงานในคิวไปทำ รายงานความคืบหน้า
+// ยกเลิกได้ ลองใหม่เมื่อผิดพลาดตามจำนวนครั้งที่กำหนด และงานที่ค้างตอนปิดระบบจะถูกทำต่อเมื่อเปิดใหม่
+//
+// sqlite เปิดได้ connection เดียว ความคืบหน้าและการยกเลิกของงานที่กำลังทำจึงเก็บในหน่วยความจำ
+// (ตัวงานอาจถือ transaction อยู่) แล้วบันทึกลงตารางเมื่องานจบ
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownKind คืองานชนิดที่ไม่ได้ Register ไว้
+	ErrUnknownKind = errors.New("unknown job kind")
+	// ErrFinished คือยกเลิก/ลองใหม่ไม่ได้เพราะงานอยู่ในสถานะที่ไม่รองรับ
+	ErrFinished = errors.New("job is not in a state that allows this")
+)
+
+// permanentError คือข้อผิดพลาดที่ลองใหม่ก็ไม่หาย (เช่น ข้อมูลไม่ถูกต้อง)
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent ห่อ err ให้งานล้มเหลวทันทีโดยไม่ลองใหม่
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// publicError คือข้อผิดพลาดที่มีข้อความให้ผู้ใช้เห็นได้ (เก็บใน Job.Error) สาเหตุจริงใน err log ไว้ที่ server เท่านั้น
+type publicError struct {
+	msg string
+	err error
+}
+
+func (e publicError) Error() string {
+	if e.err == nil {
+		return e.msg
+	}
+	return e.msg + ": " + e.err.Error()
+}
+func (e publicError) Unwrap() error { return e.err }
+
+// Public ห่อ err ด้วยข้อความที่แสดงใน Job.Error ได้ งานยังลองใหม่ตามปกติ (ถ้า err ไม่ได้เป็น Permanent)
+func Public(msg string, err error) error {
+	return publicError{msg: msg, err: err}
+}
+
+// Fail คือข้อผิดพลาดจากข้อมูลของงาน (เช่น payload ไม่ถูกต้อง) ไม่ลองใหม่ และข้อความแสดงใน Job.Error ตรง ๆ
+// ข้อความต้องไม่มีรายละเอียดภายใน เช่น SQL หรือข้อความจากระบบภายนอก
+func Fail(format string, args ...any) error {
+	return Permanent(publicError{msg: fmt.Sprintf(format, args...)})
+}
+
+// errorMessage คือข้อความที่บันทึกใน Job.Error: ข้อความจาก Public/Fail ถ้ามี ที่เหลือเป็นข้อความกลาง ๆ
+func errorMessage(err error) string {
+	var pe publicError
+	if errors.As(err, &pe) {
+		return pe.msg
+	}
+	return "Internal error"
+}
+
+const (
+	maxBackoff = 5 * time.Minute
+	baseDelay  = 5 * time.Second
+)
+
+// Handler ทำงานหนึ่งงาน ค่าที่คืนถูกเก็บเป็น JSON ใน Job.Result ถ้า ctx ถูกยกเลิกควรหยุดและคืน ctx.Err()
+type Handler func(ctx context.Context, t *Task) (any, error)
+
+type kind struct {
+	handler     Handler
+	maxAttempts int
+}
+
+var (
+	mu       sync.Mutex
+	kinds    = map[string]kind{}
+	running  = map[uint]*Task{}
+	wake     = make(chan struct{}, 1)
+	startRun sync.Once
+)
+
+// Register ผูกชนิดงานกับตัวทำงาน maxAttempts คือจำนวนครั้งที่ลองทั้งหมด (อย่างน้อย 1)
+func Register(name string, maxAttempts int, h Handler) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	mu.Lock()
+	kinds[name] = kind{handler: h, maxAttempts: maxAttempts}
+	mu.Unlock()
+}
+
+// Task คืองานที่ worker กำลังทำ ส่งให้ Handler ใช้อ่าน payload และรายงานความคืบหน้า
+type Task struct {
+	ID      uint
+	Kind    string
+	Attempt int
+
+	payload  string
+	cancel   context.CancelFunc
+	progress int
+	message  string
+}
+
+// Decode อ่าน payload ที่ส่งมาตอน Enqueue
+func (t *Task) Decode(v any) error {
+	if t.payload == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(t.payload), v)
+}
+
+// Progress รายงานว่าทำไป done จาก total แล้ว คืน ctx.Err() ถ้างานถูกยกเลิกเพื่อให้ตัวงานหยุด
+func (t *Task) Progress(ctx context.Context, done, total int, message string) error {
+	percent := 0
+	if total > 0 {
+		percent = done * 100 / total
+	}
+	if percent > 99 {
+		percent = 99 // 100 เมื่องานจบจริงเท่านั้น
+	}
+	mu.Lock()
+	if percent > t.progress {
+		t.progress = percent
+	}
+	if message != "" {
+		t.message = message
+	}
+	mu.Unlock()
+	return ctx.Err()
+}
+
+// Live คืนความคืบหน้าล่าสุดของงานที่กำลังทำในโปรเซสนี้
+func Live(id uint) (progress int, message string, ok bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	t, ok := running[id]
+	if !ok {
+		return 0, "", false
+	}
+	return t.progress, t.message, true
+}
+
+// Enqueue เพิ่มงานเข้าคิว payload ถูกเก็บเป็น JSON
+func Enqueue(db *gorm.DB, name string, payload any) (entity.Job, error) {
+	return EnqueueAt(db, name, payload, time.Now())
+}
+
+// EnqueueAt เพิ่มงานที่จะเริ่มทำไม่ก่อนเวลา at
+func EnqueueAt(db *gorm.DB, name string, payload any, at time.Time) (entity.Job, error) {
+	mu.Lock()
+	k, ok := kinds[name]
+	mu.Unlock()
+	if !ok {
+		return entity.Job{}, fmt.Errorf("%w: %s", ErrUnknownKind, name)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return entity.Job{}, err
+	}
+	job := entity.Job{
+		Kind:        name,
+		Status:      entity.JobQueued,
+		Payload:     string(raw),
+		MaxAttempts: k.maxAttempts,
+		RunAfter:    at,
+		Message:     "Waiting in queue",
+	}
+	// db ที่มาจาก WithContext(c.Request.Context()) พา trace ของ request ไปให้ตัวงาน
+	if ctx := db.Statement.Context; ctx != nil {
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+		job.TraceParent = carrier.Get("traceparent")
+	}
+	if err := db.Create(&job).Error; err != nil {
+		return entity.Job{}, err
+	}
+	notify()
+	return job, nil
+}
+
+// Cancel ยกเลิกงาน งานในคิวจบทันที งานที่กำลังทำจะถูกส่งสัญญาณให้หยุด (สถานะเปลี่ยนเมื่อตัวงานหยุดแล้ว)
+func Cancel(db *gorm.DB, id uint) (entity.Job, error) {
+	var job entity.Job
+	if err := db.First(&job, id).Error; err != nil {
+		return job, err
+	}
+	switch job.Status {
+	case entity.JobQueued:
+		now := time.Now()
+		res := db.Model(&entity.Job{}).Where("id = ? AND status = ?", id, entity.JobQueued).
+			Updates(map[string]any{"status": entity.JobCancelled, "cancel_requested": true, "finished_at": now, "message": "Cancelled"})
+		if res.Error != nil {
+			return job, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return Cancel(db, id) // worker เพิ่งหยิบไป ให้ยกเลิกแบบงานที่กำลังทำ
+		}
+	case entity.JobRunning:
+		if err := db.Model(&entity.Job{}).Where("id = ?", id).Update("cancel_requested", true).Error; err != nil {
+			return job, err
+		}
+		mu.Lock()
+		if t, ok := running[id]; ok {
+			t.cancel()
+		}
+		mu.Unlock()
+	default:
+		return job, ErrFinished
+	}
+	err := db.First(&job, id).Error
+	return job, err
+}
+
+// Retry นำงานที่ล้มเหลว/ถูกยกเลิกกลับเข้าคิว นับจำนวนครั้งใหม่
+func Retry(db *gorm.DB, id uint) (entity.Job, error) {
+	var job entity.Job
+	if err := db.First(&job, id).Error; err != nil {
+		return job, err
+	}
+	if job.Status != entity.JobFailed && job.Status != entity.JobCancelled {
+		return job, ErrFinished
+	}
+	if err := db.Model(&job).Updates(map[string]any{
+		"status": entity.JobQueued, "attempts": 0, "progress": 0, "error": "", "result": "",
+		"message": "Waiting in queue", "cancel_requested": false, "run_after": time.Now(),
+		"started_at": nil, "finished_at": nil,
+	}).Error; err != nil {
+		return job, err
+	}
+	notify()
+	err := db.First(&job, id).Error
+	return job, err
+}
+
+// Depth คือจำนวนงานที่ยังไม่จบ (queued / running) คืนครบทั้งสองสถานะแม้เป็น 0
+func Depth(db *gorm.DB) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		N      int64
+	}
+	err := db.Model(&entity.Job{}).Select("status, COUNT(*) AS n").
+		Where("status IN ?", []string{entity.JobQueued, entity.JobRunning}).
+		Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	depth := map[string]int64{entity.JobQueued: 0, entity.JobRunning: 0}
+	for _, r := range rows {
+		depth[r.Status] = r.N
+	}
+	return depth, nil
+}
+
+func notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start นำงานที่ค้าง running (ระบบปิดกลางคัน) กลับเข้าคิว แล้วเปิด worker จนกว่า ctx จะถูกยกเลิก
+// เรียกได้ครั้งเดียว
+func Start(ctx context.Context, db *gorm.DB, workers int, poll time.Duration) {
+	startRun.Do(func() {
+		res := db.Model(&entity.Job{}).Where("status = ?", entity.JobRunning).
+			Updates(map[string]any{"status": entity.JobQueued, "message": "Resumed after restart", "run_after": time.Now()})
+		if res.Error != nil {
+			slog.Error("jobs: resume interrupted jobs", "error", res.Error)
+		} else if res.RowsAffected > 0 {
+			slog.Info("jobs: resumed interrupted jobs", "count", res.RowsAffected)
+		}
+		for i := 0; i < workers; i++ {
+			go worker(ctx, db, poll)
+		}
+	})
+}
+
+func worker(ctx context.Context, db *gorm.DB, poll time.Duration) {
+	for ctx.Err() == nil {
+		job, ok, err := claim(db)
+		if err != nil {
+			slog.Error("jobs: claim", "error", err)
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-wake:
+			case <-time.After(poll):
+			}
+			continue
+		}
+		run(ctx, db, job)
+		notify() // อาจมีงานอื่นรออยู่
+	}
+}
+
+// claim หยิบงานที่ถึงเวลาทำแล้วเก่าที่สุดมาเปลี่ยนเป็น running
+func claim(db *gorm.DB) (entity.Job, bool, error) {
+	var job entity.Job
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Find แทน First เพื่อไม่ให้ gorm log "record not found" ทุกรอบที่คิวว่าง
+		found := tx.Where("status = ? AND run_after <= ?", entity.JobQueued, now).
+			Order("run_after, id").Limit(1).Find(&job)
+		if found.Error != nil {
+			return found.Error
+		}
+		if found.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		res := tx.Model(&entity.Job{}).Where("id = ? AND status = ?", job.ID, entity.JobQueued).
+			Updates(map[string]any{"status": entity.JobRunning, "attempts": gorm.Expr("attempts + 1"), "started_at": now, "message": "Running"})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		job.Status = entity.JobRunning
+		job.Attempts++
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, false, nil
+	}
+	return job, err == nil, err
+}
+
+func run(ctx context.Context, db *gorm.DB, job entity.Job) {
+	mu.Lock()
+	k, ok := kinds[job.Kind]
+	mu.Unlock()
+	if !ok {
+		slog.Error("jobs: unknown kind", "job_id", job.ID, "kind", job.Kind)
+		finish(db, job, map[string]any{"status": entity.JobFailed, "error": ErrUnknownKind.Error()})
+		return
+	}
+
+	// span ของงานต่อจาก request ที่สั่ง (ถ้ามี) ทั้ง query และ HTTP ขาออกในตัวงานจะอยู่ใต้ span นี้
+	sctx := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{"traceparent": job.TraceParent})
+	sctx, span := otel.Tracer("github.com/kookkikiv/sa_project/backend/jobs").Start(sctx, "job "+job.Kind,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.Int("job.id", int(job.ID)), attribute.Int("job.attempt", job.Attempts)))
+	defer span.End()
+
+	// log ในตัวงาน (logging.FromContext) มี job_id/kind ติดไปด้วย
+	jctx, cancel := context.WithCancel(logging.With(sctx, "job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts))
+	defer cancel()
+	t := &Task{ID: job.ID, Kind: job.Kind, Attempt: job.Attempts, payload: job.Payload, cancel: cancel}
+	mu.Lock()
+	running[job.ID] = t
+	mu.Unlock()
+	if job.CancelRequested {
+		cancel() // ขอยกเลิกไว้ตั้งแต่ก่อนระบบปิด
+	}
+
+	result, err := call(jctx, k.handler, t)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	log := logging.FromContext(jctx)
+
+	mu.Lock()
+	progress, message := t.progress, t.message
+	mu.Unlock()
+	// เอาออกจาก running หลังบันทึกสถานะสุดท้ายแล้ว ไม่งั้น GET ระหว่างนั้นจะเห็นค่าเก่าในตาราง
+	defer func() {
+		mu.Lock()
+		delete(running, job.ID)
+		mu.Unlock()
+	}()
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// ระบบกำลังปิด ให้กลับเข้าคิวโดยไม่นับครั้งนี้
+		finish(db, job, map[string]any{"status": entity.JobQueued, "attempts": job.Attempts - 1,
+			"progress": 0, "message": "Interrupted by shutdown", "started_at": nil})
+	case err != nil && jctx.Err() != nil:
+		finish(db, job, map[string]any{"status": entity.JobCancelled, "progress": progress,
+			"message": "Cancelled", "finished_at": time.Now()})
+	case err != nil && job.Attempts < k.maxAttempts && !errors.As(err, new(permanentError)):
+		delay := backoff(job.Attempts)
+		log.WarnContext(jctx, "job attempt failed, retrying", "error", err, "retry_in", delay)
+		finish(db, job, map[string]any{"status": entity.JobQueued, "error": errorMessage(err), "progress": 0,
+			"message": fmt.Sprintf("Attempt %d failed, retrying", job.Attempts), "run_after": time.Now().Add(delay)})
+	case err != nil:
+		log.ErrorContext(jctx, "job failed", "error", err)
+		finish(db, job, map[string]any{"status": entity.JobFailed, "error": errorMessage(err), "progress": progress,
+			"message": "Failed", "finished_at": time.Now()})
+	default:
+		raw, jerr := json.Marshal(result)
+		if jerr != nil {
+			log.ErrorContext(jctx, "job result encoding failed", "error", jerr)
+			finish(db, job, map[string]any{"status": entity.JobFailed, "error": "Failed to encode result",
+				"finished_at": time.Now()})
+			return
+		}
+		if message == "" || message == "Running" {
+			message = "Done"
+		}
+		finish(db, job, map[string]any{"status": entity.JobSucceeded, "result": string(raw), "error": "",
+			"progress": 100, "message": message, "finished_at": time.Now()})
+	}
+}
+
+// backoff คือเวลารอก่อนลองใหม่หลังครั้งที่ attempt ล้มเหลว: เพิ่มเท่าตัวจาก baseDelay ไม่เกิน maxBackoff
+func backoff(attempt int) time.Duration {
+	delay := baseDelay << (attempt - 1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// call เรียกตัวงาน และแปลง panic เป็น error เพื่อไม่ให้ worker ตาย
+func call(ctx context.Context, h Handler, t *Task) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, t)
+}
+
+func finish(db *gorm.DB, job entity.Job, fields map[string]any) {
+	if err := db.Model(&entity.Job{}).Where("id = ?", job.ID).Updates(fields).Error; err != nil {
+		slog.Error("jobs: update job", "job_id", job.ID, "error", err)
+	}
+}
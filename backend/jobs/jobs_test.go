@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/entity"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "jobs.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Job{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func reload(t *testing.T, db *gorm.DB, id uint) entity.Job {
+	t.Helper()
+	var job entity.Job
+	if err := db.First(&job, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// runNext หยิบงานที่ถึงเวลาแล้ว (เลื่อน run_after ของงานที่รอลองใหม่ให้ถึงก่อน) ทำ 1 ครั้ง แล้วคืนสถานะในตาราง
+func runNext(t *testing.T, ctx context.Context, db *gorm.DB, id uint) entity.Job {
+	t.Helper()
+	db.Model(&entity.Job{}).Where("id = ? AND status = ?", id, entity.JobQueued).Update("run_after", time.Now().Add(-time.Second))
+	job, ok, err := claim(db)
+	if err != nil || !ok {
+		t.Fatalf("claim = %v, %v", ok, err)
+	}
+	run(ctx, db, job)
+	return reload(t, db, id)
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{6, 160 * time.Second},
+		{7, maxBackoff},
+		{64, maxBackoff},
+		{100, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRunStates(t *testing.T) {
+	internal := errors.New("no such table: secret_things")
+	tests := []struct {
+		name        string
+		maxAttempts int
+		handler     Handler
+		// สถานะหลังทำแต่ละครั้ง
+		want      []string
+		wantError string
+	}{
+		{"succeeds", 3, func(ctx context.Context, t *Task) (any, error) { return map[string]int{"n": 1}, nil },
+			[]string{entity.JobSucceeded}, ""},
+		{"retries then fails", 3, func(ctx context.Context, t *Task) (any, error) { return nil, internal },
+			[]string{entity.JobQueued, entity.JobQueued, entity.JobFailed}, "Internal error"},
+		{"retries then succeeds", 3, func(ctx context.Context, t *Task) (any, error) {
+			if t.Attempt < 2 {
+				return nil, Public("Remote source unavailable", internal)
+			}
+			return "ok", nil
+		}, []string{entity.JobQueued, entity.JobSucceeded}, ""},
+		{"public message kept", 2, func(ctx context.Context, t *Task) (any, error) {
+			return nil, Public("Remote source unavailable", internal)
+		}, []string{entity.JobQueued, entity.JobFailed}, "Remote source unavailable"},
+		{"permanent fails at once", 3, func(ctx context.Context, t *Task) (any, error) { return nil, Permanent(internal) },
+			[]string{entity.JobFailed}, "Internal error"},
+		{"fail message shown", 3, func(ctx context.Context, t *Task) (any, error) { return nil, Fail("from must be %s", "YYYY-MM-DD") },
+			[]string{entity.JobFailed}, "from must be YYYY-MM-DD"},
+		{"panic becomes error", 1, func(ctx context.Context, t *Task) (any, error) { panic("boom") },
+			[]string{entity.JobFailed}, "Internal error"},
+		{"result that cannot be encoded", 1, func(ctx context.Context, t *Task) (any, error) { return make(chan int), nil },
+			[]string{entity.JobFailed}, "Failed to encode result"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			kind := "test_" + t.Name()
+			Register(kind, tt.maxAttempts, tt.handler)
+			job, err := Enqueue(db, kind, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got entity.Job
+			for i, want := range tt.want {
+				before := time.Now()
+				got = runNext(t, context.Background(), db, job.ID)
+				if got.Status != want || got.Attempts != i+1 {
+					t.Fatalf("run %d: status %s attempts %d, want %s attempts %d", i+1, got.Status, got.Attempts, want, i+1)
+				}
+				if want == entity.JobQueued {
+					if wait := got.RunAfter.Sub(before); wait < backoff(i+1)-time.Second || wait > backoff(i+1)+time.Second {
+						t.Errorf("run %d: retry in %v, want about %v", i+1, wait, backoff(i+1))
+					}
+				}
+			}
+			if got.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", got.Error, tt.wantError)
+			}
+			if got.Status == entity.JobSucceeded && (got.Progress != 100 || got.Result == "" || got.FinishedAt == nil) {
+				t.Errorf("succeeded job = %+v", got)
+			}
+			if got.Status == entity.JobFailed && got.FinishedAt == nil {
+				t.Error("failed job has no finished_at")
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	db := newDB(t)
+	started := make(chan struct{})
+	Register("test_cancel", 3, func(ctx context.Context, t *Task) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// งานในคิวจบทันที
+	queued, _ := Enqueue(db, "test_cancel", nil)
+	got, err := Cancel(db, queued.ID)
+	if err != nil || got.Status != entity.JobCancelled || !got.CancelRequested {
+		t.Fatalf("cancel queued = %s, %v", got.Status, err)
+	}
+	if _, err := Cancel(db, queued.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("cancel twice = %v, want ErrFinished", err)
+	}
+
+	// งานที่กำลังทำได้สัญญาณให้หยุด สถานะเปลี่ยนเมื่อตัวงานคืนค่า ไม่ถูกนับเป็นความผิดพลาดที่ต้องลองใหม่
+	job, _ := Enqueue(db, "test_cancel", nil)
+	claimed, ok, err := claim(db)
+	if err != nil || !ok || claimed.ID != job.ID {
+		t.Fatalf("claim = %v, %v", ok, err)
+	}
+	done := make(chan struct{})
+	go func() {
+		run(context.Background(), db, claimed)
+		close(done)
+	}()
+	<-started
+	if got, err := Cancel(db, job.ID); err != nil || got.Status != entity.JobRunning || !got.CancelRequested {
+		t.Fatalf("cancel running = %s, %v", got.Status, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("running job did not stop after cancel")
+	}
+	if got := reload(t, db, job.ID); got.Status != entity.JobCancelled || got.Attempts != 1 || got.Error != "" {
+		t.Errorf("after cancel = %s attempts %d error %q", got.Status, got.Attempts, got.Error)
+	}
+	if _, _, ok := Live(job.ID); ok {
+		t.Error("cancelled job still reported as running")
+	}
+
+	// ลองใหม่ได้หลังยกเลิก นับจำนวนครั้งใหม่
+	got, err = Retry(db, job.ID)
+	if err != nil || got.Status != entity.JobQueued || got.Attempts != 0 || got.CancelRequested {
+		t.Errorf("retry = %+v, %v", got, err)
+	}
+	if _, err := Retry(db, job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("retry queued job = %v, want ErrFinished", err)
+	}
+}
+
+func TestShutdownRequeues(t *testing.T) {
+	db := newDB(t)
+	ctx, stop := context.WithCancel(context.Background())
+	Register("test_shutdown", 3, func(jctx context.Context, t *Task) (any, error) {
+		stop() // ระบบปิดระหว่างทำงาน
+		<-jctx.Done()
+		return nil, jctx.Err()
+	})
+	job, _ := Enqueue(db, "test_shutdown", nil)
+	got := runNext(t, ctx, db, job.ID)
+	if got.Status != entity.JobQueued || got.Attempts != 0 || got.StartedAt != nil {
+		t.Errorf("after shutdown = %s attempts %d started %v, want queued without counting the attempt",
+			got.Status, got.Attempts, got.StartedAt)
+	}
+}
+
+func TestEnqueueUnknownKind(t *testing.T) {
+	if _, err := Enqueue(newDB(t), "test_not_registered", nil); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("err = %v, want ErrUnknownKind", err)
+	}
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger ส่ง log ของ GORM เข้า slog ผ่าน logger ใน context ของ query
+// (query ที่เรียกด้วย db.WithContext(c.Request.Context()) จะมี request_id ติดมา)
+type GormLogger struct {
+	Level         gormlogger.LogLevel
+	SlowThreshold time.Duration // query ที่ช้ากว่านี้ log เป็น warn (0 = ไม่ตรวจ)
+}
+
+// ParseGormLevel แปลง silent | error | warn | info เป็นระดับของ GORM ค่าอื่นคืน def
+// (info = log ทุก query)
+func ParseGormLevel(s string, def gormlogger.LogLevel) gormlogger.LogLevel {
+	switch s {
+	case "silent":
+		return gormlogger.Silent
+	case "error":
+		return gormlogger.Error
+	case "warn":
+		return gormlogger.Warn
+	case "info":
+		return gormlogger.Info
+	}
+	return def
+}
+
+func (g GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	g.Level = level
+	return g
+}
+
+func (g GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if g.Level >= gormlogger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+	}
+}
+
+func (g GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if g.Level >= gormlogger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+	}
+}
+
+func (g GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if g.Level >= gormlogger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+	}
+}
+
+// Trace ถูกเรียกหลังทุก query: error → error, ช้า → warn, ที่เหลือ log ด้วยเมื่อระดับเป็น info
+func (g GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if g.Level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	slow := g.SlowThreshold > 0 && elapsed > g.SlowThreshold
+	var level slog.Level
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && g.Level >= gormlogger.Error:
+		level = slog.LevelError
+	case slow && g.Level >= gormlogger.Warn:
+		level = slog.LevelWarn
+	case g.Level >= gormlogger.Info:
+		level = slog.LevelInfo
+	default:
+		return
+	}
+	l := FromContext(ctx)
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	attrs := []any{"component", "gorm", "sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+	msg := "query"
+	if slow {
+		msg = "slow query"
+	}
+	l.Log(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,65 @@
+// Package logging ตั้งค่า log/slog ของทั้งระบบ (JSON ลง stdout) และเก็บ logger ประจำ request/งานไว้ใน context
+// ทุกบรรทัดที่ log ผ่าน FromContext จึงมี request_id หรือ job_id ติดไปด้วย ค้นย้อนหลังใน production ได้
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ParseLevel แปลง debug | info | warn | error เป็น slog.Level ค่าอื่นคืน def
+func ParseLevel(s string, def slog.Level) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return def
+}
+
+// Setup ตั้ง slog.Default ตาม LOG_LEVEL (ค่าเริ่มต้น info) และ LOG_FORMAT (json | text, ค่าเริ่มต้น json)
+// log.Printf ที่ยังเหลือในไลบรารีอื่นจะออกผ่าน handler เดียวกัน
+func Setup() *slog.Logger {
+	l := New(os.Stdout, os.Getenv("LOG_FORMAT"), ParseLevel(os.Getenv("LOG_LEVEL"), slog.LevelInfo))
+	slog.SetDefault(l)
+	return l
+}
+
+// New สร้าง logger ที่เขียนลง w (format "text" = อ่านง่ายตอนพัฒนา, อื่น ๆ = JSON)
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if strings.EqualFold(format, "text") {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+type ctxKey struct{}
+
+// WithLogger ผูก l ไว้กับ ctx
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext คือ logger ที่ผูกไว้กับ ctx (มี request_id / job_id) ถ้าไม่มีใช้ slog.Default
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return slog.Default()
+}
+
+// With เพิ่ม attribute ให้ logger ใน ctx (เช่น user หลังยืนยันตัวตน) แล้วคืน ctx ใหม่
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
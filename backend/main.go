@@ -1,35 +1,109 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime/debug"
+
 	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
 	"github.com/kookkikiv/sa_project/backend/config"
 	"github.com/kookkikiv/sa_project/backend/controller"
+	"github.com/kookkikiv/sa_project/backend/jobs"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"github.com/kookkikiv/sa_project/backend/metrics"
+	"github.com/kookkikiv/sa_project/backend/middlewares"
+	"github.com/kookkikiv/sa_project/backend/tracing"
 )
 
 const PORT = "8000"
 
 func main() {
+	// log แบบ JSON ลง stdout (LOG_LEVEL / LOG_FORMAT)
+	logging.Setup()
+	shutdownTracing, err := tracing.Setup(context.Background(), config.TraceExporter())
+	if err != nil {
+		slog.Error("tracing disabled", "error", err)
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+	gin.DebugPrintRouteFunc = func(method, path, handler string, _ int) {
+		slog.Debug("route", "method", method, "path", path, "handler", handler)
+	}
+
 	// DB
 	config.ConnectionDB()
 	config.SetupDatabase()
+	if err := tracing.InstrumentDB(config.DB()); err != nil {
+		slog.Error("tracing: instrument database", "error", err)
+	}
+	if err := metrics.InstrumentDB(config.DB()); err != nil {
+		slog.Error("metrics: instrument database", "error", err)
+	}
+	if err := metrics.RegisterJobQueue(func() (map[string]int64, error) { return jobs.Depth(config.DB()) }); err != nil {
+		slog.Error("metrics: job queue depth", "error", err)
+	}
+	controller.EnsureSearchIndex()
+
+	// ปล่อยที่นั่งที่ตะกร้า/ข้อเสนอ waitlist กันไว้เกินเวลา แล้วเสนอให้คิวถัดไป
+	go controller.RunSeatHoldReleaser(context.Background(), config.SeatHoldSweepInterval())
+
+	// งานเบื้องหลัง (นำเข้าข้อมูล/รายงาน/ลบจำนวนมาก) งานที่ค้างตอนปิดระบบจะถูกทำต่อ
+	controller.RegisterJobs()
+	jobs.Start(context.Background(), config.DB(), config.JobWorkers(), config.JobPollInterval())
+
+	r := setupRouter()
+
+	// run
+	r.Run(":" + PORT) // แนะนำแบบนี้
+}
+
+// legacySuccessors คือ route เดิมที่ชื่อใน /api/v1 ไม่ตรงกับ /api/v1 + path
+var legacySuccessors = map[string]string{
+	"/province":    "/api/v1/location/provinces",
+	"/district":    "/api/v1/location/districts",
+	"/subdistrict": "/api/v1/location/subdistricts",
+	"/upload":      "/api/v1/pictures/upload",
+	"/signup":      "/api/v1/admin",
+}
 
-	r := gin.Default()
+// setupRouter ผูก middleware และ route ทั้งหมด (แยกจาก main เพื่อให้เทสต์ตรวจตาราง route ได้)
+func setupRouter() *gin.Engine {
+	r := gin.New()
+	// RequestID/Tracing ต้องมาก่อน เพื่อให้ log ของ request และของ panic มี request_id/trace_id
+	r.Use(middlewares.RequestID(), middlewares.Tracing(), middlewares.RequestLogger(), middlewares.Metrics())
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, rec any) {
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).ErrorContext(ctx, "panic recovered", "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+		apierr.Respond(c, apierr.Internal(fmt.Errorf("panic: %v", rec)))
+	}))
 	r.Use(CORSMiddleware())
+	r.NoRoute(func(c *gin.Context) {
+		apierr.Respond(c, apierr.NotFound("Route not found"))
+	})
 
 	// ✅ เสิร์ฟไฟล์อัปโหลด (ประกาศครั้งเดียวพอ และวางก่อน api group)
 	r.Static("/uploads", "./uploads")
+	controller.DocumentPath("GET", "/uploads/*filepath", "Uploaded file", "picture")
+	controller.DocumentPath("HEAD", "/uploads/*filepath", "Uploaded file (headers only)", "picture")
 
-	// ✅ รองรับ FE เดิมที่เรียก POST /upload (legacy)
-	r.POST("/upload", controller.UploadPictures)
+	// health check: livez = โปรเซสยังอยู่, readyz = ฐานข้อมูลและ uploads พร้อม (/health เดิม = readyz)
+	r.GET("/livez", controller.Livez)
+	r.GET("/readyz", controller.Readyz)
+	r.GET("/health", controller.Readyz)
 
-	// health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Prometheus
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	controller.DocumentPath("GET", "/metrics", "Prometheus metrics", "system")
+
+	// เอกสาร API (สร้างจาก route ที่ผูกไว้ด้านล่าง ดู controller/openapi.go)
+	r.GET("/openapi.json", controller.OpenAPISpec)
+	r.GET("/docs", controller.APIDocs)
 
 	// auth (public)
 	r.POST("/signin", controller.SignIn)
-	r.POST("/signup", controller.CreateAdmin)
 
 	// ---------- API v1 ----------
 	api := r.Group("/api/v1")
@@ -43,6 +117,11 @@ func main() {
 			loc.POST("/districts", controller.CreateDistrict)
 			loc.GET("/subdistricts", controller.FindSubdistricts)
 			loc.POST("/subdistricts", controller.CreateSubdistrict)
+			loc.GET("/places", controller.FindLocations)
+			loc.POST("/places", controller.CreateLocation)
+			loc.PUT("/places/:id/coordinates", controller.UpdateLocationCoordinates)
+			loc.GET("/lookup", controller.LookupPostalCode)
+			loc.POST("/address/parse", controller.ParseAddress)
 		}
 
 		// Admin
@@ -63,6 +142,7 @@ func main() {
 			acc.POST("", controller.CreateAccommodation)
 			acc.PUT("/:id", controller.UpdateAccommodationById)
 			acc.DELETE("/:id", controller.DeleteAccommodationById)
+			acc.PUT("/:id/coordinates", controller.UpdateAccommodationCoordinates)
 		}
 
 		// Package
@@ -75,16 +155,114 @@ func main() {
 			pkg.POST("", controller.CreatePackage)
 			pkg.PUT("/:id", controller.UpdatePackageById)
 			pkg.DELETE("/:id", controller.DeletePackageById)
+			pkg.GET("/:id/itinerary", controller.FindPackageItinerary)
+			pkg.GET("/:id/seats", controller.FindPackageSeats)
+			pkg.PUT("/:id/itinerary", controller.UpdatePackageItinerary)
 		}
 
 		// Guide
 		g := api.Group("/guide")
 		{
 			g.GET("", controller.FindGuide)
+			g.GET("/available", controller.SuggestAvailableGuides)
 			g.GET("/:id", controller.FindGuideById)
 			g.POST("", controller.CreateGuide)
 			g.PUT("/:id", controller.UpdateGuideById)
 			g.DELETE("/:id", controller.DeleteGuideById)
+			g.GET("/:id/availability", controller.FindGuideAvailability)
+			g.POST("/:id/availability", controller.CreateGuideAvailability)
+			g.DELETE("/:id/availability/:availability_id", controller.DeleteGuideAvailability)
+			g.GET("/:id/statement", controller.GetGuideStatement)
+		}
+
+		// Guide payouts (ledger / commission / payout batch)
+		ledger := api.Group("/ledger")
+		{
+			ledger.GET("", controller.FindLedgerEntries)
+			ledger.GET("/reconcile", controller.ReconcilePayments)
+			ledger.POST("/reconcile", controller.EnqueueReconcilePayments)
+			ledger.POST("/reservation/:id", controller.PostReservationToLedger)
+		}
+		commission := api.Group("/commission-rule")
+		{
+			commission.GET("", controller.FindCommissionRules)
+			commission.PUT("/:guide_type_id", controller.UpsertCommissionRule)
+		}
+		payout := api.Group("/payout-batch")
+		{
+			payout.GET("", controller.FindPayoutBatches)
+			payout.GET("/:id", controller.FindPayoutBatchById)
+			payout.POST("", controller.CreatePayoutBatch)
+			payout.PUT("/:id/status", controller.UpdatePayoutBatchStatus)
+		}
+
+		// Pricing (quote ใช้ฟังก์ชันเดียวกับ cart / booking / checkout)
+		api.POST("/quote", controller.QuotePrice)
+		pricingRule := api.Group("/pricing-rule")
+		{
+			pricingRule.GET("", controller.FindPricingRules)
+			pricingRule.POST("", controller.CreatePricingRule)
+			pricingRule.PUT("/:id", controller.UpdatePricingRule)
+			pricingRule.DELETE("/:id", controller.DeletePricingRule)
+		}
+		cart := api.Group("/cart")
+		{
+			cart.GET("", controller.FindCart)
+			cart.POST("/items", controller.AddCartItem)
+			cart.DELETE("/items/:id", controller.DeleteCartItem)
+			cart.POST("/checkout", controller.CheckoutCart)
+		}
+		promo := api.Group("/promotion")
+		{
+			promo.GET("", controller.FindPromotions)
+			promo.GET("/:id", controller.FindPromotionById)
+			promo.GET("/:id/redemptions", controller.FindPromotionRedemptions)
+			promo.POST("", controller.CreatePromotion)
+			promo.POST("/check", controller.CheckPromotion)
+			promo.PUT("/:id", controller.UpdatePromotionById)
+			promo.DELETE("/:id", controller.DeletePromotionById)
+		}
+		waitlist := api.Group("/waitlist")
+		{
+			waitlist.GET("", controller.FindWaitlist)
+			waitlist.POST("", controller.JoinWaitlist)
+			waitlist.POST("/:id/claim", controller.ClaimWaitlistOffer)
+			waitlist.DELETE("/:id", controller.LeaveWaitlist)
+		}
+		api.POST("/reservation/:id/cancel", controller.CancelReservation)
+		booking := api.Group("/booking")
+		{
+			booking.GET("", controller.FindBookings)
+			booking.GET("/:id", controller.FindBookingById)
+			booking.POST("", controller.CreateBooking)
+		}
+
+		// Search (FTS5 ถ้า build ด้วย -tags sqlite_fts5 ไม่งั้นใช้ LIKE)
+		api.GET("/search", controller.Search)
+		api.POST("/search/reindex", controller.ReindexSearch)
+		api.GET("/search/alias", controller.FindPlaceAliases)
+		api.POST("/search/alias", controller.CreatePlaceAlias)
+		api.DELETE("/search/alias/:id", controller.DeletePlaceAlias)
+
+		// Nearby (ค้นตามระยะทางจากพิกัดหรือจากอีเวนต์/ที่พัก/สถานที่)
+		api.GET("/nearby", controller.FindNearby)
+
+		// Currency (แสดงราคาหลายสกุลผ่าน ?currency=)
+		currency := api.Group("/currency")
+		{
+			currency.GET("", controller.FindCurrencies)
+			currency.PUT("/:code", controller.UpsertCurrency)
+			currency.GET("/:code/rates", controller.FindExchangeRates)
+			currency.POST("/rates/import", controller.ImportExchangeRates)
+		}
+
+		// Event
+		event := api.Group("/event")
+		{
+			event.GET("", controller.FindEvent)
+			event.GET("/:id", controller.FindEventById)
+			event.GET("/:id/seats", controller.FindEventSeats)
+			event.PUT("/:id/coordinates", controller.UpdateEventCoordinates)
 		}
 
 		// Room
@@ -107,12 +285,24 @@ func main() {
 			fac.DELETE("/:id", controller.DeleteFacilityById)
 		}
 
+		// Jobs (งานเบื้องหลัง: POST ที่ตอบ 202 ให้ poll ที่นี่)
+		api.GET("/jobs", controller.FindJobs)
+		api.GET("/jobs/:id", controller.GetJob)
+		api.POST("/jobs/:id/cancel", controller.CancelJob)
+		api.POST("/jobs/:id/retry", controller.RetryJob)
+
+		// ยอดเรียก route เดิม (ดูก่อนปิด LEGACY_ROUTES)
+		api.GET("/legacy-usage", controller.FindLegacyUsage)
+
 		// Thailand bulk import
 		th := api.Group("/thailand")
 		{
 			th.POST("/import-all", controller.ImportThailandAll)
 			th.GET("/stats", controller.GetThailandStats)
 			th.POST("/clear-data", controller.ClearThailandData)
+			th.GET("/versions", controller.FindGeographyVersions)
+			th.GET("/versions/:id/changes", controller.FindGeographyChanges)
+			th.GET("/audit", controller.AuditGeoHierarchy)
 		}
 
 		// Pictures API (ใหม่)
@@ -126,49 +316,58 @@ func main() {
 	}
 
 	// ---------- Legacy routes (ถ้ายังต้องใช้) ----------
-	r.GET("/province", controller.FindProvinces)
-	r.GET("/district", controller.FindDistricts)
-	r.GET("/subdistrict", controller.FindSubdistricts)
-
-	r.GET("/admin", controller.FindAdmin)
-	r.GET("/admin/:id", controller.FindAdminById)
-	r.PUT("/admin/:id", controller.UpdateAdminById)
-	r.DELETE("/admin/:id", controller.DeleteAdminById)
-
-	r.GET("/accommodation", controller.FindAccommodation)
-	r.GET("/accommodation/:id", controller.FindAccommodationId)
-	r.POST("/accommodation", controller.CreateAccommodation)
-	r.PUT("/accommodation/:id", controller.UpdateAccommodationById)
-	r.DELETE("/accommodation/:id", controller.DeleteAccommodationById)
-
-	r.GET("/package", controller.FindPackage)
-	r.GET("/package/:id", controller.FindPackageById)
-	r.POST("/package", controller.CreatePackage)
-	r.PUT("/package/:id", controller.UpdatePackageById)
-	r.DELETE("/package/:id", controller.DeletePackageById)
-
-	r.GET("/guide", controller.FindGuide)
-	r.GET("/guide/:id", controller.FindGuideById)
-	r.POST("/guide", controller.CreateGuide)
-	r.PUT("/guide/:id", controller.UpdateGuideById)
-	r.DELETE("/guide/:id", controller.DeleteGuideById)
-
-	r.GET("/room", controller.FindRoom)
-	r.GET("/room/:id", controller.FindRoomById)
-	r.POST("/room", controller.CreateRoom)
-	r.PUT("/room/:id", controller.UpdateRoomById)
-	r.DELETE("/room/:id", controller.DeleteRoomById)
-
-	r.GET("/facility", controller.FindFacility)
-	r.GET("/facility/:id", controller.FindFacilityById)
-	r.POST("/facility", controller.CreateFacility)
-	r.PUT("/facility/:id", controller.UpdateFacilityById)
-	r.DELETE("/facility/:id", controller.DeleteFacilityById)
+	// ตอบพร้อม header Deprecation/Sunset และนับยอดเรียก (GET /api/v1/legacy-usage) ปิดได้ด้วย LEGACY_ROUTES=false
+	if config.LegacyRoutesEnabled() {
+		legacy := r.Group("", middlewares.Deprecated(config.LegacyRoutesSunset(), legacySuccessors))
+		{
+			// ✅ รองรับ FE เดิมที่เรียก POST /upload และ /signup
+			legacy.POST("/upload", controller.UploadPictures)
+			legacy.POST("/signup", controller.CreateAdmin)
 
-	// run
-	r.Run(":" + PORT) // แนะนำแบบนี้
-}
+			legacy.GET("/province", controller.FindProvinces)
+			legacy.GET("/district", controller.FindDistricts)
+			legacy.GET("/subdistrict", controller.FindSubdistricts)
+
+			legacy.GET("/admin", controller.FindAdmin)
+			legacy.GET("/admin/:id", controller.FindAdminById)
+			legacy.PUT("/admin/:id", controller.UpdateAdminById)
+			legacy.DELETE("/admin/:id", controller.DeleteAdminById)
+
+			legacy.GET("/accommodation", controller.FindAccommodation)
+			legacy.GET("/accommodation/:id", controller.FindAccommodationId)
+			legacy.POST("/accommodation", controller.CreateAccommodation)
+			legacy.PUT("/accommodation/:id", controller.UpdateAccommodationById)
+			legacy.DELETE("/accommodation/:id", controller.DeleteAccommodationById)
 
+			legacy.GET("/package", controller.FindPackage)
+			legacy.GET("/package/:id", controller.FindPackageById)
+			legacy.POST("/package", controller.CreatePackage)
+			legacy.PUT("/package/:id", controller.UpdatePackageById)
+			legacy.DELETE("/package/:id", controller.DeletePackageById)
+
+			legacy.GET("/guide", controller.FindGuide)
+			legacy.GET("/guide/:id", controller.FindGuideById)
+			legacy.POST("/guide", controller.CreateGuide)
+			legacy.PUT("/guide/:id", controller.UpdateGuideById)
+			legacy.DELETE("/guide/:id", controller.DeleteGuideById)
+
+			legacy.GET("/room", controller.FindRoom)
+			legacy.GET("/room/:id", controller.FindRoomById)
+			legacy.POST("/room", controller.CreateRoom)
+			legacy.PUT("/room/:id", controller.UpdateRoomById)
+			legacy.DELETE("/room/:id", controller.DeleteRoomById)
+
+			legacy.GET("/facility", controller.FindFacility)
+			legacy.GET("/facility/:id", controller.FindFacilityById)
+			legacy.POST("/facility", controller.CreateFacility)
+			legacy.PUT("/facility/:id", controller.UpdateFacilityById)
+			legacy.DELETE("/facility/:id", controller.DeleteFacilityById)
+		}
+	}
+
+	controller.ServeOpenAPI(r)
+	return r
+}
 
 // CORS
 func CORSMiddleware() gin.HandlerFunc {
@@ -177,7 +376,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers",
 			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, "+
-				"Authorization, accept, origin, Cache-Control, X-Requested-With")
+				"Authorization, accept, origin, Cache-Control, X-Requested-With, "+middlewares.ClientHeader+", "+middlewares.RequestIDHeader+", traceparent, tracestate")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Deprecation, Sunset, Link, "+middlewares.RequestIDHeader)
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
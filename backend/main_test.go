@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/controller"
+)
+
+// route ใหม่ที่ไม่ได้ลงทะเบียนใน controller/openapi.go ต้องทำให้เทสต์นี้ล้ม
+func TestEveryRouteHasOpenAPIEntry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := setupRouter()
+
+	doc, missing := controller.BuildOpenAPI(r.Routes())
+	for _, m := range missing {
+		t.Errorf("%s %s (%s) has no OpenAPI entry", m.Method, m.Path, m.Handler)
+	}
+	for _, route := range r.Routes() {
+		path := strings.NewReplacer(":", "{", "*", "{").Replace(route.Path)
+		item := doc.Paths[closeParams(path)]
+		if item == nil || item[strings.ToLower(route.Method)] == nil {
+			t.Errorf("%s %s missing from document paths", route.Method, route.Path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", w.Code)
+	}
+	var served map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if served["openapi"] == nil || served["paths"] == nil {
+		t.Fatalf("document lacks openapi/paths: %v", served)
+	}
+}
+
+// closeParams ปิดวงเล็บของ {param} ที่แปลงมาจาก :param / *param
+func closeParams(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		if strings.HasPrefix(s, "{") {
+			parts[i] = s + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
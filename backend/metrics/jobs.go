@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var queueDepthDesc = prometheus.NewDesc("jobs_queue_depth",
+	"Background jobs by status (queued = waiting, running = in progress).", []string{"status"}, nil)
+
+// queueCollector ถามจำนวนงานจากฐานข้อมูลตอนถูก scrape แทนการนับเองที่อาจคลาดหลัง restart
+type queueCollector struct {
+	count func() (map[string]int64, error)
+}
+
+func (q queueCollector) Describe(ch chan<- *prometheus.Desc) { ch <- queueDepthDesc }
+
+func (q queueCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := q.count()
+	if err != nil {
+		slog.Error("metrics: job queue depth", "error", err)
+		ch <- prometheus.NewInvalidMetric(queueDepthDesc, err)
+		return
+	}
+	for status, n := range counts {
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(n), status)
+	}
+}
+
+// RegisterJobQueue ส่งออกจำนวนงานเบื้องหลังแยกตามสถานะ count ถูกเรียกทุกครั้งที่ scrape
+func RegisterJobQueue(count func() (map[string]int64, error)) error {
+	return Registry.Register(queueCollector{count: count})
+}
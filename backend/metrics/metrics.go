@@ -0,0 +1,71 @@
+// Package metrics คือ metric ของ Prometheus ทั้งระบบ (เปิดที่ GET /metrics)
+// ใช้ registry ของตัวเองแทน prometheus.DefaultRegisterer เพื่อให้รู้ชัดว่ามี metric อะไรบ้าง
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry เก็บทุก metric ที่ /metrics ส่งออก
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPDuration แยกตาม route pattern (เช่น /api/v1/package/:id) ไม่ใช่ path จริง กัน label บวมตาม id
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route, method and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	// DBQueryDuration แยกตามชนิดคำสั่งของ GORM (create/query/update/delete/row/raw) และตาราง
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "GORM statement latency by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// Bookings นับการจองห้อง (kind=booking) และการชำระตะกร้า (kind=checkout)
+	// outcome: success | rejected (4xx เช่น ที่นั่งเต็ม/ข้อมูลผิด) | error (5xx)
+	Bookings = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bookings_total",
+		Help: "Room bookings and cart checkouts by outcome.",
+	}, []string{"kind", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPDuration, HTTPInFlight, DBQueryDuration, Bookings,
+	)
+}
+
+// Handler คือ handler ของ /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Outcome จัดกลุ่ม HTTP status เป็น success | rejected | error สำหรับ label outcome
+func Outcome(status int) string {
+	switch {
+	case status >= 500:
+		return "error"
+	case status >= 400:
+		return "rejected"
+	}
+	return "success"
+}
+
+// CountBooking นับผลการจองตาม status ที่ตอบไปแล้ว (เรียกด้วย defer ใน handler)
+func CountBooking(kind string, status int) {
+	Bookings.WithLabelValues(kind, Outcome(status)).Inc()
+}
@@ -1,10 +1,11 @@
 package middlewares
 
 import (
-	"net/http"
-	"strings"
-	"github.com/kookkikiv/sa_project/backend/services"
 	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/apierr"
+	"github.com/kookkikiv/sa_project/backend/logging"
+	"github.com/kookkikiv/sa_project/backend/services"
+	"strings"
 )
 
 // validates token
@@ -12,7 +13,7 @@ func Authorizes() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientToken := c.Request.Header.Get("Authorization")
 		if clientToken == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No Authorization header provided"})
+			apierr.Respond(c, apierr.Unauthorized("No Authorization header provided"))
 			return
 		}
 
@@ -21,7 +22,7 @@ func Authorizes() gin.HandlerFunc {
 		if len(extractedToken) == 2 {
 			clientToken = strings.TrimSpace(extractedToken[1])
 		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Incorrect Format of Authorization Token"})
+			apierr.Respond(c, apierr.Unauthorized("Incorrect Format of Authorization Token"))
 			return
 		}
 
@@ -30,13 +31,15 @@ func Authorizes() gin.HandlerFunc {
 			Issuer:    "AuthService",
 		}
 
-		_, err := jwtWrapper.ValidateToken(clientToken)
+		claims, err := jwtWrapper.ValidateToken(clientToken)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			apierr.Respond(c, apierr.Unauthorized("Invalid or expired token"))
 			return
 
 		}
+		c.Set(UserKey, claims.Email)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), "user", claims.Email))
 		c.Next()
 	}
 
-}
\ No newline at end of file
+}
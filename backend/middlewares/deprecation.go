@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientHeader ให้ frontend ระบุตัวเอง (เช่น "admin-web") เพื่อแยกยอดเรียก route เดิมของแต่ละแอป
+// ถ้าไม่ส่งมาจะนับตาม IP
+const ClientHeader = "X-Client-Name"
+
+// LegacyHit คือยอดเรียก route เดิมหนึ่ง route จาก client หนึ่งราย
+type LegacyHit struct {
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Successor string    `json:"successor"`
+	Client    string    `json:"client"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// LegacyRouteKey ถูกตั้งใน gin.Context ของ request ที่มาทาง route เดิม
+// (list ที่ไม่ระบุ page/page_size/cursor บน route เดิมคืนทุกแถวเหมือนก่อนมีการแบ่งหน้า)
+const LegacyRouteKey = "legacy_route"
+
+// client มาจาก header ที่ใครก็ส่งมาได้ จึงจำกัดจำนวนชื่อที่แยกนับ เกินจากนี้รวมเป็น "other"
+const (
+	maxLegacyClients   = 100
+	maxClientNameLen   = 64
+	otherLegacyClients = "other"
+)
+
+var legacyUsage = struct {
+	sync.Mutex
+	hits    map[string]*LegacyHit
+	clients map[string]bool
+}{hits: map[string]*LegacyHit{}, clients: map[string]bool{}}
+
+// Deprecated ติด header Deprecation/Sunset/Link ให้ route เดิมที่ไม่มี /api/v1 และนับยอดเรียกแยกตาม client
+// successors ใช้กับ path ที่ย้ายไปชื่ออื่น (เช่น /province → /api/v1/location/provinces) ที่เหลือคือ /api/v1 + path
+func Deprecated(sunset time.Time, successors map[string]string) gin.HandlerFunc {
+	sunsetHeader := sunset.UTC().Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		successor, link := successors[route], successors[route]
+		if successor == "" {
+			successor, link = "/api/v1"+route, "/api/v1"+c.Request.URL.Path
+		}
+
+		h := c.Writer.Header()
+		h.Set("Deprecation", "true")
+		h.Set("Sunset", sunsetHeader)
+		h.Add("Link", "<"+link+`>; rel="successor-version"`)
+
+		client := strings.TrimSpace(c.GetHeader(ClientHeader))
+		if client == "" {
+			client = c.ClientIP()
+		}
+		if len(client) > maxClientNameLen {
+			client = strings.ToValidUTF8(client[:maxClientNameLen], "")
+		}
+		recordLegacyHit(c.Request.Method, route, successor, client)
+		c.Set(LegacyRouteKey, true)
+		c.Next()
+	}
+}
+
+func recordLegacyHit(method, route, successor, client string) {
+	now := time.Now()
+	legacyUsage.Lock()
+	defer legacyUsage.Unlock()
+	if !legacyUsage.clients[client] {
+		if len(legacyUsage.clients) >= maxLegacyClients {
+			client = otherLegacyClients
+		} else {
+			legacyUsage.clients[client] = true
+		}
+	}
+	key := method + " " + route + " " + client
+	hit, ok := legacyUsage.hits[key]
+	if !ok {
+		// log ครั้งแรกที่ client นี้เรียก route นี้ พอให้รู้ว่าใครยังไม่ย้าย โดยไม่ท่วม log
+		slog.Warn("legacy route called", "method", method, "route", route, "client", client, "successor", successor)
+		hit = &LegacyHit{Method: method, Route: route, Successor: successor, Client: client, FirstSeen: now}
+		legacyUsage.hits[key] = hit
+	}
+	hit.Count++
+	hit.LastSeen = now
+}
+
+// LegacyUsage คือยอดเรียก route เดิมตั้งแต่เริ่มระบบ เรียงจากเรียกบ่อยสุด
+func LegacyUsage() []LegacyHit {
+	legacyUsage.Lock()
+	out := make([]LegacyHit, 0, len(legacyUsage.hits))
+	for _, h := range legacyUsage.hits {
+		out = append(out, *h)
+	}
+	legacyUsage.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].LastSeen.After(out[j].LastSeen)
+	})
+	return out
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/metrics"
+)
+
+// Metrics จับเวลาแต่ละ request ลง http_request_duration_seconds ตาม route pattern
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched" // 404 ทุก path รวมเป็น label เดียว
+		}
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
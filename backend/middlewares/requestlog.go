@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kookkikiv/sa_project/backend/logging"
+)
+
+// RequestIDHeader รับ request ID จาก proxy/frontend ถ้ามี และส่งกลับในคำตอบทุกครั้ง
+const RequestIDHeader = "X-Request-ID"
+
+// key ใน gin.Context
+const (
+	RequestIDKey = "request_id"
+	UserKey      = "user" // email ของผู้ใช้ที่ Authorizes ยืนยันแล้ว
+)
+
+// RequestID ใช้ X-Request-ID ที่ส่งมา (ถ้ารูปแบบใช้ได้) หรือสร้างใหม่ แล้วผูก logger ที่มี request_id ไว้กับ context ของ request
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		ctx := logging.With(c.Request.Context(), "request_id", id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// validRequestID รับเฉพาะตัวอักษรที่พิมพ์ได้ไม่เกิน 128 ตัว กัน header แปลก ๆ ปน log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger แทน gin.Logger: log หนึ่งบรรทัดต่อ request พร้อม route, status, latency และผู้ใช้
+// 5xx = error, 4xx = warn, ที่เหลือ = info
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if user := c.GetString(UserKey); user != "" {
+			attrs = append(attrs, slog.String("user", user))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).LogAttrs(ctx, level, "http request", attrs...)
+	}
+}
@@ -0,0 +1,183 @@
+// Package money เก็บจำนวนเงินเป็นหน่วยย่อยแบบจำนวนเต็ม (สตางค์สำหรับ THB) และแปลงสกุลเงินด้วยอัตราที่บันทึกไว้
+// ห้ามใช้ float กับจำนวนเงิน ใช้ float ได้เฉพาะตอนรับค่าเก่า (เช่น Event.Price) ผ่าน FromFloat
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Base คือสกุลเงินที่ระบบเก็บราคาจริง ทุกสกุลอื่นแปลงจากสกุลนี้
+const Base = "THB"
+
+// BaseExponent คือจำนวนหลักทศนิยมของ Base (1 บาท = 100 สตางค์)
+const BaseExponent = 2
+
+// RateScale คืออัตราส่วนของอัตราแลกเปลี่ยนที่เก็บเป็นจำนวนเต็ม (ทศนิยม 6 ตำแหน่ง)
+const RateScale = 1_000_000
+
+// Money คือจำนวนเงินในหน่วยย่อยของ Base
+type Money int64
+
+var (
+	ErrInvalidAmount = errors.New("money: invalid amount")
+	ErrInvalidRate   = errors.New("money: rate must be a positive decimal with at most 6 decimal places")
+)
+
+func pow10(n int) int64 {
+	v := int64(1)
+	for i := 0; i < n; i++ {
+		v *= 10
+	}
+	return v
+}
+
+// FromMajor แปลงจำนวนเต็มหน่วยหลัก (บาท) เป็น Money
+func FromMajor(v int64) Money {
+	return Money(v * pow10(BaseExponent))
+}
+
+// FromFloat แปลงค่าทศนิยมหน่วยหลัก (บาท) เป็น Money ปัดเศษที่หน่วยย่อย
+func FromFloat(v float64) Money {
+	scaled := v * float64(pow10(BaseExponent))
+	if scaled < 0 {
+		return Money(scaled - 0.5)
+	}
+	return Money(scaled + 0.5)
+}
+
+// Parse แปลงข้อความหน่วยหลัก เช่น "1250" หรือ "1250.50" เป็น Money
+func Parse(s string) (Money, error) {
+	v, err := parseDecimal(s, BaseExponent)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	return Money(v), nil
+}
+
+// ApplyPercent ปรับยอดด้วย percent (+ เพิ่ม, - ลด) ปัดเศษครึ่งขึ้น ผลลัพธ์ไม่ติดลบ
+func (m Money) ApplyPercent(percent int) Money {
+	v := int64(m) * int64(100+percent)
+	if v < 0 {
+		return 0
+	}
+	return Money((v + 50) / 100)
+}
+
+// Mul คูณด้วยจำนวน
+func (m Money) Mul(n int) Money {
+	return m * Money(n)
+}
+
+// String แสดงเป็นหน่วยหลักของ Base เช่น "1250.50"
+func (m Money) String() string {
+	return formatMinor(int64(m), BaseExponent)
+}
+
+// Currency คือสกุลเงินและจำนวนหลักทศนิยม (JPY = 0, USD = 2)
+type Currency struct {
+	Code     string
+	Exponent int
+}
+
+// Rate คือราคาของ 1 หน่วยหลักของ Base ในสกุล Currency คูณ RateScale
+// เช่น 1 THB = 0.0275 USD เก็บเป็น 27500
+type Rate struct {
+	Currency Currency
+	Micros   int64
+}
+
+// Amount คือจำนวนเงินในหน่วยย่อยของสกุลใดสกุลหนึ่ง
+type Amount struct {
+	Currency string `json:"currency"`
+	Minor    int64  `json:"amount"`
+	Display  string `json:"display"`
+}
+
+// Convert แปลง Money (Base) เป็นสกุลของ rate ปัดเศษครึ่งขึ้นที่หน่วยย่อยของสกุลปลายทาง
+func Convert(m Money, rate Rate) Amount {
+	// minor_target = minor_base * micros * 10^exp_target / (10^exp_base * RateScale)
+	num := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(rate.Micros))
+	num.Mul(num, big.NewInt(pow10(rate.Currency.Exponent)))
+	den := big.NewInt(pow10(BaseExponent) * RateScale)
+
+	neg := num.Sign() < 0
+	num.Abs(num)
+	num.Add(num, new(big.Int).Quo(den, big.NewInt(2)))
+	num.Quo(num, den)
+	minor := num.Int64()
+	if neg {
+		minor = -minor
+	}
+	return Amount{
+		Currency: rate.Currency.Code,
+		Minor:    minor,
+		Display:  rate.Currency.Code + " " + formatMinor(minor, rate.Currency.Exponent),
+	}
+}
+
+// BaseAmount คือ m ในรูป Amount ของ Base (ใช้ตอนไม่ได้ขอสกุลอื่น)
+func BaseAmount(m Money) Amount {
+	return Amount{Currency: Base, Minor: int64(m), Display: Base + " " + m.String()}
+}
+
+// ParseRate แปลงอัตราทศนิยม เช่น "0.0275" เป็นจำนวนเต็มคูณ RateScale
+func ParseRate(s string) (int64, error) {
+	v, err := parseDecimal(s, 6)
+	if err != nil || v <= 0 {
+		return 0, ErrInvalidRate
+	}
+	return v, nil
+}
+
+// FormatRate แสดงอัตราที่เก็บเป็นจำนวนเต็มกลับเป็นทศนิยม ตัดศูนย์ท้าย
+func FormatRate(micros int64) string {
+	s := formatMinor(micros, 6)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// parseDecimal แปลงทศนิยมฐานสิบเป็นจำนวนเต็มคูณ 10^places โดยไม่ผ่าน float
+func parseDecimal(s string, places int) (int64, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrInvalidAmount
+	}
+	// เครื่องหมายได้แค่ตัวเดียวด้านหน้า ("--5" ไม่ใช่ 5)
+	if strings.ContainsAny(whole+frac, "+-") {
+		return 0, ErrInvalidAmount
+	}
+	if len(frac) > places {
+		return 0, ErrInvalidAmount
+	}
+	frac += strings.Repeat("0", places-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+func formatMinor(v int64, exp int) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if exp == 0 {
+		return sign + strconv.FormatInt(v, 10)
+	}
+	p := pow10(exp)
+	return fmt.Sprintf("%s%d.%0*d", sign, v/p, exp, v%p)
+}
@@ -0,0 +1,116 @@
+package money
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	usd := Currency{Code: "USD", Exponent: 2}
+	jpy := Currency{Code: "JPY", Exponent: 0}
+	kwd := Currency{Code: "KWD", Exponent: 3}
+	tests := []struct {
+		name        string
+		m           Money
+		rate        Rate
+		wantMinor   int64
+		wantDisplay string
+	}{
+		{"two decimals", FromMajor(1000), Rate{usd, 27_500}, 2750, "USD 27.50"},
+		{"zero decimals", FromMajor(1000), Rate{jpy, 4_123_456}, 4123, "JPY 4123"},
+		{"three decimals", FromMajor(1000), Rate{kwd, 8_512}, 8512, "KWD 8.512"},
+		{"half rounds up", 1, Rate{usd, 500_000}, 1, "USD 0.01"},
+		{"below half rounds down", 1, Rate{usd, 499_999}, 0, "USD 0.00"},
+		{"negative rounds away from zero", -1, Rate{usd, 500_000}, -1, "USD -0.01"},
+		{"large amount does not overflow", 9_000_000_000_000, Rate{usd, 1_000_000_000}, 9_000_000_000_000_000, "USD 90000000000000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.m, tt.rate)
+			if got.Minor != tt.wantMinor || got.Display != tt.wantDisplay || got.Currency != tt.rate.Currency.Code {
+				t.Errorf("Convert(%d) = %+v, want %d %q", tt.m, got, tt.wantMinor, tt.wantDisplay)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0.0275", 27_500, false},
+		{"1", 1_000_000, false},
+		{" 36.5 ", 36_500_000, false},
+		{".5", 500_000, false},
+		{"+2", 2_000_000, false},
+		{"0.000001", 1, false},
+		{"0.0000001", 0, true},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"--1", 0, true},
+		{"1.2.3", 0, true},
+		{"1e3", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if tt.wantErr {
+				if err != ErrInvalidRate {
+					t.Errorf("ParseRate(%q) = %d, %v, want ErrInvalidRate", tt.in, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseRate(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+			}
+			if back, _ := ParseRate(FormatRate(got)); back != got {
+				t.Errorf("FormatRate(%d) = %q does not round-trip", got, FormatRate(got))
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{"1250", 125_000, false},
+		{"1250.5", 125_050, false},
+		{"1250.50", 125_050, false},
+		{"-3.25", -325, false},
+		{"1250.505", 0, true},
+		{"--5", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Parse(%q) = %d, %v, want %d (err %v)", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{"percent half rounds up", Money(5).ApplyPercent(-50), 3},
+		{"percent rounds down below half", Money(99_999).ApplyPercent(-15), 84_999},
+		{"percent never negative", Money(100_000).ApplyPercent(-150), 0},
+		{"percent increase", Money(100_000).ApplyPercent(30), 130_000},
+		{"float half rounds up", FromFloat(0.125), 13},
+		{"negative float rounds away from zero", FromFloat(-0.125), -13},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Package pricing คำนวณราคาห้องพักและแพ็คเกจจากราคาตั้งต้นและกฎราคา
+// เป็นฟังก์ชันล้วน ไม่แตะฐานข้อมูล เพื่อให้ตะกร้า การจอง และ checkout ได้ราคาเดียวกันเสมอ
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+// Unit คือหน่วยที่ราคาตั้งต้นคิด
+type Unit string
+
+const (
+	PerPerson Unit = "per_person"
+	PerRoom   Unit = "per_room"
+)
+
+// RuleKind คือชนิดของกฎราคา
+type RuleKind string
+
+const (
+	Season     RuleKind = "season"      // ปรับราคา Percent ในช่วง From..To (เช่น high +30, low -20)
+	Weekend    RuleKind = "weekend"     // บวก Percent สำหรับคืนวันศุกร์/เสาร์
+	Child      RuleKind = "child"       // เด็กจ่าย Percent ของราคาผู้ใหญ่
+	EarlyBird  RuleKind = "early_bird"  // ลด Percent เมื่อจองล่วงหน้าอย่างน้อย Days วัน
+	LastMinute RuleKind = "last_minute" // ลด Percent เมื่อจองก่อนเริ่มไม่เกิน Days วัน
+	Group      RuleKind = "group"       // ปรับ Percent เมื่อจำนวน (คนหรือห้อง) ถึง MinQuantity ใช้ขั้นที่สูงสุดที่ถึง
+)
+
+// Rule คือกฎราคา 1 ข้อ Percent เป็นบวก = บวกเพิ่ม, ลบ = ลด (ยกเว้น Child ที่เป็นสัดส่วนของราคาผู้ใหญ่)
+type Rule struct {
+	ID      uint
+	Name    string
+	Kind    RuleKind
+	Percent int
+	From    time.Time // Season: วันแรก (นับรวม)
+	To      time.Time // Season: วันสุดท้าย (นับรวม)
+	Days    int       // EarlyBird / LastMinute
+	// Group: จำนวนขั้นต่ำ (per_person นับผู้ใหญ่+เด็ก, per_room นับห้อง)
+	MinQuantity int
+	TargetID    *uint // nil = กฎรวมของทั้ง scope, มีค่า = กฎเฉพาะแพ็คเกจ/ห้องนั้น
+}
+
+// overrides บอกว่า r ควรแทน cur ที่เลือกไว้แล้วหรือไม่ (Weekend/Child ใช้ได้ข้อเดียว)
+// กฎเฉพาะรายการชนะกฎรวมเสมอ ชนิดเดียวกันใช้ข้อหลังสุด
+func (r *Rule) overrides(cur *Rule) bool {
+	return cur == nil || r.TargetID != nil || cur.TargetID == nil
+}
+
+// Request คือสิ่งที่ต้องการให้ตีราคา
+// Nightly = true (ห้องพัก) คิดราคาทุกคืนตั้งแต่ Start ถึงก่อน End, false (แพ็คเกจ) คิดครั้งเดียวตามวัน Start
+type Request struct {
+	BasePrice money.Money // หน่วยย่อย (สตางค์)
+	Unit      Unit
+	Nightly   bool
+	Start     time.Time
+	End       time.Time
+	Adults    int
+	Children  int
+	Rooms     int
+	BookedAt  time.Time
+}
+
+// Line คือรายการ 1 บรรทัดในใบเสนอราคา Amount = Quantity * UnitPrice (ส่วนลดเป็นค่าลบ)
+type Line struct {
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Amount      money.Money `json:"amount"`
+}
+
+// Quote คือใบเสนอราคาแบบแจกแจง
+type Quote struct {
+	Unit     Unit        `json:"unit"`
+	Lines    []Line      `json:"lines"`
+	Subtotal money.Money `json:"subtotal"`
+	Discount money.Money `json:"discount"`
+	Total    money.Money `json:"total"`
+}
+
+var (
+	ErrInvalidUnit  = errors.New("pricing: unit must be per_person or per_room")
+	ErrInvalidDates = errors.New("pricing: end must be after start")
+	ErrNoGuests     = errors.New("pricing: at least one adult is required")
+	ErrNoRooms      = errors.New("pricing: at least one room is required")
+)
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// seasonFor หา season ที่ครอบวันนั้น ถ้าทับกันหลายช่วงใช้ช่วงที่แคบที่สุด
+func seasonFor(day time.Time, seasons []Rule) *Rule {
+	var best *Rule
+	for i := range seasons {
+		s := &seasons[i]
+		if day.Before(dateOnly(s.From)) || day.After(dateOnly(s.To)) {
+			continue
+		}
+		if best == nil || s.To.Sub(s.From) < best.To.Sub(best.From) {
+			best = s
+		}
+	}
+	return best
+}
+
+func isWeekendNight(day time.Time) bool {
+	wd := day.Weekday()
+	return wd == time.Friday || wd == time.Saturday
+}
+
+// Evaluate ตีราคาตามกฎทั้งหมด ลำดับ: season -> weekend -> ราคากลุ่ม -> อัตราเด็ก -> ส่วนลดจองล่วงหน้า/นาทีสุดท้าย
+func Evaluate(req Request, rules []Rule) (Quote, error) {
+	if req.Unit != PerPerson && req.Unit != PerRoom {
+		return Quote{}, ErrInvalidUnit
+	}
+	if req.Unit == PerPerson && req.Adults < 1 {
+		return Quote{}, ErrNoGuests
+	}
+	if req.Unit == PerRoom && req.Rooms < 1 {
+		return Quote{}, ErrNoRooms
+	}
+
+	start := dateOnly(req.Start)
+	days := []time.Time{start}
+	if req.Nightly {
+		end := dateOnly(req.End)
+		if !end.After(start) {
+			return Quote{}, ErrInvalidDates
+		}
+		days = days[:0]
+		for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	}
+
+	var seasons, discounts []Rule
+	var weekend, child, group *Rule
+	for i := range rules {
+		r := &rules[i]
+		switch r.Kind {
+		case Season:
+			seasons = append(seasons, *r)
+		case Weekend:
+			if r.overrides(weekend) {
+				weekend = r
+			}
+		case Child:
+			if r.overrides(child) {
+				child = r
+			}
+		case EarlyBird, LastMinute:
+			discounts = append(discounts, *r)
+		case Group:
+			if qty := groupQuantity(req); qty >= r.MinQuantity && (group == nil || r.MinQuantity > group.MinQuantity) {
+				group = r
+			}
+		}
+	}
+
+	q := Quote{Unit: req.Unit, Lines: []Line{}}
+	for _, day := range days {
+		price := req.BasePrice
+		var notes []string
+		if s := seasonFor(day, seasons); s != nil {
+			price = price.ApplyPercent(s.Percent)
+			notes = append(notes, fmt.Sprintf("%s %+d%%", s.Name, s.Percent))
+		}
+		if req.Nightly && weekend != nil && isWeekendNight(day) {
+			price = price.ApplyPercent(weekend.Percent)
+			notes = append(notes, fmt.Sprintf("%s %+d%%", weekend.Name, weekend.Percent))
+		}
+		if group != nil {
+			price = price.ApplyPercent(group.Percent)
+			notes = append(notes, fmt.Sprintf("%s %d+ %+d%%", group.Name, group.MinQuantity, group.Percent))
+		}
+
+		label := day.Format("2006-01-02")
+		if len(notes) > 0 {
+			label += " (" + strings.Join(notes, ", ") + ")"
+		}
+
+		if req.Unit == PerRoom {
+			q.addLine(Line{Code: "room", Description: label, Quantity: req.Rooms, UnitPrice: price})
+			continue
+		}
+		q.addLine(Line{Code: "adult", Description: label, Quantity: req.Adults, UnitPrice: price})
+		if req.Children > 0 {
+			childPrice := price
+			desc := label
+			if child != nil {
+				childPrice = price.ApplyPercent(child.Percent - 100)
+				desc = fmt.Sprintf("%s (%s %d%%)", label, child.Name, child.Percent)
+			}
+			q.addLine(Line{Code: "child", Description: desc, Quantity: req.Children, UnitPrice: childPrice})
+		}
+	}
+
+	// ส่วนลดใช้ได้ข้อเดียว เลือกที่ลดมากที่สุด
+	if !req.BookedAt.IsZero() {
+		lead := int(start.Sub(dateOnly(req.BookedAt)).Hours() / 24)
+		var discount *Rule
+		for i := range discounts {
+			d := &discounts[i]
+			ok := (d.Kind == EarlyBird && lead >= d.Days) ||
+				(d.Kind == LastMinute && lead >= 0 && lead <= d.Days)
+			if ok && d.Percent < 0 && (discount == nil || d.Percent < discount.Percent) {
+				discount = d
+			}
+		}
+		if discount != nil {
+			amount := q.Subtotal - q.Subtotal.ApplyPercent(discount.Percent)
+			q.Lines = append(q.Lines, Line{
+				Code:        string(discount.Kind),
+				Description: fmt.Sprintf("%s %d%%", discount.Name, discount.Percent),
+				Quantity:    1,
+				UnitPrice:   -amount,
+				Amount:      -amount,
+			})
+			q.Discount += amount
+		}
+	}
+
+	q.Total = q.Subtotal - q.Discount
+	return q, nil
+}
+
+// groupQuantity คือจำนวนที่ใช้เทียบขั้นราคากลุ่ม
+func groupQuantity(req Request) int {
+	if req.Unit == PerRoom {
+		return req.Rooms
+	}
+	return req.Adults + req.Children
+}
+
+func (q *Quote) addLine(l Line) {
+	l.Amount = l.UnitPrice.Mul(l.Quantity)
+	q.Lines = append(q.Lines, l)
+	q.Subtotal += l.Amount
+}
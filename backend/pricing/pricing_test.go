@@ -0,0 +1,157 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kookkikiv/sa_project/backend/money"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func target(id uint) *uint { return &id }
+
+// กฎเฉพาะรายการต้องชนะกฎรวมไม่ว่าจะส่งมาลำดับไหน
+func TestEvaluateTargetedRuleWins(t *testing.T) {
+	globalWeekend := Rule{ID: 1, Name: "weekend", Kind: Weekend, Percent: 10}
+	itemWeekend := Rule{ID: 2, Name: "weekend item", Kind: Weekend, Percent: 30, TargetID: target(7)}
+	globalChild := Rule{ID: 3, Name: "child", Kind: Child, Percent: 50}
+	itemChild := Rule{ID: 4, Name: "child item", Kind: Child, Percent: 25, TargetID: target(7)}
+	laterGlobal := Rule{ID: 5, Name: "weekend late", Kind: Weekend, Percent: 20}
+
+	tests := []struct {
+		name      string
+		rules     []Rule
+		wantAdult money.Money
+		wantChild money.Money
+	}{
+		{"targeted after global", []Rule{globalWeekend, globalChild, itemWeekend, itemChild}, 130000, 32500},
+		{"targeted before global", []Rule{itemWeekend, itemChild, globalWeekend, globalChild}, 130000, 32500},
+		{"global only", []Rule{globalWeekend, globalChild}, 110000, 55000},
+		{"later global of same kind wins", []Rule{globalWeekend, laterGlobal}, 120000, 120000},
+		{"later global does not beat targeted", []Rule{itemWeekend, laterGlobal}, 130000, 130000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Evaluate(Request{
+				BasePrice: money.FromMajor(1000), Unit: PerPerson, Nightly: true,
+				Start: date("2026-10-23"), End: date("2026-10-24"), // คืนวันศุกร์
+				Adults: 1, Children: 1,
+			}, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(q.Lines) != 2 {
+				t.Fatalf("lines = %+v", q.Lines)
+			}
+			if got := q.Lines[0].UnitPrice; got != tt.wantAdult {
+				t.Errorf("adult = %v, want %v", got, tt.wantAdult)
+			}
+			if got := q.Lines[1].UnitPrice; got != tt.wantChild {
+				t.Errorf("child = %v, want %v", got, tt.wantChild)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	thb := money.FromMajor
+	// จันทร์ 19 ต.ค. 2026, ศุกร์ 23, เสาร์ 24
+	nightly := func(start, end string, adults, children int) Request {
+		return Request{BasePrice: thb(1000), Unit: PerPerson, Nightly: true,
+			Start: date(start), End: date(end), Adults: adults, Children: children}
+	}
+	pkg := func(start string, adults, children int) Request {
+		return Request{BasePrice: thb(1000), Unit: PerPerson, Start: date(start), Adults: adults, Children: children}
+	}
+	booked := func(r Request, at string) Request {
+		r.BookedAt = date(at)
+		return r
+	}
+	high := Rule{Name: "high", Kind: Season, Percent: 30, From: date("2026-10-01"), To: date("2026-10-31")}
+	low := Rule{Name: "low", Kind: Season, Percent: -20, From: date("2026-10-20"), To: date("2026-10-20")}
+	weekend := Rule{Name: "weekend", Kind: Weekend, Percent: 10}
+	child := Rule{Name: "child", Kind: Child, Percent: 50}
+	group4 := Rule{Name: "group", Kind: Group, Percent: -5, MinQuantity: 4}
+	group8 := Rule{Name: "group", Kind: Group, Percent: -10, MinQuantity: 8}
+	early30 := Rule{Name: "early", Kind: EarlyBird, Percent: -10, Days: 30}
+	early60 := Rule{Name: "early+", Kind: EarlyBird, Percent: -15, Days: 60}
+	lastMinute := Rule{Name: "last", Kind: LastMinute, Percent: -20, Days: 3}
+	discounts := []Rule{early30, early60, lastMinute}
+
+	tests := []struct {
+		name         string
+		req          Request
+		rules        []Rule
+		wantSubtotal money.Money
+		wantDiscount money.Money
+	}{
+		{"no rules", nightly("2026-10-19", "2026-10-21", 2, 0), nil, thb(4000), 0},
+		{"narrowest season wins", nightly("2026-10-19", "2026-10-21", 1, 0), []Rule{high, low}, thb(1300 + 800), 0},
+		{"season outside range", nightly("2026-11-02", "2026-11-03", 1, 0), []Rule{high}, thb(1000), 0},
+		{"weekend on fri and sat nights", nightly("2026-10-22", "2026-10-25", 1, 0), []Rule{weekend}, thb(1000 + 1100 + 1100), 0},
+		{"weekend ignored for packages", pkg("2026-10-23", 1, 0), []Rule{weekend}, thb(1000), 0},
+		{"season then weekend", nightly("2026-10-23", "2026-10-24", 1, 0), []Rule{weekend, high}, thb(1430), 0},
+		{"child percent of adult price", pkg("2026-11-02", 2, 1), []Rule{child}, thb(2000 + 500), 0},
+		{"highest group tier reached", pkg("2026-11-02", 5, 3), []Rule{group4, group8, child}, thb(5*900 + 3*450), 0},
+		{"group below minimum", pkg("2026-11-02", 3, 0), []Rule{group4}, thb(3000), 0},
+		{"per room group counts rooms", Request{BasePrice: thb(1000), Unit: PerRoom, Nightly: true,
+			Start: date("2026-11-02"), End: date("2026-11-03"), Adults: 1, Rooms: 4}, []Rule{group4}, thb(4 * 950), 0},
+		{"biggest early bird only", booked(pkg("2026-11-02", 1, 0), "2026-08-01"), discounts, thb(1000), thb(150)},
+		{"early bird threshold", booked(pkg("2026-11-02", 1, 0), "2026-09-20"), discounts, thb(1000), thb(100)},
+		{"last minute", booked(pkg("2026-11-02", 1, 0), "2026-10-31"), discounts, thb(1000), thb(200)},
+		{"no discount window", booked(pkg("2026-11-02", 1, 0), "2026-10-20"), discounts, thb(1000), 0},
+		{"no booking date no discount", pkg("2026-11-02", 1, 0), discounts, thb(1000), 0},
+		{"discount off stacked subtotal", booked(nightly("2026-10-23", "2026-10-24", 2, 2), "2026-10-22"),
+			[]Rule{high, weekend, group4, child, lastMinute}, 2*135850 + 2*67925, 81510},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Evaluate(tt.req, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Subtotal != tt.wantSubtotal || q.Discount != tt.wantDiscount {
+				t.Errorf("subtotal/discount = %v/%v, want %v/%v (lines %+v)",
+					q.Subtotal, q.Discount, tt.wantSubtotal, tt.wantDiscount, q.Lines)
+			}
+			if q.Total != q.Subtotal-q.Discount {
+				t.Errorf("total = %v, want subtotal - discount", q.Total)
+			}
+			var sum money.Money
+			for _, l := range q.Lines {
+				sum += l.Amount
+			}
+			if sum != q.Total {
+				t.Errorf("lines sum to %v, total is %v", sum, q.Total)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	start := date("2026-11-02")
+	tests := []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{"unknown unit", Request{Unit: "per_day", Adults: 1, Start: start}, ErrInvalidUnit},
+		{"no adults", Request{Unit: PerPerson, Children: 2, Start: start}, ErrNoGuests},
+		{"no rooms", Request{Unit: PerRoom, Adults: 2, Start: start}, ErrNoRooms},
+		{"end before start", Request{Unit: PerPerson, Adults: 1, Nightly: true, Start: start, End: start}, ErrInvalidDates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Evaluate(tt.req, nil); err != tt.want {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,8 @@
 package seeder
 
 import (
-	"fmt"
+	"log/slog"
+
 	"github.com/kookkikiv/sa_project/backend/config"
 	"github.com/kookkikiv/sa_project/backend/entity"
 )
@@ -17,8 +18,6 @@ func SeedGuideTypes() {
 			{Name: "Adventure Guide", Description: "Handles outdoor activities"},
 			{Name: "Historical Guide", Description: "Expert in history-related tours"},
 		})
-		fmt.Println("Seeded base GuideType data")
+		slog.Info("seeded base GuideType data")
 	}
 }
-
-
@@ -1,8 +1,7 @@
 package seeder
 
 import (
-	"fmt"
-	"time"
+	"log/slog"
 
 	"github.com/kookkikiv/sa_project/backend/config"
 	"github.com/kookkikiv/sa_project/backend/entity"
@@ -12,17 +11,15 @@ func SeedServiceAreas() {
 	db := config.DB()
 
 	// Example ServiceAreas (assume GuideType and ProvinceArea already exist)
-	serviceAreas := []entity.ServiceArea{
-		
-	}
+	serviceAreas := []entity.ServiceArea{}
 
 	for _, sa := range serviceAreas {
 		// Only create if it doesn’t exist (check by ProvinceArea and District)
 		db.FirstOrCreate(&sa, entity.ServiceArea{
 			ProvinceID: sa.ProvinceID,
-			DistrictID:        sa.DistrictID,
+			DistrictID: sa.DistrictID,
 		})
 	}
 
-	fmt.Println("ServiceAreas seeding completed at", time.Now())
+	slog.Info("ServiceAreas seeding completed")
 }
@@ -1,69 +1,65 @@
-
 package services
 
 import (
-   "errors"
-   "time"
-   jwt "github.com/dgrijalva/jwt-go"
+	"errors"
+	jwt "github.com/dgrijalva/jwt-go"
+	"time"
 )
 
-
 // JwtWrapper wraps the signing key and the issuer
 type JwtWrapper struct {
-   SecretKey       string
-   Issuer          string
-   ExpirationHours int64
-
+	SecretKey       string
+	Issuer          string
+	ExpirationHours int64
 }
 
 // JwtClaim adds email as a claim to the token
 type JwtClaim struct {
-   Email string
-   jwt.StandardClaims
+	Email string
+	jwt.StandardClaims
 }
 
-
 // Generate Token generates a jwt token
 func (j *JwtWrapper) GenerateToken(email string) (signedToken string, err error) {
-   claims := &JwtClaim{
-       Email: email,
-       StandardClaims: jwt.StandardClaims{
-           ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(j.ExpirationHours)).Unix(),
-           Issuer:    j.Issuer,
-       },
-   }
+	claims := &JwtClaim{
+		Email: email,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(j.ExpirationHours)).Unix(),
+			Issuer:    j.Issuer,
+		},
+	}
 
-   token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-   signedToken, err = token.SignedString([]byte(j.SecretKey))
-   if err != nil {
-       return
-   }
-   return
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signedToken, err = token.SignedString([]byte(j.SecretKey))
+	if err != nil {
+		return
+	}
+	return
 }
 
 // Validate Token validates the jwt token
 func (j *JwtWrapper) ValidateToken(signedToken string) (claims *JwtClaim, err error) {
-   token, err := jwt.ParseWithClaims(
-       signedToken,
-       &JwtClaim{},
-       func(token *jwt.Token) (interface{}, error) {
-           return []byte(j.SecretKey), nil
-       },
-   )
+	token, err := jwt.ParseWithClaims(
+		signedToken,
+		&JwtClaim{},
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(j.SecretKey), nil
+		},
+	)
 
-   if err != nil {
-       return
-   }
+	if err != nil {
+		return
+	}
 
-   claims, ok := token.Claims.(*JwtClaim)
-   if !ok {
-       err = errors.New("Couldn't parse claims")
-       return
-   }
+	claims, ok := token.Claims.(*JwtClaim)
+	if !ok {
+		err = errors.New("Couldn't parse claims")
+		return
+	}
 
-   if claims.ExpiresAt < time.Now().Local().Unix() {
-       err = errors.New("JWT is expired")
-       return
-   }
-   return
-}
\ No newline at end of file
+	if claims.ExpiresAt < time.Now().Local().Unix() {
+		err = errors.New("JWT is expired")
+		return
+	}
+	return
+}
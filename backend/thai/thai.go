@@ -0,0 +1,231 @@
+// Package thai ตัดคำภาษาไทยด้วยพจนานุกรม (maximal matching) และทำคีย์สำหรับเทียบชื่อสถานที่
+// ที่สะกดต่างกัน เช่น "Chiang Mai" / "Chiangmai" / "เชียงใหม่" ให้ได้คีย์เดียวกันกับชื่อในฐานข้อมูล
+package thai
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed words.txt
+var wordList string
+
+var (
+	baseOnce sync.Once
+	base     *Segmenter
+)
+
+// Segmenter ตัดคำไทยตามพจนานุกรม ใช้พร้อมกันหลาย goroutine ได้หลังสร้างเสร็จ (ห้าม Add ระหว่างใช้งาน)
+type Segmenter struct {
+	words  map[string]struct{}
+	maxLen int // ความยาวคำที่ยาวที่สุด (จำนวน rune)
+}
+
+// NewSegmenter สร้างตัวตัดคำจากคำที่ให้มา (ไม่รวมพจนานุกรมพื้นฐาน ใช้ Default สำหรับกรณีทั่วไป)
+func NewSegmenter(words ...string) *Segmenter {
+	s := &Segmenter{words: map[string]struct{}{}}
+	s.Add(words...)
+	return s
+}
+
+// Default คืนสำเนาของตัวตัดคำที่มีพจนานุกรมพื้นฐาน (words.txt) ผู้เรียกเพิ่มคำเองได้
+func Default() *Segmenter {
+	baseOnce.Do(func() {
+		base = NewSegmenter()
+		sc := bufio.NewScanner(strings.NewReader(wordList))
+		for sc.Scan() {
+			if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				base.Add(line)
+			}
+		}
+	})
+	return base.Clone()
+}
+
+// Clone คัดลอกพจนานุกรม
+func (s *Segmenter) Clone() *Segmenter {
+	c := &Segmenter{words: make(map[string]struct{}, len(s.words)), maxLen: s.maxLen}
+	for w := range s.words {
+		c.words[w] = struct{}{}
+	}
+	return c
+}
+
+// Add เพิ่มคำ เก็บเฉพาะคำที่เป็นอักษรไทยล้วน (ชื่อที่มีช่องว่างจะถูกแยกเป็นหลายคำ)
+func (s *Segmenter) Add(words ...string) {
+	for _, w := range words {
+		for _, part := range strings.FieldsFunc(w, func(r rune) bool { return !isThaiLetter(r) }) {
+			s.words[part] = struct{}{}
+			if n := utf8.RuneCountInString(part); n > s.maxLen {
+				s.maxLen = n
+			}
+		}
+	}
+}
+
+// Len คือจำนวนคำในพจนานุกรม
+func (s *Segmenter) Len() int { return len(s.words) }
+
+// Segment แยกข้อความเป็นคำ: ช่วงอักษรไทยตัดตามพจนานุกรม ส่วนตัวอักษรอื่นแยกตามช่องว่าง/เครื่องหมาย
+// ตัวอักษรละตินคืนเป็นตัวพิมพ์เล็ก ส่วนที่ไม่รู้จักในพจนานุกรมคืนเป็นก้อนเดียวต่อเนื่อง
+func (s *Segmenter) Segment(text string) []string {
+	var out []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isThaiLetter(r):
+			j := i
+			for j < len(runes) && isThaiLetter(runes[j]) {
+				j++
+			}
+			out = append(out, s.segmentThai(runes[i:j])...)
+			i = j
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && !isThaiLetter(runes[j]) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || unicode.Is(unicode.Mn, runes[j])) {
+				j++
+			}
+			out = append(out, strings.ToLower(string(runes[i:j])))
+			i = j
+		default:
+			i++
+		}
+	}
+	return out
+}
+
+// segmentThai หาวิธีตัดที่มีส่วนไม่รู้จักน้อยที่สุด แล้วจำนวนคำน้อยที่สุด (maximal matching)
+func (s *Segmenter) segmentThai(runes []rune) []string {
+	n := len(runes)
+	type step struct {
+		unknown, words int
+		prev           int
+		known          bool
+		ok             bool
+	}
+	best := make([]step, n+1)
+	best[0] = step{ok: true}
+	better := func(a, b step) bool {
+		return !b.ok || a.unknown < b.unknown || (a.unknown == b.unknown && a.words < b.words)
+	}
+	for i := 0; i < n; i++ {
+		if !best[i].ok {
+			continue
+		}
+		for l := 1; l <= s.maxLen && i+l <= n; l++ {
+			j := i + l
+			if j < n && isTrailing(runes[j]) {
+				continue // ห้ามตัดกลางพยางค์ (ก่อนสระหลัง/วรรณยุกต์)
+			}
+			if _, ok := s.words[string(runes[i:j])]; !ok {
+				continue
+			}
+			cand := step{unknown: best[i].unknown, words: best[i].words + 1, prev: i, known: true, ok: true}
+			if better(cand, best[j]) {
+				best[j] = cand
+			}
+		}
+		j := nextCluster(runes, i)
+		cand := step{unknown: best[i].unknown + 1, words: best[i].words + 1, prev: i, ok: true}
+		if better(cand, best[j]) {
+			best[j] = cand
+		}
+	}
+
+	// ย้อนหาคำจากท้าย แล้วรวมก้อนที่ไม่รู้จักที่อยู่ติดกันเป็นคำเดียว
+	type piece struct {
+		from, to int
+		known    bool
+	}
+	var pieces []piece
+	for j := n; j > 0; j = best[j].prev {
+		pieces = append(pieces, piece{best[j].prev, j, best[j].known})
+	}
+	var out []string
+	for k := len(pieces) - 1; k >= 0; k-- {
+		p := pieces[k]
+		for !p.known && k > 0 && !pieces[k-1].known {
+			k--
+			p.to = pieces[k].to
+		}
+		out = append(out, string(runes[p.from:p.to]))
+	}
+	return out
+}
+
+// nextCluster ข้ามไป 1 กลุ่มอักษร: สระหน้า + พยัญชนะ + สระหลัง/วรรณยุกต์ที่ตามมา
+func nextCluster(runes []rune, i int) int {
+	j := i
+	if isLeadingVowel(runes[j]) {
+		j++
+	}
+	if j < len(runes) {
+		j++
+	}
+	for j < len(runes) && isTrailing(runes[j]) {
+		j++
+	}
+	return j
+}
+
+// isThaiLetter คืออักษรไทยที่เป็นส่วนของคำ (ไม่รวมเลขไทย, ฯ, ๆ, ฿)
+func isThaiLetter(r rune) bool {
+	return r >= 0x0E01 && r <= 0x0E4E && r != 0x0E2F && r != 0x0E3F && r != 0x0E46
+}
+
+// isLeadingVowel คือสระที่เขียนหน้าพยัญชนะ (เ แ โ ใ ไ)
+func isLeadingVowel(r rune) bool { return r >= 0x0E40 && r <= 0x0E44 }
+
+// isTrailing คือสระหลัง/บน/ล่างและวรรณยุกต์ที่ต้องติดกับพยัญชนะข้างหน้า
+func isTrailing(r rune) bool {
+	switch {
+	case r == 0x0E30 || r == 0x0E31 || r == 0x0E32 || r == 0x0E33 || r == 0x0E45:
+		return true
+	case r >= 0x0E34 && r <= 0x0E3A:
+		return true
+	case r >= 0x0E47 && r <= 0x0E4E:
+		return true
+	}
+	return false
+}
+
+// HasThai บอกว่าข้อความมีอักษรไทย
+func HasThai(s string) bool {
+	for _, r := range s {
+		if isThaiLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// latinFolds รวมการถอดเสียงอังกฤษที่สะกดต่างกันบ่อย (เช่น Phuket/Puket, Mueang/Muang, Chieng/Chiang)
+var latinFolds = strings.NewReplacer(
+	"ph", "p", "th", "t", "kh", "k",
+	"ue", "u", "eu", "u", "oo", "u", "ee", "i", "aa", "a", "ie", "ia",
+)
+
+// Key คืนคีย์สำหรับเทียบชื่อ: ตัดช่องว่าง/ขีด/จุด, ตัวพิมพ์เล็ก, รวมการสะกดอังกฤษที่ต่างกัน และตัดตัวอักษรละตินที่ซ้ำติดกัน
+// "Chiang Mai", "chiang-mai", "Chiangmai", "Chiengmai" ได้ "chiangmai" ส่วนชื่อไทยได้ตัวอักษรไทยต่อกันโดยไม่มีช่องว่าง
+func Key(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if isThaiLetter(r) || (r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			b.WriteRune(r)
+		}
+	}
+	folded := []rune(latinFolds.Replace(b.String()))
+	out := folded[:0]
+	for _, r := range folded {
+		if len(out) > 0 && r < utf8.RuneSelf && unicode.IsLetter(r) && r == out[len(out)-1] {
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
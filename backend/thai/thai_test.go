@@ -0,0 +1,74 @@
+package thai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	seg := NewSegmenter("เชียงใหม่", "เชียง", "ใหม่", "ทัวร์", "ที่พัก", "ใกล้", "ทะเล", "ภูเก็ต")
+	tests := []struct {
+		name  string
+		in    string
+		want  []string
+		words []string // ถ้ากำหนด ใช้พจนานุกรมนี้แทน seg
+	}{
+		{"longest match wins", "ทัวร์เชียงใหม่", []string{"ทัวร์", "เชียงใหม่"}, nil},
+		{"several words", "ที่พักใกล้ทะเลภูเก็ต", []string{"ที่พัก", "ใกล้", "ทะเล", "ภูเก็ต"}, nil},
+		{"unknown run kept together", "ที่พักกขคใกล้ทะเล", []string{"ที่พัก", "กขค", "ใกล้", "ทะเล"}, nil},
+		{"no cut before trailing vowel", "ตาม", []string{"ตาม"}, []string{"ต", "าม"}},
+		{"mai yamok is its own token", "เชียงใหม่ๆ", []string{"เชียงใหม่", "ๆ"}, nil},
+		{"latin lowered and split", "Chiang-Mai 2024 Tour", []string{"chiang", "mai", "2024", "tour"}, nil},
+		{"mixed scripts", "ทัวร์Phuket", []string{"ทัวร์", "phuket"}, nil},
+		{"thai digits kept, symbols dropped", "ทะเล๑๒ ฿", []string{"ทะเล", "๑๒"}, nil},
+		{"empty", "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := seg
+			if tt.words != nil {
+				s = NewSegmenter(tt.words...)
+			}
+			if got := s.Segment(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segment(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultIsACopy(t *testing.T) {
+	a := Default()
+	n := a.Len()
+	a.Add("คำทดสอบเฉพาะ")
+	if b := Default(); b.Len() != n {
+		t.Errorf("Default() shares words with an earlier copy: %d, want %d", b.Len(), n)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Chiang Mai", "chiangmai"},
+		{"chiang-mai", "chiangmai"},
+		{"Chiangmai", "chiangmai"},
+		{"Chiengmai", "chiangmai"},
+		{"Phuket", "puket"},
+		{"Puket", "puket"},
+		{"Mueang", "muang"},
+		{"Muang", "muang"},
+		{"Nakhon Pathom", "nakonpatom"},
+		{"Pattaya", "pataya"},
+		{"เชียง ใหม่", "เชียงใหม่"},
+		{"อ.เมือง", "อเมือง"},
+		{"Soi 11", "soi11"}, // ตัดเฉพาะตัวอักษรที่ซ้ำ ตัวเลขคงไว้
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Key(tt.in); got != tt.want {
+				t.Errorf("Key(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
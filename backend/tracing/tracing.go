@@ -0,0 +1,79 @@
+// Package tracing ตั้งค่า OpenTelemetry tracing: span ของ HTTP request, query ของ GORM และ HTTP ขาออก
+// exporter เลือกด้วย OTEL_TRACES_EXPORTER (otlp | stdout | none) ค่า otlp ใช้ OTEL_EXPORTER_OTLP_ENDPOINT ตามมาตรฐาน
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName ใช้เมื่อไม่ได้ตั้ง OTEL_SERVICE_NAME
+const ServiceName = "sa-project-backend"
+
+const instrumentation = "github.com/kookkikiv/sa_project/backend"
+
+// Tracer คือ tracer ของระบบนี้ (ก่อน Setup หรือเมื่อ exporter = none จะเป็น no-op)
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Setup ตั้ง tracer provider และ propagator (W3C traceparent + baggage) ตาม exporter ที่เลือก
+// คืนฟังก์ชันสำหรับ flush span ที่ค้างตอนปิดระบบ
+func Setup(ctx context.Context, exporter string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exp sdktrace.SpanExporter
+	switch strings.ToLower(exporter) {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exp, err = otlptracehttp.New(ctx)
+	case "stdout", "console":
+		// ลง stderr เพื่อไม่ปนกับ log JSON ที่ออก stdout
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want otlp, stdout or none)", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME / OTEL_RESOURCE_ATTRIBUTES ทับค่าด้านบนได้
+	if env, err := resource.New(ctx, resource.WithFromEnv()); err == nil {
+		if merged, err := resource.Merge(res, env); err == nil {
+			res = merged
+		}
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// HTTPClient คือ http.Client สำหรับเรียกระบบภายนอก ทุก request มี span และส่ง traceparent ต่อไปให้ปลายทาง
+// (ต้องสร้าง request ด้วย http.NewRequestWithContext เพื่อให้ต่อกับ trace ของงานที่เรียก)
+func HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+}